@@ -2,13 +2,17 @@ package app
 
 import (
 	"context"
+	"os"
+	"time"
 
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 	"github.com/AaronSaikovski/armv/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 // NewRootCommand builds the root cobra command for the armv CLI.
-// Note: MCP server subcommand has been disabled.
 func NewRootCommand(version string) *cobra.Command {
 	var (
 		sourceSubscriptionId string
@@ -17,6 +21,11 @@ func NewRootCommand(version string) *cobra.Command {
 		targetResourceGroup  string
 		debug                bool
 		outputPath           string
+		pollInterval         time.Duration
+		pollTimeout          time.Duration
+		reportFormats        []string
+		resourceIDs          []string
+		logOpts              logOptions
 	)
 
 	rootCmd := &cobra.Command{
@@ -29,6 +38,7 @@ func NewRootCommand(version string) *cobra.Command {
 				ctx = context.Background()
 			}
 
+			authOpts := authFlags(cmd.Flags())
 			cfg := &Config{
 				Version: version,
 				Args: utils.Args{
@@ -38,36 +48,84 @@ func NewRootCommand(version string) *cobra.Command {
 					TargetResourceGroup:  targetResourceGroup,
 					Debug:                debug,
 					OutputPath:           outputPath,
+					PollInterval:         pollInterval,
+					PollTimeout:          pollTimeout,
+					ReportFormats:        reportFormats,
+					ResourceIDs:          resourceIDs,
+					AuthMode:             authOpts.mode,
+					TenantID:             authOpts.tenantID,
+					ClientID:             authOpts.clientID,
 				},
 				OutputPath: outputPath,
 			}
 
 			return run(ctx, cfg)
 		},
+		// Fills unset flags from ARMV_* variables and the config profile,
+		// then configures logging. It runs before cobra's own required-flag
+		// check so a missing flag is classified as an input error and maps to
+		// ExitInputError.
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := applyConfig(cmd, os.LookupEnv); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			logOpts.levelSet = cmd.Flags().Changed("log-level")
+			if err := configureLogging(os.Stderr, logOpts); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			if err := cmd.ValidateRequiredFlags(); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			return nil
+		},
 		Version:       version,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 
+	// `armv completion` replaces cobra's default so it can document the
+	// dynamic lookups; the hidden __complete command is unaffected.
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return validator.NewError(validator.KindInvalidInput, err)
+	})
+
+	// The four IDs are required, but may come from ARMV_* variables or a
+	// profile, so run checks them after resolution instead of cobra.
 	rootCmd.Flags().StringVar(&sourceSubscriptionId, "source-subscription-id", "", "Source Subscription Id (required)")
 	rootCmd.Flags().StringVar(&sourceResourceGroup, "source-resource-group", "", "Source Resource Group (required)")
 	rootCmd.Flags().StringVar(&targetSubscriptionId, "target-subscription-id", "", "Target Subscription Id (required)")
 	rootCmd.Flags().StringVar(&targetResourceGroup, "target-resource-group", "", "Target Resource Group (required)")
 	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug mode with timing information")
 	rootCmd.Flags().StringVar(&outputPath, "output-path", DefaultOutputPath, "Output path to write results")
+	rootCmd.Flags().DurationVar(&pollInterval, "poll-interval", poller.DefaultPollInterval, "Wait between validate-move polls when Azure sends no Retry-After (minimum 1s)")
+	rootCmd.Flags().DurationVar(&pollTimeout, "poll-timeout", poller.DefaultPollTimeout, "Give up polling validate-move after this long")
+	rootCmd.Flags().StringSliceVar(&reportFormats, "report-formats", []string{string(poller.FormatMarkdown)}, "Report formats to write: md, html, sarif, junit (Markdown is always written)")
+	rootCmd.Flags().StringSliceVar(&resourceIDs, "resource-ids", nil, "Validate only these resource IDs from the source resource group (default: all)")
 
-	// Required flags apply only to the root invocation.
-	// Note: MCP server subcommand has been disabled.
-	for _, flagName := range []string{
-		"source-subscription-id",
-		"source-resource-group",
-		"target-subscription-id",
-		"target-resource-group",
-	} {
-		cobra.CheckErr(rootCmd.MarkFlagRequired(flagName))
-	}
+	rootCmd.PersistentFlags().String("config", "", "Config file (default $XDG_CONFIG_HOME/armv/config.yaml or ~/.config/armv/config.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to use (default: the file's default_profile)")
+	rootCmd.PersistentFlags().String("auth-mode", string(auth.ModeDefault), "Credential to use: default, azure-cli, managed-identity or client-secret")
+	rootCmd.PersistentFlags().String("tenant-id", "", "Tenant ID for azure-cli or client-secret auth")
+	rootCmd.PersistentFlags().String("client-id", "", "Client ID for client-secret or user-assigned managed-identity auth")
+
+	rootCmd.PersistentFlags().StringVar(&logOpts.level, "log-level", DefaultLogLevel, "Diagnostic log level: debug, info, warn or error (logs go to stderr)")
+	rootCmd.PersistentFlags().StringVar(&logOpts.format, "log-format", DefaultLogFormat, "Diagnostic log format: text or json")
+	rootCmd.PersistentFlags().BoolVar(&logOpts.traceHTTP, "trace-http", false, "Log every ARM request and response (secrets redacted); implies --log-level info")
+
+	registerRootCompletions(rootCmd)
+
+	rootCmd.AddCommand(newReportCommand())
+	rootCmd.AddCommand(newResumeCommand())
+	rootCmd.AddCommand(newSupportBundleCommand())
+	rootCmd.AddCommand(newConfigCommand())
+	rootCmd.AddCommand(newListCommand())
+	rootCmd.AddCommand(newInteractiveCommand())
+	rootCmd.AddCommand(newCompletionCommand())
 
-	// MCP subcommand disabled: rootCmd.AddCommand(newMCPCommand(version))
+	rootCmd.AddCommand(newMCPCommand(version))
+	rootCmd.AddCommand(newServeCommand(version))
 
 	return rootCmd
 }
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/completion"
+	"github.com/AaronSaikovski/armv/internal/pkg/config"
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the Azure calls behind one Tab press, so a slow
+// or unreachable endpoint leaves the shell waiting seconds, not minutes.
+const completionTimeout = 10 * time.Second
+
+// newCompletionCommand returns `armv completion`, which prints the shell
+// script that enables Tab completion. It replaces cobra's default command so
+// the supported shells and install steps are documented in one place.
+func newCompletionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Print the shell completion script",
+		Long: `Print the shell completion script for bash, zsh or fish.
+
+Besides flags and subcommands, completion looks up subscription IDs for
+--source-subscription-id/--target-subscription-id and --subscription-id, and
+resource group names for the --*-resource-group flags, using the same
+credential settings as a run. Lookups are cached for 5 minutes in the user
+cache directory; set ARMV_COMPLETION_TTL to change that (0 disables the
+cache).
+
+To load completions:
+
+  bash:  source <(armv completion bash)
+         # or permanently (needs bash-completion v2):
+         armv completion bash > /etc/bash_completion.d/armv
+  zsh:   armv completion zsh > "${fpath[1]}/_armv"
+         # compinit must be enabled: autoload -U compinit; compinit
+  fish:  armv completion fish > ~/.config/fish/completions/armv.fish`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(out, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(out)
+			case "fish":
+				return cmd.Root().GenFishCompletion(out, true)
+			default:
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("unsupported shell %q: must be one of bash, zsh, fish", args[0]))
+			}
+		},
+	}
+}
+
+// registerRootCompletions attaches value completion to the root command's
+// flags. Persistent flags are completed for every subcommand.
+func registerRootCompletions(root *cobra.Command) {
+	_ = root.RegisterFlagCompletionFunc("source-subscription-id", completeSubscriptions)
+	_ = root.RegisterFlagCompletionFunc("target-subscription-id", completeSubscriptions)
+	_ = root.RegisterFlagCompletionFunc("source-resource-group", completeResourceGroups("source-subscription-id"))
+	_ = root.RegisterFlagCompletionFunc("target-resource-group", completeResourceGroups("target-subscription-id", "source-subscription-id"))
+	_ = root.RegisterFlagCompletionFunc("report-formats", completeReportFormats)
+	_ = root.RegisterFlagCompletionFunc("output-path", cobra.FixedCompletions(nil, cobra.ShellCompDirectiveFilterDirs))
+
+	modes := make([]string, len(auth.Modes))
+	for i, m := range auth.Modes {
+		modes[i] = string(m)
+	}
+	_ = root.RegisterFlagCompletionFunc("auth-mode", cobra.FixedCompletions(modes, cobra.ShellCompDirectiveNoFileComp))
+	_ = root.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions([]string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = root.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = root.RegisterFlagCompletionFunc("profile", completeProfiles)
+	_ = root.RegisterFlagCompletionFunc("config", cobra.FixedCompletions([]string{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt))
+}
+
+// completeSubscriptions completes subscription IDs, described by name.
+func completeSubscriptions(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	authOpts := completionAuth(cmd)
+	return cachedCompletions(cmd, "subscriptions|"+authKey(authOpts), toComplete, func(ctx context.Context) ([]completion.Candidate, error) {
+		cred, err := newCredential(authOpts)
+		if err != nil {
+			return nil, err
+		}
+		subs, err := discovery.ListSubscriptions(ctx, cred)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]completion.Candidate, len(subs))
+		for i, s := range subs {
+			out[i] = completion.Candidate{Value: s.SubscriptionID, Description: s.DisplayName}
+		}
+		return out, nil
+	})
+}
+
+// completeResourceGroups completes resource group names in the subscription
+// named by the first of subscriptionFlags that is set (on the command line,
+// in the environment or in the profile), described by location.
+func completeResourceGroups(subscriptionFlags ...string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		authOpts := completionAuth(cmd)
+		var subscriptionID string
+		for _, name := range subscriptionFlags {
+			if v, _ := cmd.Flags().GetString(name); v != "" {
+				subscriptionID = v
+				break
+			}
+		}
+		if !utils.CheckValidSubscriptionID(subscriptionID) {
+			cobra.CompDebugln(fmt.Sprintf("no valid %s to list resource groups from", strings.Join(subscriptionFlags, " or ")), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		key := "groups|" + authKey(authOpts) + "|" + strings.ToLower(subscriptionID)
+		return cachedCompletions(cmd, key, toComplete, func(ctx context.Context) ([]completion.Candidate, error) {
+			cred, err := newCredential(authOpts)
+			if err != nil {
+				return nil, err
+			}
+			groups, err := discovery.ListResourceGroups(ctx, cred, subscriptionID)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]completion.Candidate, len(groups))
+			for i, g := range groups {
+				out[i] = completion.Candidate{Value: g.Name, Description: g.Location}
+			}
+			return out, nil
+		})
+	}
+}
+
+// completionAuth resolves the command's flags from the environment and
+// profile, as a run would, and returns the auth settings. Completion runs
+// without the root PersistentPreRunE, so this is not done for it. A broken
+// config file only means fewer completions, so errors are ignored.
+func completionAuth(cmd *cobra.Command) authSettings {
+	if _, err := applyConfig(cmd, os.LookupEnv); err != nil {
+		cobra.CompDebugln("config: "+err.Error(), false)
+	}
+	return authFlags(cmd.Flags())
+}
+
+// authKey identifies the credential in cache keys, so switching tenant or
+// identity does not complete from another identity's lookups.
+func authKey(s authSettings) string {
+	return strings.Join([]string{s.mode, strings.ToLower(s.tenantID), strings.ToLower(s.clientID)}, "|")
+}
+
+// cachedCompletions returns the cached or freshly fetched candidates under
+// key whose value starts with toComplete, ignoring case.
+func cachedCompletions(cmd *cobra.Command, key, toComplete string, fetch func(context.Context) ([]completion.Candidate, error)) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cache, err := completion.DefaultCache()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		cache = &completion.Cache{}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	candidates, err := cache.Get(key, func() ([]completion.Candidate, error) {
+		ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+		defer cancel()
+		return fetch(ctx)
+	})
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var out []cobra.Completion
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c.Value), strings.ToLower(toComplete)) {
+			out = append(out, cobra.CompletionWithDesc(c.Value, c.Description))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeReportFormats completes the last entry of a comma-separated
+// --report-formats value, leaving out formats already listed.
+func completeReportFormats(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	done, current := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		done, current = toComplete[:i+1], toComplete[i+1:]
+	}
+	listed := strings.Split(done, ",")
+
+	var out []cobra.Completion
+	for _, f := range poller.ReportFormats {
+		name := string(f)
+		if strings.HasPrefix(name, current) && !containsFold(listed, name) {
+			out = append(out, done+name)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeProfiles completes the profile names in the selected config file.
+func completeProfiles(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	path, explicit, err := configPath(cmd.Flags(), os.LookupEnv)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	file, err := config.Load(path, !explicit)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return file.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
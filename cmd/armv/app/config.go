@@ -0,0 +1,238 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/config"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// settingSource says which layer supplied an effective setting. Layers are
+// applied in precedence order: flag > env > profile > default.
+type settingSource string
+
+const (
+	sourceFlag    settingSource = "flag"
+	sourceEnv     settingSource = "env"
+	sourceProfile settingSource = "profile"
+	sourceDefault settingSource = "default"
+)
+
+// setting is one effective flag value and where it came from.
+type setting struct {
+	Name   string
+	Value  string
+	Source settingSource
+}
+
+// sourceAnnotation marks a flag filled from the environment or a profile,
+// so that resolving the same flag set again (as `config show` does for the
+// shared persistent flags) still reports the original source.
+const sourceAnnotation = "armv_setting_source"
+
+// commandSettings lists, by the path of the command defining them, the
+// flags besides config.SettingFlags that are bound to ARMV_* variables. Any
+// other flag is only read from the command line, so a variable such as
+// ARMV_FORMAT cannot reach every subcommand that happens to have a --format
+// flag with its own meaning.
+var commandSettings = map[string][]string{
+	"armv": {"debug", "trace-http"},
+}
+
+// boundFlag reports whether the flag called name, as seen by cmd, is filled
+// from the environment and profiles.
+func boundFlag(cmd *cobra.Command, name string) bool {
+	if slices.Contains(config.SettingFlags, name) {
+		return true
+	}
+	// The flag belongs to the nearest command on the path that declares it
+	// locally; for an inherited persistent flag that is an ancestor.
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.LocalFlags().Lookup(name) != nil {
+			return slices.Contains(commandSettings[c.CommandPath()], name)
+		}
+	}
+	return false
+}
+
+// resolvedConfig is the outcome of layering the environment and a profile
+// under the command-line flags.
+type resolvedConfig struct {
+	Path     string
+	Profile  string
+	Settings []setting
+}
+
+// applyConfig fills every bound flag of cmd (see boundFlag) that was not
+// given on the command line from its ARMV_* environment variable or, failing
+// that, from the selected profile. The config file is --config, else ARMV_CONFIG, else
+// config.DefaultPath (which may be absent); the profile is --profile, else
+// ARMV_PROFILE, else the file's default_profile. lookupEnv is os.LookupEnv
+// outside tests.
+func applyConfig(cmd *cobra.Command, lookupEnv func(string) (string, bool)) (resolvedConfig, error) {
+	var res resolvedConfig
+	fs := cmd.Flags()
+
+	path, explicit, err := configPath(fs, lookupEnv)
+	if err != nil {
+		return res, err
+	}
+	file, err := config.Load(path, !explicit)
+	if err != nil {
+		return res, err
+	}
+	res.Path = path
+
+	name, _ := flagOrEnv(fs, "profile", lookupEnv)
+	profile, resolvedName, err := file.Profile(name)
+	if err != nil {
+		return res, err
+	}
+	res.Profile = resolvedName
+	fromProfile := profile.Settings()
+
+	var applyErr error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if applyErr != nil || !boundFlag(cmd, f.Name) {
+			return
+		}
+		s := setting{Name: f.Name, Source: sourceDefault}
+		switch env := config.EnvName(f.Name); {
+		case f.Changed:
+			s.Source = sourceFlag
+			if src := f.Annotations[sourceAnnotation]; len(src) == 1 {
+				s.Source = settingSource(src[0])
+			}
+		case lookupNonEmpty(lookupEnv, env) != "":
+			if err := fs.Set(f.Name, lookupNonEmpty(lookupEnv, env)); err != nil {
+				applyErr = fmt.Errorf("invalid %s: %w", env, err)
+				return
+			}
+			s.Source = sourceEnv
+		case fromProfile[f.Name] != "":
+			if err := fs.Set(f.Name, fromProfile[f.Name]); err != nil {
+				applyErr = fmt.Errorf("invalid %s in profile %q: %w", f.Name, resolvedName, err)
+				return
+			}
+			s.Source = sourceProfile
+		}
+		if s.Source == sourceEnv || s.Source == sourceProfile {
+			if f.Annotations == nil {
+				f.Annotations = map[string][]string{}
+			}
+			f.Annotations[sourceAnnotation] = []string{string(s.Source)}
+		}
+		s.Value = flagValue(f)
+		res.Settings = append(res.Settings, s)
+	})
+	return res, applyErr
+}
+
+// configPath returns the config file selected by --config or ARMV_CONFIG,
+// else config.DefaultPath. explicit is false for the default path, which
+// need not exist.
+func configPath(fs *pflag.FlagSet, lookupEnv func(string) (string, bool)) (path string, explicit bool, err error) {
+	if path, explicit = flagOrEnv(fs, "config", lookupEnv); path != "" {
+		return path, explicit, nil
+	}
+	path, err = config.DefaultPath()
+	return path, false, err
+}
+
+// flagOrEnv returns the flag's value if it was set, else its environment
+// variable. explicit is true if either was given.
+func flagOrEnv(fs *pflag.FlagSet, name string, lookupEnv func(string) (string, bool)) (value string, explicit bool) {
+	if f := fs.Lookup(name); f != nil && f.Changed {
+		return f.Value.String(), true
+	}
+	if v := lookupNonEmpty(lookupEnv, config.EnvName(name)); v != "" {
+		return v, true
+	}
+	return "", false
+}
+
+func lookupNonEmpty(lookupEnv func(string) (string, bool), name string) string {
+	v, _ := lookupEnv(name)
+	return strings.TrimSpace(v)
+}
+
+// flagValue renders a flag's value without pflag's brackets around slices.
+func flagValue(f *pflag.Flag) string {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(sv.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
+
+// newConfigCommand returns the `armv config` parent command.
+func newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect armv configuration",
+	}
+	configCmd.AddCommand(newConfigShowCommand())
+	return configCmd
+}
+
+// newConfigShowCommand returns `armv config show`, which prints the
+// effective value and source of every setting a validation run would use.
+func newConfigShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective settings and where each comes from",
+		Long: `Print the effective settings and where each comes from.
+
+Settings are resolved in precedence order: command-line flag, then the
+ARMV_* environment variable, then the selected profile in the config file,
+then the built-in default. Pass --profile or --config to inspect another
+profile or file.`,
+		Example: `  armv config show
+  armv config show --profile prod-to-shared`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// The run settings are the root command's flags, which were not
+			// parsed for this subcommand; resolve them the same way a run
+			// would. The persistent flags are shared, so --profile and
+			// --config given here still count as flags.
+			root := cmd.Root()
+			// Called for its side effect: cobra merges the persistent flags
+			// into root.Flags(), so --log-level, --config and --profile are
+			// resolved too.
+			root.LocalFlags()
+			res, err := applyConfig(root, os.LookupEnv)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			return writeSettings(cmd, res)
+		},
+	}
+}
+
+func writeSettings(cmd *cobra.Command, res resolvedConfig) error {
+	out := cmd.OutOrStdout()
+	profile := res.Profile
+	if profile == "" {
+		profile = "(none)"
+	}
+	fmt.Fprintf(out, "Config file: %s\nProfile:     %s\n\n", res.Path, profile)
+
+	settings := slices.Clone(res.Settings)
+	slices.SortFunc(settings, func(a, b setting) int { return strings.Compare(a.Name, b.Name) })
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE\tENV")
+	for _, s := range settings {
+		value := s.Value
+		if value == "" {
+			value = `""`
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, value, s.Source, config.EnvName(s.Name))
+	}
+	return tw.Flush()
+}
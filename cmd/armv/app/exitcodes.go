@@ -0,0 +1,51 @@
+package app
+
+import (
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+)
+
+// Process exit codes. These are part of the CLI contract so CI pipelines can
+// gate on the outcome; never renumber an existing code.
+const (
+	// ExitOK means validation succeeded: every resource is eligible to move.
+	ExitOK = 0
+	// ExitInternalError covers unexpected failures (Azure 5xx, I/O errors, bugs).
+	ExitInternalError = 1
+	// ExitValidationFailed means Azure completed the check and reported conflicts.
+	ExitValidationFailed = 2
+	// ExitInputError covers bad flags, malformed IDs and empty source groups.
+	ExitInputError = 3
+	// ExitAuthFailure means no usable credential, or Azure answered 401/403.
+	ExitAuthFailure = 4
+	// ExitResourceGroupNotFound means the source or target group does not exist.
+	ExitResourceGroupNotFound = 5
+	// ExitPollTimeout means the validate-move operation outlived the polling ceiling.
+	ExitPollTimeout = 6
+	// ExitInterrupted means the run was stopped by SIGINT/SIGTERM. It follows
+	// the shell convention of 128 + SIGINT.
+	ExitInterrupted = 130
+)
+
+// ExitCode maps an error returned from the root command to a process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch validator.KindOf(err) {
+	case validator.KindValidationFailed:
+		return ExitValidationFailed
+	case validator.KindInvalidInput:
+		return ExitInputError
+	case validator.KindAuth:
+		return ExitAuthFailure
+	case validator.KindResourceGroupNotFound:
+		return ExitResourceGroupNotFound
+	case validator.KindPollTimeout:
+		return ExitPollTimeout
+	case validator.KindInterrupted:
+		return ExitInterrupted
+	default:
+		return ExitInternalError
+	}
+}
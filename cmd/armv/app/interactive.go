@@ -0,0 +1,326 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/config"
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/prompt"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/spf13/cobra"
+)
+
+// newInteractiveCommand returns `armv interactive`, a terminal wizard that
+// picks the source and target from what the credential can see instead of
+// needing the four IDs up front.
+func newInteractiveCommand() *cobra.Command {
+	var (
+		outputPath    string
+		pollInterval  time.Duration
+		pollTimeout   time.Duration
+		reportFormats []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "interactive",
+		Aliases: []string{"wizard"},
+		Short:   "Choose the source and target interactively, then validate",
+		Long: `Choose the source and target interactively, then validate.
+
+The wizard lists the subscriptions and resource groups the credential can
+see; type part of a name to narrow a list (letters need only appear in
+order, so "prdweu" finds rg-prod-westeurope) and its number to pick it. It
+then lists the resources in the source resource group so some can be left
+out, runs the validation, and offers to save the choices as a config
+profile for the next run.`,
+		Example: `  armv interactive
+  armv interactive --auth-mode azure-cli --report-formats md,html`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			pollOpts := poller.PollOptions{Interval: pollInterval, Timeout: pollTimeout}
+			if err := pollOpts.Validate(); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			if _, err := parseReportFormats(reportFormats); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			authOpts := authFlags(cmd.Flags())
+			cred, err := newCredential(authOpts)
+			if err != nil {
+				return err
+			}
+
+			w := &wizard{p: prompt.New(cmd.InOrStdin(), cmd.OutOrStdout()), out: cmd.OutOrStdout(), cred: cred}
+			choice, err := w.choose(ctx)
+			if err != nil {
+				return promptError(err)
+			}
+
+			var runErr error
+			start, err := w.p.Confirm(ctx, "\nRun the validation now?", true)
+			if err != nil {
+				return promptError(err)
+			}
+			if start {
+				runErr = run(ctx, &Config{
+					Version: cmd.Root().Version,
+					Args: utils.Args{
+						SourceSubscriptionId: choice.sourceSubscriptionID,
+						SourceResourceGroup:  choice.sourceResourceGroup,
+						TargetSubscriptionId: choice.targetSubscriptionID,
+						TargetResourceGroup:  choice.targetResourceGroup,
+						ResourceIDs:          choice.resourceIDs,
+						OutputPath:           outputPath,
+						PollInterval:         pollInterval,
+						PollTimeout:          pollTimeout,
+						ReportFormats:        reportFormats,
+						AuthMode:             authOpts.mode,
+						TenantID:             authOpts.tenantID,
+						ClientID:             authOpts.clientID,
+					},
+					OutputPath: outputPath,
+				})
+				if validator.KindOf(runErr) == validator.KindInterrupted || ctx.Err() != nil {
+					return runErr
+				}
+			}
+
+			if err := w.offerProfile(ctx, cmd, choice, authOpts); err != nil {
+				return errors.Join(runErr, promptError(err))
+			}
+			return runErr
+		},
+	}
+
+	cmd.Flags().StringVar(&outputPath, "output-path", DefaultOutputPath, "Output path to write results")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", poller.DefaultPollInterval, "Wait between validate-move polls when Azure sends no Retry-After (minimum 1s)")
+	cmd.Flags().DurationVar(&pollTimeout, "poll-timeout", poller.DefaultPollTimeout, "Give up polling validate-move after this long")
+	cmd.Flags().StringSliceVar(&reportFormats, "report-formats", []string{string(poller.FormatMarkdown)}, "Report formats to write: md, html, sarif, junit (Markdown is always written)")
+	_ = cmd.RegisterFlagCompletionFunc("report-formats", completeReportFormats)
+	_ = cmd.RegisterFlagCompletionFunc("output-path", cobra.FixedCompletions(nil, cobra.ShellCompDirectiveFilterDirs))
+
+	return cmd
+}
+
+// wizardChoice is what the user picked. resourceIDs is nil when every
+// resource in the source group is kept, so a saved profile follows the
+// group's contents rather than freezing today's list.
+type wizardChoice struct {
+	sourceSubscriptionID string
+	sourceResourceGroup  string
+	targetSubscriptionID string
+	targetResourceGroup  string
+	resourceIDs          []string
+}
+
+type wizard struct {
+	p    *prompt.Prompter
+	out  io.Writer
+	cred azcore.TokenCredential
+}
+
+// choose walks the user through source, resources and target.
+func (w *wizard) choose(ctx context.Context) (wizardChoice, error) {
+	var c wizardChoice
+
+	fmt.Fprintln(w.out, "Listing subscriptions…")
+	subs, err := discovery.ListSubscriptions(ctx, w.cred)
+	if err != nil {
+		return c, validator.NewError(validator.KindInternal, err)
+	}
+	if len(subs) == 0 {
+		return c, validator.NewError(validator.KindAuth, errors.New("the credential cannot see any subscriptions"))
+	}
+	_ = discovery.Sort(subs, "name")
+	subLabels := labels(subs, func(s discovery.Subscription) string {
+		return fmt.Sprintf("%s  (%s)", s.DisplayName, s.SubscriptionID)
+	})
+
+	i, err := w.p.Choose(ctx, "source subscription", subLabels)
+	if err != nil {
+		return c, err
+	}
+	c.sourceSubscriptionID = subs[i].SubscriptionID
+
+	sourceGroups, err := w.groups(ctx, c.sourceSubscriptionID)
+	if err != nil {
+		return c, err
+	}
+	i, err = w.p.Choose(ctx, "source resource group", groupLabels(sourceGroups))
+	if err != nil {
+		return c, err
+	}
+	c.sourceResourceGroup = sourceGroups[i].Name
+
+	if c.resourceIDs, err = w.resources(ctx, c); err != nil {
+		return c, err
+	}
+
+	same, err := w.p.Confirm(ctx, "\nIs the target resource group in the same subscription?", true)
+	if err != nil {
+		return c, err
+	}
+	c.targetSubscriptionID = c.sourceSubscriptionID
+	targetGroups := sourceGroups
+	if !same {
+		if i, err = w.p.Choose(ctx, "target subscription", subLabels); err != nil {
+			return c, err
+		}
+		c.targetSubscriptionID = subs[i].SubscriptionID
+		if targetGroups, err = w.groups(ctx, c.targetSubscriptionID); err != nil {
+			return c, err
+		}
+	}
+	// Resources cannot move to the group they are already in.
+	if c.targetSubscriptionID == c.sourceSubscriptionID {
+		targetGroups = slices.DeleteFunc(slices.Clone(targetGroups), func(g discovery.ResourceGroup) bool {
+			return strings.EqualFold(g.Name, c.sourceResourceGroup)
+		})
+	}
+	if len(targetGroups) == 0 {
+		return c, validator.NewError(validator.KindInvalidInput, errors.New("no other resource group to move to in the target subscription"))
+	}
+	if i, err = w.p.Choose(ctx, "target resource group", groupLabels(targetGroups)); err != nil {
+		return c, err
+	}
+	c.targetResourceGroup = targetGroups[i].Name
+
+	fmt.Fprintf(w.out, "\nSource: %s / %s\nTarget: %s / %s\n",
+		c.sourceSubscriptionID, c.sourceResourceGroup, c.targetSubscriptionID, c.targetResourceGroup)
+	return c, nil
+}
+
+func (w *wizard) groups(ctx context.Context, subscriptionID string) ([]discovery.ResourceGroup, error) {
+	groups, err := discovery.ListResourceGroups(ctx, w.cred, subscriptionID)
+	if err != nil {
+		return nil, validator.NewError(validator.KindInternal, err)
+	}
+	if len(groups) == 0 {
+		return nil, validator.NewError(validator.KindInvalidInput, fmt.Errorf("subscription %s has no resource groups", subscriptionID))
+	}
+	_ = discovery.Sort(groups, "name")
+	return groups, nil
+}
+
+// resources previews the source group's resources and returns the IDs to
+// validate, or nil to validate all of them.
+func (w *wizard) resources(ctx context.Context, c wizardChoice) ([]string, error) {
+	items, err := discovery.ListResources(ctx, w.cred, c.sourceSubscriptionID, c.sourceResourceGroup)
+	if err != nil {
+		return nil, validator.NewError(validator.KindInternal, err)
+	}
+	if len(items) == 0 {
+		return nil, validator.NewError(validator.KindInvalidInput, fmt.Errorf("no resources found in source resource group %q", c.sourceResourceGroup))
+	}
+	_ = discovery.Sort(items, "name")
+
+	width := 0
+	for _, r := range items {
+		width = max(width, len(r.Name))
+	}
+	keep, err := w.p.Deselect(ctx, "Resources in "+c.sourceResourceGroup, labels(items, func(r discovery.Resource) string {
+		return fmt.Sprintf("%-*s  %s", width, r.Name, r.Type)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w.out, "Validating %d of %d %s.\n", len(keep), len(items), pluralResources(len(items)))
+	if len(keep) == len(items) {
+		return nil, nil
+	}
+	ids := make([]string, len(keep))
+	for i, k := range keep {
+		ids[i] = items[k].ID
+	}
+	return ids, nil
+}
+
+// offerProfile asks for a profile name and, if one is given, saves the
+// choice to the config file so the same validation can be rerun with
+// --profile.
+func (w *wizard) offerProfile(ctx context.Context, cmd *cobra.Command, c wizardChoice, authOpts authSettings) error {
+	name, err := w.p.Text(ctx, "\nSave these choices as a config profile? Profile name (Enter to skip)", "")
+	if err != nil || name == "" {
+		return err
+	}
+
+	path, explicit, err := configPath(cmd.Flags(), os.LookupEnv)
+	if err != nil {
+		return validator.NewError(validator.KindInternal, err)
+	}
+	file, err := config.Load(path, !explicit)
+	if err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	if _, exists := file.Profiles[name]; exists {
+		overwrite, err := w.p.Confirm(ctx, fmt.Sprintf("Profile %q already exists in %s. Replace it?", name, path), false)
+		if err != nil || !overwrite {
+			return err
+		}
+	}
+
+	profile := config.Profile{
+		SourceSubscriptionID: c.sourceSubscriptionID,
+		SourceResourceGroup:  c.sourceResourceGroup,
+		TargetSubscriptionID: c.targetSubscriptionID,
+		TargetResourceGroup:  c.targetResourceGroup,
+		ResourceIDs:          c.resourceIDs,
+	}
+	if authOpts.mode != string(auth.ModeDefault) || authOpts.tenantID != "" || authOpts.clientID != "" {
+		profile.Auth = config.Auth{Mode: authOpts.mode, TenantID: authOpts.tenantID, ClientID: authOpts.clientID}
+	}
+	file.SetProfile(name, profile)
+	if err := config.Save(path, file); err != nil {
+		return validator.NewError(validator.KindInternal, err)
+	}
+
+	rerun := "armv --profile " + name
+	if explicit {
+		rerun = fmt.Sprintf("armv --config %s --profile %s", path, name)
+	}
+	fmt.Fprintf(w.out, "Saved profile %q to %s. Rerun with: %s\n", name, path, rerun)
+	return nil
+}
+
+// promptError classifies an error from the wizard's questions: Ctrl-C is an
+// interruption and closed input an input error.
+func promptError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return validator.NewError(validator.KindInterrupted, fmt.Errorf("interactive: cancelled: %w", err))
+	case errors.Is(err, prompt.ErrClosed):
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("interactive: %w before every choice was made", err))
+	default:
+		return validator.NewError(validator.KindInternal, err)
+	}
+}
+
+func groupLabels(groups []discovery.ResourceGroup) []string {
+	return labels(groups, func(g discovery.ResourceGroup) string {
+		return fmt.Sprintf("%s  (%s)", g.Name, g.Location)
+	})
+}
+
+func labels[T any](items []T, label func(T) string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = label(item)
+	}
+	return out
+}
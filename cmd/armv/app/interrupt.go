@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/logrusorgru/aurora"
+)
+
+// runState records how far a run has got, so that if it is interrupted the
+// partial report can say which phase was reached and what was already known.
+type runState struct {
+	outputPath  string
+	version     string
+	started     time.Time
+	phase       poller.Phase
+	reportCtx   poller.ReportContext
+	resourceIDs []string
+	targetRGID  string
+	resumeToken string
+	resumeFile  string
+	resumed     bool
+
+	// reportFormats are written in addition to the Markdown report.
+	reportFormats []poller.ReportFormat
+
+	traceFile string
+	traceOut  io.Closer
+	stopTrace func()
+
+	progress *poller.Progress
+	history  *poller.History
+}
+
+func newRunState(outputPath string, reportCtx poller.ReportContext) *runState {
+	progress, history := newProgress()
+	return &runState{outputPath: outputPath, started: time.Now(), reportCtx: reportCtx, progress: progress, history: history}
+}
+
+// enter records and announces the start of phase; detail is optional.
+func (s *runState) enter(phase poller.Phase, detail string) {
+	s.phase = phase
+	s.progress.Phase(phase, detail)
+}
+
+// startTrace records the run's redacted HTTP trace to trace-<stamp>.jsonl in
+// the output directory for `armv support-bundle`. It must be called before
+// the first ARM client is built. Failing to open the file only loses the
+// trace, so it is a warning.
+func (s *runState) startTrace() {
+	name := fmt.Sprintf("trace-%s.jsonl", s.started.Format("2006-01-02-15-04-05"))
+	if err := utils.MakeFolder(s.outputPath); err != nil {
+		fmt.Println(aurora.Yellow(fmt.Sprintf("Warning: HTTP trace not recorded: %v", err)))
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(s.outputPath, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, utils.FilePermission)
+	if err != nil {
+		fmt.Println(aurora.Yellow(fmt.Sprintf("Warning: HTTP trace not recorded: %v", err)))
+		return
+	}
+	s.stopTrace = pipeline.RecordHTTPTrace(f)
+	s.traceFile, s.traceOut = name, f
+}
+
+// diagnostics returns what is saved in the run record for support bundles.
+func (s *runState) diagnostics() *poller.RunDiagnostics {
+	return &poller.RunDiagnostics{
+		ToolVersion:           s.version,
+		CorrelationID:         pipeline.CorrelationID(),
+		StartedAt:             s.started.UTC(),
+		TargetResourceGroupID: s.targetRGID,
+		ResourceIDs:           s.resourceIDs,
+		TraceFile:             s.traceFile,
+	}
+}
+
+// finish passes err through unchanged unless ctx was cancelled (SIGINT or
+// SIGTERM) before a verdict was reached. In that case it writes a partial
+// report and returns a KindInterrupted error, which exits with ExitInterrupted.
+func (s *runState) finish(ctx context.Context, err error) error {
+	if s.traceOut != nil {
+		s.stopTrace()
+		_ = s.traceOut.Close()
+		s.traceOut, s.stopTrace = nil, nil
+	}
+	if err == nil || ctx.Err() == nil || errors.Is(err, validator.ErrValidationFailed) {
+		return err
+	}
+
+	now := time.Now()
+	fileName, writeErr := poller.WritePartialReport(s.outputPath, poller.PartialReport{
+		InterruptedAt: now,
+		Phase:         s.phase,
+		Elapsed:       now.Sub(s.started),
+		Context:       s.reportCtx,
+		ResourceIDs:   s.resourceIDs,
+		ResumeToken:   s.resumeToken,
+		ResumeFile:    s.resumeFile,
+	})
+	if writeErr != nil {
+		return &validator.Error{Kind: validator.KindInterrupted, Err: fmt.Errorf("interrupted while %s (partial report not written: %v): %w", s.phase, writeErr, err)}
+	}
+
+	fmt.Println(aurora.Yellow(fmt.Sprintf("\n***  Interrupted while %s; partial report written to: - %s ***", s.phase, filepath.Join(s.outputPath, fileName))))
+	if s.resumeFile != "" {
+		fmt.Println(aurora.Yellow(fmt.Sprintf("***  Resume with: armv resume --token-file %s ***", s.resumeFile)))
+	}
+	return &validator.Error{Kind: validator.KindInterrupted, Err: fmt.Errorf("interrupted while %s: %w", s.phase, err)}
+}
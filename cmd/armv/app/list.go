@@ -0,0 +1,246 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/spf13/cobra"
+)
+
+// listFormat is an output format for `armv list`.
+type listFormat string
+
+const (
+	listTable listFormat = "table"
+	listJSON  listFormat = "json"
+	listCSV   listFormat = "csv"
+)
+
+func parseListFormat(s string) (listFormat, error) {
+	switch f := listFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case listTable, listJSON, listCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported list format %q: must be one of table, json, csv", s)
+	}
+}
+
+// listOptions are the flags shared by every `armv list` subcommand.
+type listOptions struct {
+	format   string
+	filter   string
+	typ      string
+	location string
+	tag      string
+	sort     string
+}
+
+func (o *listOptions) bind(cmd *cobra.Command, withType, withLocation bool) {
+	cmd.Flags().StringVar(&o.format, "format", string(listTable), "Output format: table, json or csv")
+	cmd.Flags().StringVar(&o.filter, "filter", "", "Only show items whose name contains this text (case-insensitive)")
+	cmd.Flags().StringVar(&o.sort, "sort", "name", "Column to sort by; prefix with - for descending (e.g. -location)")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{string(listTable), string(listJSON), string(listCSV)}, cobra.ShellCompDirectiveNoFileComp))
+	if withType {
+		cmd.Flags().StringVar(&o.typ, "type", "", "Only show this resource type (e.g. Microsoft.Web/sites, or Microsoft.Web/ for a namespace)")
+	}
+	if withLocation {
+		cmd.Flags().StringVar(&o.location, "location", "", "Only show items in this Azure region (e.g. australiaeast)")
+		cmd.Flags().StringVar(&o.tag, "tag", "", "Only show items with this tag: name, or name=value (case-insensitive)")
+	}
+}
+
+// newListCommand returns `armv list`, the CLI counterpart of the MCP
+// discovery tools. It is read-only.
+func newListCommand() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List subscriptions, resource groups or resources",
+		Long: `List subscriptions, resource groups or resources visible to the current
+credential, to find the IDs and names a validation needs.`,
+	}
+	listCmd.AddCommand(newListSubscriptionsCommand(), newListGroupsCommand(), newListResourcesCommand())
+	return listCmd
+}
+
+func newListSubscriptionsCommand() *cobra.Command {
+	var opts listOptions
+	cmd := &cobra.Command{
+		Use:     "subscriptions",
+		Aliases: []string{"subs"},
+		Short:   "List subscriptions visible to the credential",
+		Example: `  armv list subscriptions
+  armv list subscriptions --filter prod --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runList(cmd, opts, func(ctx context.Context, cred azcore.TokenCredential) ([]discovery.Subscription, error) {
+				return discovery.ListSubscriptions(ctx, cred)
+			})
+		},
+	}
+	opts.bind(cmd, false, false)
+	return cmd
+}
+
+func newListGroupsCommand() *cobra.Command {
+	var (
+		opts           listOptions
+		subscriptionID string
+	)
+	cmd := &cobra.Command{
+		Use:     "groups",
+		Aliases: []string{"resource-groups", "rgs"},
+		Short:   "List resource groups in a subscription",
+		Example: `  armv list groups --subscription-id 00000000-0000-0000-0000-000000000000
+  armv list groups --subscription-id … --location australiaeast --format csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSubscriptionID(subscriptionID); err != nil {
+				return err
+			}
+			return runList(cmd, opts, func(ctx context.Context, cred azcore.TokenCredential) ([]discovery.ResourceGroup, error) {
+				return discovery.ListResourceGroups(ctx, cred, subscriptionID)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&subscriptionID, "subscription-id", "", "Subscription to list resource groups in (required)")
+	_ = cmd.RegisterFlagCompletionFunc("subscription-id", completeSubscriptions)
+	opts.bind(cmd, false, true)
+	return cmd
+}
+
+func newListResourcesCommand() *cobra.Command {
+	var (
+		opts           listOptions
+		subscriptionID string
+		resourceGroup  string
+	)
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List resources in a resource group",
+		Example: `  armv list resources --subscription-id … --resource-group rg-app
+  armv list resources --subscription-id … --resource-group rg-app --type Microsoft.Web/ --sort type`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := requireSubscriptionID(subscriptionID); err != nil {
+				return err
+			}
+			if strings.TrimSpace(resourceGroup) == "" {
+				return validator.NewError(validator.KindInvalidInput, errors.New("--resource-group is required"))
+			}
+			return runList(cmd, opts, func(ctx context.Context, cred azcore.TokenCredential) ([]discovery.Resource, error) {
+				items, err := discovery.ListResources(ctx, cred, subscriptionID, resourceGroup)
+				if isResourceGroupNotFound(err) {
+					return nil, validator.NewError(validator.KindResourceGroupNotFound, fmt.Errorf("resource group %q does not exist: %w", resourceGroup, err))
+				}
+				return items, err
+			})
+		},
+	}
+	cmd.Flags().StringVar(&subscriptionID, "subscription-id", "", "Subscription containing the resource group (required)")
+	cmd.Flags().StringVar(&resourceGroup, "resource-group", "", "Resource group to list (required)")
+	_ = cmd.RegisterFlagCompletionFunc("subscription-id", completeSubscriptions)
+	_ = cmd.RegisterFlagCompletionFunc("resource-group", completeResourceGroups("subscription-id"))
+	opts.bind(cmd, true, true)
+	return cmd
+}
+
+func requireSubscriptionID(id string) error {
+	if !utils.CheckValidSubscriptionID(id) {
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("invalid or missing --subscription-id %q: expected '00000000-0000-0000-0000-000000000000'", id))
+	}
+	return nil
+}
+
+func isResourceGroupNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound && respErr.ErrorCode == "ResourceGroupNotFound"
+}
+
+// runList validates opts, fetches the items with the configured credential,
+// then filters, sorts and writes them to stdout.
+func runList[T discovery.Record](cmd *cobra.Command, opts listOptions, fetch func(context.Context, azcore.TokenCredential) ([]T, error)) error {
+	format, err := parseListFormat(opts.format)
+	if err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	filter := discovery.Filter{Text: opts.filter, Type: opts.typ, Location: opts.location, Tag: opts.tag}
+	// Check the filter and sort key before calling Azure.
+	if _, err := discovery.Apply([]T{}, filter); err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	if err := discovery.Sort([]T{}, opts.sort); err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cred, err := newCredential(authFlags(cmd.Flags()))
+	if err != nil {
+		return err
+	}
+	items, err := fetch(ctx, cred)
+	if err != nil {
+		return validator.NewError(validator.KindInternal, err)
+	}
+
+	items, _ = discovery.Apply(items, filter)
+	_ = discovery.Sort(items, opts.sort)
+	return writeRecords(cmd.OutOrStdout(), items, format)
+}
+
+// writeRecords renders items as an aligned table, a JSON array or CSV with
+// a header row.
+func writeRecords[T discovery.Record](w io.Writer, items []T, format listFormat) error {
+	var zero T
+	columns := zero.Columns()
+
+	switch format {
+	case listJSON:
+		if items == nil {
+			items = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case listCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := cw.Write(rowOf(item, columns)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range items {
+			fmt.Fprintln(tw, strings.Join(rowOf(item, columns), "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+func rowOf(item discovery.Record, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i], _ = item.Field(col)
+	}
+	return row
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+)
+
+const (
+	// DefaultLogLevel keeps diagnostic logging quiet unless asked for; the
+	// progress lines and banners are the normal user-facing output.
+	DefaultLogLevel = "warn"
+
+	// DefaultLogFormat is the slog handler used when --log-format is unset.
+	DefaultLogFormat = "text"
+)
+
+// logOptions are the logging-related persistent flags.
+type logOptions struct {
+	level     string
+	format    string
+	traceHTTP bool
+
+	// levelSet records whether --log-level was given explicitly, so that
+	// --trace-http can raise the default level without overriding the user.
+	levelSet bool
+}
+
+// configureLogging installs the process-wide slog logger writing to w and,
+// with traceHTTP, registers the ARM request/response trace policy. Trace
+// records are logged at info, so an unset level is raised to info.
+func configureLogging(w io.Writer, opts logOptions) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: expected debug, info, warn or error", opts.level)
+	}
+	if opts.traceHTTP && !opts.levelSet && level > slog.LevelInfo {
+		level = slog.LevelInfo
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.format) {
+	case "text":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return fmt.Errorf("invalid --log-format %q: expected text or json", opts.format)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	if opts.traceHTTP {
+		pipeline.EnableHTTPTrace(logger)
+	}
+	return nil
+}
@@ -3,20 +3,59 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
 	"github.com/AaronSaikovski/armv/internal/pkg/validation"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/logrusorgru/aurora"
+	"github.com/spf13/pflag"
 )
 
+// authSettings are the persistent --auth-mode, --tenant-id and --client-id
+// flags after config resolution.
+type authSettings struct {
+	mode     string
+	tenantID string
+	clientID string
+}
+
+// authFlags reads the auth settings from fs.
+func authFlags(fs *pflag.FlagSet) authSettings {
+	mode, _ := fs.GetString("auth-mode")
+	tenantID, _ := fs.GetString("tenant-id")
+	clientID, _ := fs.GetString("client-id")
+	return authSettings{mode: mode, tenantID: tenantID, clientID: clientID}
+}
+
+// newCredential builds the credential selected by s. A client secret is
+// only ever taken from AZURE_CLIENT_SECRET.
+func newCredential(s authSettings) (azcore.TokenCredential, error) {
+	mode, err := auth.ParseMode(s.mode)
+	if err != nil {
+		return nil, validator.NewError(validator.KindInvalidInput, err)
+	}
+	cred, err := auth.NewCredential(auth.CredentialOptions{
+		Mode:         mode,
+		TenantID:     s.tenantID,
+		ClientID:     s.clientID,
+		ClientSecret: os.Getenv("AZURE_CLIENT_SECRET"),
+	})
+	if err != nil {
+		return nil, validator.NewError(validator.KindAuth, fmt.Errorf("failed to get Azure %s credential: %w", mode, err))
+	}
+	return cred, nil
+}
+
 // checkLogin verifies the caller has access to the source Azure subscription.
 func checkLogin(ctx context.Context, azureResourceMoveInfo *validation.AzureResourceMoveInfo) error {
 	login, err := auth.CheckLogin(ctx, azureResourceMoveInfo.Credentials, azureResourceMoveInfo.SourceSubscriptionId)
 	if err != nil {
-		return fmt.Errorf("login error: %w", err)
+		return validator.NewError(validator.KindAuth, fmt.Errorf("login error: %w", err))
 	}
 	if !login {
-		return fmt.Errorf("not logged into Azure subscription %q: please run `az login` and retry", azureResourceMoveInfo.SourceSubscriptionId)
+		return validator.NewError(validator.KindAuth, fmt.Errorf("not logged into Azure subscription %q: please run `az login` and retry", azureResourceMoveInfo.SourceSubscriptionId))
 	}
 	fmt.Println(aurora.Yellow(fmt.Sprintf("Logged into Subscription Id: %s", azureResourceMoveInfo.SourceSubscriptionId)))
 
@@ -1,43 +1,207 @@
-//go:build ignore
-
 package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/AaronSaikovski/armv/internal/pkg/config"
 	"github.com/AaronSaikovski/armv/internal/pkg/mcpserver"
+	"github.com/AaronSaikovski/armv/internal/pkg/restapi"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/spf13/cobra"
 )
 
+// mcpTokenEnv holds the bearer token for `armv mcp serve --listen` when no
+// --token-file is given, so it can come from a container secret.
+const mcpTokenEnv = "ARMV_MCP_TOKEN"
+
 // newMCPCommand returns the `armv mcp` parent command and its `serve` subcommand.
-// `armv mcp serve` runs ARMV as a Model Context Protocol server over stdio,
-// exposing validation and discovery tools to MCP clients.
-//
-// Intended to be launched by an MCP client (Claude Desktop, Claude Code, VS Code, etc.).
-//
-// DISABLED: MCP server capability has been removed.
+// `armv mcp serve` runs ARMV as a Model Context Protocol server, exposing
+// validation and discovery tools to MCP clients: over stdio when launched by
+// a client (Claude Desktop, Claude Code, VS Code, etc.), or over streamable
+// HTTP with --listen for a shared server.
 func newMCPCommand(version string) *cobra.Command {
 	mcpCmd := &cobra.Command{
 		Use:   "mcp",
 		Short: "MCP server commands",
-		Long:  `MCP server commands (disabled)`,
 	}
 
+	var (
+		listen         string
+		tokenFile      string
+		maxValidations int
+		maxJobs        int
+		jobRetention   time.Duration
+		reportDir      string
+		allowInline    bool
+	)
 	serveCmd := &cobra.Command{
 		Use:   "serve",
-		Short: "Run ARMV as an MCP server over stdio",
-		Long: `Run ARMV as an MCP server over stdio.
+		Short: "Run ARMV as an MCP server over stdio or streamable HTTP",
+		Long: `Run ARMV as an MCP server.
+
+Without --listen the server speaks MCP over stdio and is meant to be
+launched by an MCP client (Claude Desktop, Claude Code, VS Code, etc.).
+Nothing but protocol messages is written to stdout; logs go to stderr.
+
+With --listen the server speaks streamable HTTP at /mcp, for a shared team
+server, e.g. in a container. Every request must send
+"Authorization: Bearer <token>", where the token (at least 16 characters) is
+read from --token-file or the ARMV_MCP_TOKEN environment variable. /healthz
+answers liveness probes and /metrics serves Prometheus metrics, both without
+a token. Terminate TLS in front of the server.
+
+Long validations can run as background jobs (start_validation and friends),
+so clients with short tool-call timeouts are not cut off. --max-jobs bounds
+how many poll Azure at once and --job-retention how long results are kept.
+
+Identical validations (same groups, resources and credential) that overlap
+share one Azure operation, and a call may accept a recent identical result
+with max_age_seconds. --max-validations bounds how many validations, from
+validate_move and jobs together, run against Azure at once, so a busy server
+does not get the subscription throttled.
 
-Intended to be launched by an MCP client (Claude Desktop, Claude Code, VS Code, etc.).
+Every completed validation is published as an MCP resource,
+armv://reports/{id} (Markdown) and armv://reports/{id}.json, so earlier
+reports can be compared without re-running. The last 50 are kept in
+--report-dir.
 
-DISABLED: MCP server capability has been removed.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Fprintln(cmd.ErrOrStderr(), "MCP server capability has been disabled")
-			return nil
+Tools authenticate with the server's credential, chosen by --auth-mode,
+--tenant-id and --client-id (or their ARMV_* variables and the selected
+profile) exactly as for a validation run. Every config file profile that
+sets auth.mode is also offered as a named credential: a tool call selects
+it with credential_profile. Tool calls cannot pass tenant_id, client_id,
+client_secret or bearer_token unless --allow-inline-credentials is set,
+because those values pass through the LLM conversation and client logs.
+Tool call arguments are logged with secrets redacted.`,
+		Example: `  armv mcp serve
+  ARMV_MCP_TOKEN=$(openssl rand -hex 32) armv mcp serve --listen :8080
+  armv mcp serve --listen :8080 --token-file /run/secrets/armv-mcp-token`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			if maxJobs < 1 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--max-jobs must be at least 1, got %d", maxJobs))
+			}
+			if maxValidations < 1 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--max-validations must be at least 1, got %d", maxValidations))
+			}
+			if jobRetention <= 0 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--job-retention must be positive, got %s", jobRetention))
+			}
+			cred, profiles, err := mcpCredentials(cmd)
+			if err != nil {
+				return err
+			}
+			opts := mcpserver.Options{
+				MaxJobs:                maxJobs,
+				MaxValidations:         maxValidations,
+				JobRetention:           jobRetention,
+				ReportDir:              reportDir,
+				Credential:             cred,
+				CredentialProfiles:     profiles,
+				AllowInlineCredentials: allowInline,
+			}
+
+			if listen == "" {
+				if tokenFile != "" {
+					return validator.NewError(validator.KindInvalidInput, errors.New("--token-file only applies with --listen"))
+				}
+				return serveResult(ctx, mcpserver.Run(ctx, version, opts))
+			}
+
+			token, err := bearerToken(tokenFile, mcpTokenEnv)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			return serveResult(ctx, mcpserver.ServeHTTP(ctx, version, mcpserver.HTTPOptions{Addr: listen, Token: token, Server: opts}))
 		},
 	}
+	serveCmd.Flags().StringVar(&listen, "listen", "", "Serve streamable HTTP on this address (e.g. :8080) instead of stdio")
+	serveCmd.Flags().IntVar(&maxJobs, "max-jobs", mcpserver.DefaultMaxJobs, "Background validation jobs (start_validation) that may poll Azure at once; more are queued")
+	serveCmd.Flags().IntVar(&maxValidations, "max-validations", mcpserver.DefaultMaxValidations, "Validations (validate_move and jobs together) that may run against Azure at once; more wait for a slot")
+	serveCmd.Flags().DurationVar(&jobRetention, "job-retention", mcpserver.DefaultJobRetention, "How long a finished job's result stays available to get_validation_result")
+	serveCmd.Flags().StringVar(&reportDir, "report-dir", "", "Directory for the validation reports served as armv://reports/{id} resources (default: <user cache dir>/armv/mcp-reports)")
+	_ = serveCmd.MarkFlagDirname("report-dir")
+	serveCmd.Flags().BoolVar(&allowInline, "allow-inline-credentials", false, "Let tool calls pass tenant_id, client_id, client_secret and bearer_token instead of using the server's credentials")
+	serveCmd.Flags().StringVar(&tokenFile, "token-file", "", "File holding the bearer token HTTP clients must send (default: $"+mcpTokenEnv+")")
 
 	mcpCmd.AddCommand(serveCmd)
 	return mcpCmd
 }
+
+// bearerToken reads an HTTP bearer token from path, else from the
+// environment variable env.
+func bearerToken(path, env string) (string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read --token-file: %w", err)
+		}
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("--token-file %s is empty", path)
+	}
+	if token := strings.TrimSpace(os.Getenv(env)); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("--listen needs a bearer token: set %s or pass --token-file", env)
+}
+
+// mcpCredentials builds the server's default credential from the resolved
+// auth flags, and one named credential per config file profile that sets
+// auth.mode. A client secret still only comes from AZURE_CLIENT_SECRET. A
+// profile whose credential cannot be built is skipped with a warning, so a
+// profile meant for another machine does not stop the server.
+func mcpCredentials(cmd *cobra.Command) (azcore.TokenCredential, map[string]azcore.TokenCredential, error) {
+	cred, err := newCredential(authFlags(cmd.Flags()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, explicit, err := configPath(cmd.Flags(), os.LookupEnv)
+	if err != nil {
+		return nil, nil, validator.NewError(validator.KindInvalidInput, err)
+	}
+	file, err := config.Load(path, !explicit)
+	if err != nil {
+		return nil, nil, validator.NewError(validator.KindInvalidInput, err)
+	}
+	profiles := make(map[string]azcore.TokenCredential)
+	for _, name := range file.ProfileNames() {
+		a := file.Profiles[name].Auth
+		if a.Mode == "" {
+			continue
+		}
+		c, err := newCredential(authSettings{mode: a.Mode, tenantID: a.TenantID, clientID: a.ClientID})
+		if err != nil {
+			slog.Warn("mcp: skipping credential profile", "profile", name, "error", err)
+			continue
+		}
+		profiles[name] = c
+	}
+	return cred, profiles, nil
+}
+
+// serveResult treats the server stopping because ctx was cancelled (Ctrl-C,
+// SIGTERM from the container runtime) as a clean exit.
+func serveResult(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	if errors.Is(err, mcpserver.ErrWeakToken) || errors.Is(err, restapi.ErrWeakToken) {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	return validator.NewError(validator.KindInternal, err)
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"os"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"golang.org/x/term"
+)
+
+// newProgress returns the CLI progress reporter, rendering on stderr. The
+// in-place status line is only used when stdout is a terminal; when output
+// is piped or captured by CI, progress is written as plain log lines. The
+// returned history is nil if the user cache directory is unavailable.
+func newProgress() (*poller.Progress, *poller.History) {
+	var history *poller.History
+	if path, err := poller.DefaultHistoryPath(); err == nil {
+		history = poller.LoadHistory(path)
+	}
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	return poller.NewProgress(os.Stderr, interactive, history), history
+}
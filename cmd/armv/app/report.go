@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// newReportCommand returns the `armv report` parent command. Its subcommands
+// work purely on saved run records and never call Azure.
+func newReportCommand() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Work with saved validation reports",
+	}
+
+	reportCmd.AddCommand(newReportRenderCommand())
+	return reportCmd
+}
+
+// newReportRenderCommand returns `armv report render`, which rebuilds any
+// report format from a run-*.json record written by a previous validation.
+func newReportRenderCommand() *cobra.Command {
+	var (
+		input  string
+		format string
+		output string
+	)
+
+	renderCmd := &cobra.Command{
+		Use:   "render",
+		Short: "Re-render a saved run in another format without calling Azure",
+		Long: `Re-render a saved run in another format without calling Azure.
+
+Every validation writes a run-<timestamp>.json record next to its Markdown
+report. This command rebuilds the report from that record, so it can be
+regenerated or restyled offline.`,
+		Example: `  armv report render --input ./output/run-2026-04-20-10-45-12.json --format html
+  armv report render --input run.json --format sarif --output armv.sarif`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			reportFormat, err := poller.ParseReportFormat(format)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+
+			rec, err := poller.LoadRunRecord(input)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+
+			rendered, err := poller.Render(rec.Report(), reportFormat)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), rendered)
+				return err
+			}
+
+			if err := utils.WriteOutputFile(filepath.Dir(output), filepath.Base(output), rendered); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Report written to: %s\n", output)
+			return nil
+		},
+	}
+
+	renderCmd.Flags().StringVar(&input, "input", "", "Path to a saved run-*.json record (required)")
+	renderCmd.Flags().StringVar(&format, "format", string(poller.FormatMarkdown), "Output format: md, html, sarif or junit")
+	renderCmd.Flags().StringVar(&output, "output", "", "File to write the rendered report to (default stdout)")
+	cobra.CheckErr(renderCmd.MarkFlagRequired("input"))
+
+	return renderCmd
+}
@@ -4,52 +4,61 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
 	"github.com/AaronSaikovski/armv/internal/pkg/resourcegroups"
 	"github.com/AaronSaikovski/armv/internal/pkg/resources"
 	"github.com/AaronSaikovski/armv/internal/pkg/validation"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 )
 
 // getResourceGroupInfo populates the source/target resource group details and
-// the full resource-ID list on the supplied AzureResourceMoveInfo.
-func getResourceGroupInfo(ctx context.Context, azureResourceMoveInfo *validation.AzureResourceMoveInfo) error {
+// the resource-ID list on the supplied AzureResourceMoveInfo, recording the
+// phase reached in st. A non-empty want restricts the list to those IDs.
+func getResourceGroupInfo(ctx context.Context, azureResourceMoveInfo *validation.AzureResourceMoveInfo, want []string, st *runState) error {
+	st.enter(poller.PhaseResourceGroups, "")
 	resourceGroupClient, err := resourcegroups.GetResourceGroupClient(azureResourceMoveInfo.Credentials, azureResourceMoveInfo.SourceSubscriptionId)
 	if err != nil {
-		return fmt.Errorf("failed to get resource group client: %w", err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("failed to get resource group client: %w", err))
 	}
 
 	srcRsgExists, err := resourcegroups.CheckResourceGroupExists(ctx, resourceGroupClient, azureResourceMoveInfo.SourceResourceGroup)
 	if err != nil {
-		return fmt.Errorf("checking source resource group %q: %w", azureResourceMoveInfo.SourceResourceGroup, err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("checking source resource group %q: %w", azureResourceMoveInfo.SourceResourceGroup, err))
 	}
 	if !srcRsgExists {
-		return fmt.Errorf("source resource group %q does not exist", azureResourceMoveInfo.SourceResourceGroup)
+		return validator.NewError(validator.KindResourceGroupNotFound, fmt.Errorf("source resource group %q does not exist", azureResourceMoveInfo.SourceResourceGroup))
 	}
 
 	dstRsgExists, err := resourcegroups.CheckResourceGroupExists(ctx, resourceGroupClient, azureResourceMoveInfo.TargetResourceGroup)
 	if err != nil {
-		return fmt.Errorf("checking target resource group %q: %w", azureResourceMoveInfo.TargetResourceGroup, err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("checking target resource group %q: %w", azureResourceMoveInfo.TargetResourceGroup, err))
 	}
 	if !dstRsgExists {
-		return fmt.Errorf("destination resource group %q does not exist", azureResourceMoveInfo.TargetResourceGroup)
+		return validator.NewError(validator.KindResourceGroupNotFound, fmt.Errorf("destination resource group %q does not exist", azureResourceMoveInfo.TargetResourceGroup))
 	}
 
+	st.enter(poller.PhaseEnumerating, "")
 	resourcesClient, err := resources.GetResourcesClient(azureResourceMoveInfo.Credentials, azureResourceMoveInfo.SourceSubscriptionId)
 	if err != nil {
-		return fmt.Errorf("failed to get resources client: %w", err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("failed to get resources client: %w", err))
 	}
 
 	azureResourceMoveInfo.ResourceIds, err = resources.GetResourceIds(ctx, resourcesClient, azureResourceMoveInfo.SourceResourceGroup)
 	if err != nil {
-		return fmt.Errorf("failed to get resource IDs: %w", err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("failed to get resource IDs: %w", err))
 	}
 
 	if len(azureResourceMoveInfo.ResourceIds) == 0 {
-		return fmt.Errorf("no resources found in source resource group %q", azureResourceMoveInfo.SourceResourceGroup)
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("no resources found in source resource group %q", azureResourceMoveInfo.SourceResourceGroup))
+	}
+	azureResourceMoveInfo.ResourceIds, err = validator.SelectResources(azureResourceMoveInfo.ResourceIds, want, azureResourceMoveInfo.SourceResourceGroup)
+	if err != nil {
+		return err
 	}
 
 	azureResourceMoveInfo.TargetResourceGroupId, err = resourcegroups.GetResourceGroupId(ctx, resourceGroupClient, azureResourceMoveInfo.TargetResourceGroup)
 	if err != nil {
-		return fmt.Errorf("failed to get target resource group ID: %w", err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("failed to get target resource group ID: %w", err))
 	}
 
 	return nil
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/validation"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// newResumeCommand returns `armv resume`, which reattaches to a validate-move
+// operation from the resume-*.json file a previous run saved before polling.
+func newResumeCommand() *cobra.Command {
+	var (
+		tokenFile    string
+		outputPath   string
+		pollInterval time.Duration
+		pollTimeout  time.Duration
+	)
+
+	resumeCmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume polling an interrupted validation",
+		Long: `Resume polling an interrupted validation.
+
+As soon as Azure accepts a validate-move request, armv saves a
+resume-<timestamp>-<resource group>.json file holding the operation's resume
+token to the output directory. If the run is interrupted, this command
+reattaches to the same operation and writes the normal report.`,
+		Example: `  armv resume
+  armv resume --token-file ./output/resume-2026-04-20-10-45-12-rg-src.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			if tokenFile == "" {
+				latest, err := validator.LatestResumeFile(outputPath)
+				if err != nil {
+					return validator.NewError(validator.KindInvalidInput, err)
+				}
+				tokenFile = latest
+			}
+
+			saved, err := validator.LoadResumeState(tokenFile)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+
+			pollOpts := poller.PollOptions{Interval: pollInterval, Timeout: pollTimeout}
+			if err := pollOpts.Validate(); err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+
+			st := newRunState(outputPath, poller.ReportContext{
+				SourceSubscriptionID: saved.SourceSubscriptionID,
+				SourceResourceGroup:  saved.SourceResourceGroup,
+				TargetSubscriptionID: saved.TargetSubscriptionID,
+				TargetResourceGroup:  saved.TargetResourceGroup,
+				ResourceCount:        len(saved.ResourceIDs),
+			})
+			st.version = cmd.Root().Version
+			st.resourceIDs = saved.ResourceIDs
+			st.targetRGID = saved.TargetResourceGroupID
+			st.resumeToken = saved.ResumeToken
+			st.resumeFile = tokenFile
+			st.resumed = true
+			st.progress.SetResourceCount(len(saved.ResourceIDs))
+			st.startTrace()
+
+			return st.finish(ctx, resumeValidation(ctx, saved, authFlags(cmd.Flags()), pollOpts, st))
+		},
+	}
+
+	resumeCmd.Flags().StringVar(&tokenFile, "token-file", "", "Resume state file to reattach to (default: newest resume-*.json in --output-path)")
+	resumeCmd.Flags().StringVar(&outputPath, "output-path", DefaultOutputPath, "Output path to write results")
+	resumeCmd.Flags().DurationVar(&pollInterval, "poll-interval", poller.DefaultPollInterval, "Wait between validate-move polls when Azure sends no Retry-After (minimum 1s)")
+	resumeCmd.Flags().DurationVar(&pollTimeout, "poll-timeout", poller.DefaultPollTimeout, "Give up polling validate-move after this long")
+
+	return resumeCmd
+}
+
+// resumeValidation reattaches to the operation in saved and polls it to a
+// report, recording progress in st.
+func resumeValidation(ctx context.Context, saved validator.ResumeState, authOpts authSettings, pollOpts poller.PollOptions, st *runState) error {
+	st.enter(poller.PhaseAuthenticating, "")
+	cred, err := newCredential(authOpts)
+	if err != nil {
+		return err
+	}
+
+	st.enter(poller.PhaseLoginCheck, "")
+	moveInfo := validation.NewAzureResourceMoveInfo(saved.SourceSubscriptionID, saved.SourceResourceGroup, saved.TargetResourceGroup, nil, nil, cred)
+	if err := checkLogin(ctx, &moveInfo); err != nil {
+		return err
+	}
+
+	st.enter(poller.PhaseSubmitting, "")
+	resp, err := validation.ResumeValidateMove(ctx, cred, saved.SourceSubscriptionID, saved.SourceResourceGroup, saved.ResumeToken)
+	if err != nil {
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("failed to resume validation from %s: %w", st.resumeFile, err))
+	}
+	fmt.Println(aurora.Yellow(fmt.Sprintf("Resuming validation started %s (%s -> %s)",
+		saved.StartedAt.Local().Format(time.DateTime), saved.SourceResourceGroup, saved.TargetResourceGroup)))
+
+	return pollAndReport(ctx, resp, pollOpts, st)
+}
@@ -5,13 +5,20 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/AaronSaikovski/armv/cmd/armv/poller"
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
 	"github.com/AaronSaikovski/armv/internal/pkg/validation"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/logrusorgru/aurora"
 )
 
@@ -33,11 +40,28 @@ type Config struct {
 
 // run executes the validation workflow end-to-end.
 func run(ctx context.Context, cfg *Config) error {
+	if missing := missingSettings(cfg.Args); len(missing) > 0 {
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf(
+			"required settings not set: %s (pass them as flags, ARMV_* environment variables or in a config profile)",
+			strings.Join(missing, ", ")))
+	}
 	if !utils.CheckValidSubscriptionID(cfg.Args.SourceSubscriptionId) {
-		return fmt.Errorf("invalid source subscription ID format: expected '00000000-0000-0000-0000-000000000000'")
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("invalid source subscription ID format: expected '00000000-0000-0000-0000-000000000000'"))
 	}
 	if !utils.CheckValidSubscriptionID(cfg.Args.TargetSubscriptionId) {
-		return fmt.Errorf("invalid target subscription ID format: expected '00000000-0000-0000-0000-000000000000'")
+		return validator.NewError(validator.KindInvalidInput, fmt.Errorf("invalid target subscription ID format: expected '00000000-0000-0000-0000-000000000000'"))
+	}
+
+	pollOpts := poller.PollOptions{Interval: cfg.Args.PollInterval, Timeout: cfg.Args.PollTimeout}
+	if err := pollOpts.Validate(); err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	if _, err := auth.ParseMode(cfg.Args.AuthMode); err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
+	}
+	formats, err := parseReportFormats(cfg.Args.ReportFormats)
+	if err != nil {
+		return validator.NewError(validator.KindInvalidInput, err)
 	}
 
 	if cfg.Args.Debug {
@@ -47,9 +71,24 @@ func run(ctx context.Context, cfg *Config) error {
 		}()
 	}
 
-	cred, err := auth.GetAzureDefaultCredential()
+	st := newRunState(cfg.OutputPath, poller.ReportContext{
+		SourceSubscriptionID: cfg.Args.SourceSubscriptionId,
+		SourceResourceGroup:  cfg.Args.SourceResourceGroup,
+		TargetSubscriptionID: cfg.Args.TargetSubscriptionId,
+		TargetResourceGroup:  cfg.Args.TargetResourceGroup,
+	})
+	st.version = cfg.Version
+	st.reportFormats = formats
+	st.startTrace()
+	return st.finish(ctx, runValidation(ctx, cfg, pollOpts, st))
+}
+
+// runValidation performs the Azure calls for run, recording progress in st.
+func runValidation(ctx context.Context, cfg *Config, pollOpts poller.PollOptions, st *runState) error {
+	st.enter(poller.PhaseAuthenticating, "")
+	cred, err := newCredential(authSettings{mode: cfg.Args.AuthMode, tenantID: cfg.Args.TenantID, clientID: cfg.Args.ClientID})
 	if err != nil {
-		return fmt.Errorf("failed to get Azure default credential: %w", err)
+		return err
 	}
 
 	azureResourceMoveInfo := validation.NewAzureResourceMoveInfo(
@@ -61,30 +100,86 @@ func run(ctx context.Context, cfg *Config) error {
 		cred,
 	)
 
+	st.enter(poller.PhaseLoginCheck, "")
 	if err := checkLogin(ctx, &azureResourceMoveInfo); err != nil {
 		return err
 	}
 
-	if err := getResourceGroupInfo(ctx, &azureResourceMoveInfo); err != nil {
+	if err := getResourceGroupInfo(ctx, &azureResourceMoveInfo, cfg.Args.ResourceIDs, st); err != nil {
 		return err
 	}
 
+	st.resourceIDs = make([]string, 0, len(azureResourceMoveInfo.ResourceIds))
+	for _, id := range azureResourceMoveInfo.ResourceIds {
+		if id != nil {
+			st.resourceIDs = append(st.resourceIDs, *id)
+		}
+	}
+	st.reportCtx.ResourceCount = len(st.resourceIDs)
+	st.progress.SetResourceCount(len(st.resourceIDs))
+	if azureResourceMoveInfo.TargetResourceGroupId != nil {
+		st.targetRGID = *azureResourceMoveInfo.TargetResourceGroupId
+	}
+
+	st.enter(poller.PhaseSubmitting, fmt.Sprintf("%d %s", len(st.resourceIDs), pluralResources(len(st.resourceIDs))))
 	resp, err := azureResourceMoveInfo.ValidateMove(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to validate resource move: %w", err)
+		return validator.NewError(validator.KindInternal, fmt.Errorf("failed to validate resource move: %w", err))
 	}
 
-	reportCtx := poller.ReportContext{
-		SourceSubscriptionID: cfg.Args.SourceSubscriptionId,
-		SourceResourceGroup:  cfg.Args.SourceResourceGroup,
-		TargetSubscriptionID: cfg.Args.TargetSubscriptionId,
-		TargetResourceGroup:  cfg.Args.TargetResourceGroup,
-		ResourceCount:        len(azureResourceMoveInfo.ResourceIds),
+	// Persist the resume token before polling so an interrupted run can be
+	// picked up with `armv resume`. A failure here is not fatal: the
+	// validation itself is already running.
+	if token, err := resp.ResumeToken(); err == nil {
+		st.resumeToken = token
+		st.resumeFile, err = validator.SaveResumeState(cfg.OutputPath, validator.ResumeState{
+			ResumeToken:           token,
+			SourceSubscriptionID:  cfg.Args.SourceSubscriptionId,
+			SourceResourceGroup:   cfg.Args.SourceResourceGroup,
+			TargetSubscriptionID:  cfg.Args.TargetSubscriptionId,
+			TargetResourceGroup:   cfg.Args.TargetResourceGroup,
+			TargetResourceGroupID: st.targetRGID,
+			ResourceIDs:           st.resourceIDs,
+			StartedAt:             time.Now().UTC(),
+		})
+		if err != nil {
+			fmt.Println(aurora.Yellow(fmt.Sprintf("Warning: could not save resume token: %v", err)))
+		}
 	}
 
-	report, err := poller.PollApi(ctx, resp, cfg.OutputPath, reportCtx)
+	return pollAndReport(ctx, resp, pollOpts, st)
+}
+
+// pollAndReport drives an accepted validate-move operation to completion,
+// writes the report and prints the terminal summary. It is shared by a fresh
+// run and `armv resume`. The resume file, if any, is removed once the report
+// has been written since the operation can no longer be resumed.
+func pollAndReport[T any](ctx context.Context, resp *runtime.Poller[T], pollOpts poller.PollOptions, st *runState) error {
+	st.enter(poller.PhasePolling, "")
+	report, err := poller.PollApi(ctx, resp, st.outputPath, st.reportCtx, st.diagnostics(), pollOpts, st.progress)
 	if err != nil {
-		return fmt.Errorf("failed to poll API: %w", err)
+		if st.resumeFile != "" && ctx.Err() == nil {
+			err = fmt.Errorf("%w (resume with: armv resume --token-file %s)", err, st.resumeFile)
+		}
+		return validator.PollError(fmt.Errorf("failed to poll API: %w", err))
+	}
+
+	if names, err := poller.WriteReportFormats(st.outputPath, report, st.reportFormats); err != nil {
+		fmt.Println(aurora.Yellow(fmt.Sprintf("Warning: %v", err)))
+	} else if len(names) > 0 {
+		slog.Debug("app: wrote extra report formats", "files", names)
+	}
+
+	// Only uninterrupted runs feed the ETA history: a resumed run's polling
+	// time covers just the tail of the operation.
+	if st.history != nil && !st.resumed {
+		_ = st.history.Record(report.Context.ResourceCount, report.Context.PollDuration, time.Now())
+	}
+
+	if st.resumeFile != "" {
+		if err := os.Remove(st.resumeFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Println(aurora.Yellow(fmt.Sprintf("Warning: could not remove resume file: %v", err)))
+		}
 	}
 
 	if report.Success {
@@ -93,6 +188,50 @@ func run(ctx context.Context, cfg *Config) error {
 		utils.OutputFailSummary(len(report.Errors), poller.TopFailureNames(report, consoleTopFailures))
 	}
 
-	fmt.Println(aurora.Yellow(fmt.Sprintf("\n***  Output file written to: - %s ***", cfg.OutputPath)))
+	fmt.Println(aurora.Yellow(fmt.Sprintf("\n***  Output file written to: - %s ***", st.outputPath)))
+
+	if !report.Success {
+		return validator.NewError(validator.KindValidationFailed, fmt.Errorf("validation failed: %d resource(s) reported errors (HTTP %d)", len(report.Errors), report.StatusCode))
+	}
 	return nil
 }
+
+// missingSettings returns the flag names of the required settings that are
+// still empty after flags, environment and profile have been applied.
+func missingSettings(args utils.Args) []string {
+	var missing []string
+	for _, s := range []struct{ flag, value string }{
+		{"--source-subscription-id", args.SourceSubscriptionId},
+		{"--source-resource-group", args.SourceResourceGroup},
+		{"--target-subscription-id", args.TargetSubscriptionId},
+		{"--target-resource-group", args.TargetResourceGroup},
+	} {
+		if strings.TrimSpace(s.value) == "" {
+			missing = append(missing, s.flag)
+		}
+	}
+	return missing
+}
+
+// parseReportFormats resolves --report-formats. Markdown is always written,
+// so it is dropped from the extra formats returned.
+func parseReportFormats(names []string) ([]poller.ReportFormat, error) {
+	var formats []poller.ReportFormat
+	for _, name := range names {
+		f, err := poller.ParseReportFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		if f != poller.FormatMarkdown && !slices.Contains(formats, f) {
+			formats = append(formats, f)
+		}
+	}
+	return formats, nil
+}
+
+func pluralResources(n int) string {
+	if n == 1 {
+		return "resource"
+	}
+	return "resources"
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/restapi"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/spf13/cobra"
+)
+
+// apiTokenEnv holds the bearer token for `armv serve` when no --token-file
+// is given, so it can come from a container secret.
+const apiTokenEnv = "ARMV_API_TOKEN"
+
+// newServeCommand returns `armv serve`, which runs ARMV as a REST API for
+// services such as an internal developer portal.
+func newServeCommand(version string) *cobra.Command {
+	var (
+		listen       string
+		tokenFile    string
+		maxJobs      int
+		jobRetention time.Duration
+		drainPeriod  time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run ARMV as a REST API server",
+		Long: `Run ARMV as a versioned REST API on --listen, for services that validate
+moves without shelling out to the CLI.
+
+  POST   /v1/validations              submit a validation job
+  GET    /v1/validations              list jobs, newest first
+  GET    /v1/validations/{id}         job status and verdict
+  DELETE /v1/validations/{id}         cancel a job
+  GET    /v1/validations/{id}/report  report as json, md, html, sarif or junit
+  GET    /v1/subscriptions[/{id}/resourceGroups[/{rg}/resources]]
+
+The API is described by the OpenAPI document at /openapi.json. /healthz
+answers liveness probes, /readyz readiness probes and /metrics serves
+Prometheus metrics; none of them needs a token.
+Every /v1 request must send "Authorization: Bearer <token>", where the token
+(at least 16 characters) is read from --token-file or the ARMV_API_TOKEN
+environment variable. Terminate TLS in front of the server.
+
+Validations run in the background; --max-jobs bounds how many call Azure at
+once and --job-retention how long finished jobs and their reports are kept.
+On SIGTERM or Ctrl-C, /readyz fails while requests are still served for
+--drain-period, so the load balancer can take the server out of rotation;
+in-flight requests then get 10 seconds to finish and jobs still running are
+cancelled.
+
+Azure is called with the server's credential, chosen by --auth-mode,
+--tenant-id and --client-id (or their ARMV_* variables and the selected
+profile) exactly as for a validation run.`,
+		Example: `  ARMV_API_TOKEN=$(openssl rand -hex 32) armv serve --listen :8080
+  armv serve --listen :8080 --token-file /run/secrets/armv-api-token --auth-mode managed-identity`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			if listen == "" {
+				return validator.NewError(validator.KindInvalidInput, errors.New("--listen is required, e.g. --listen :8080"))
+			}
+			if maxJobs < 1 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--max-jobs must be at least 1, got %d", maxJobs))
+			}
+			if jobRetention <= 0 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--job-retention must be positive, got %s", jobRetention))
+			}
+			if drainPeriod <= 0 {
+				return validator.NewError(validator.KindInvalidInput, fmt.Errorf("--drain-period must be positive, got %s", drainPeriod))
+			}
+			token, err := bearerToken(tokenFile, apiTokenEnv)
+			if err != nil {
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+			cred, err := newCredential(authFlags(cmd.Flags()))
+			if err != nil {
+				return err
+			}
+
+			return serveResult(ctx, restapi.Serve(ctx, version, restapi.Options{
+				Addr:         listen,
+				Token:        token,
+				Credential:   cred,
+				MaxJobs:      maxJobs,
+				JobRetention: jobRetention,
+				DrainPeriod:  drainPeriod,
+			}))
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", "", "Address to serve the REST API on, e.g. :8080 (required)")
+	cmd.Flags().StringVar(&tokenFile, "token-file", "", "File holding the bearer token API clients must send (default: $"+apiTokenEnv+")")
+	cmd.Flags().IntVar(&maxJobs, "max-jobs", restapi.DefaultMaxJobs, "Validation jobs that may run against Azure at once; more are queued")
+	cmd.Flags().DurationVar(&jobRetention, "job-retention", restapi.DefaultJobRetention, "How long a finished job and its report stay available")
+	cmd.Flags().DurationVar(&drainPeriod, "drain-period", restapi.DefaultDrainPeriod, "How long /readyz fails before the server stops accepting requests on shutdown")
+	return cmd
+}
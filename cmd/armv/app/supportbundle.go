@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/supportbundle"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// newSupportBundleCommand returns `armv support-bundle`, which zips the last
+// run's report, request payload, inventory and HTTP trace for Azure support.
+func newSupportBundleCommand() *cobra.Command {
+	var (
+		outputPath string
+		runFile    string
+		output     string
+	)
+
+	bundleCmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Package the last run into a zip for Azure support",
+		Long: `Package the last run into a zip for Azure support.
+
+The bundle holds the report, the validate-move request (MoveInfo) payload, the
+resource inventory, the redacted HTTP trace with correlation and request IDs,
+and the armv version and environment. Bearer tokens and other secrets are
+redacted. Nothing is sent anywhere; attach the zip to the support ticket.`,
+		Example: `  armv support-bundle
+  armv support-bundle --run ./output/run-2026-04-20-10-45-12.json --output case-123.zip`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if runFile == "" {
+				latest, err := supportbundle.LatestRunRecord(outputPath)
+				if err != nil {
+					return validator.NewError(validator.KindInvalidInput, err)
+				}
+				runFile = latest
+			}
+
+			now := time.Now()
+			if output == "" {
+				output = filepath.Join(outputPath, fmt.Sprintf("support-bundle-%s.zip", now.Format("2006-01-02-15-04-05")))
+			}
+			if err := utils.MakeFolder(filepath.Dir(output)); err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, utils.FilePermission)
+			if err != nil {
+				return fmt.Errorf("create support bundle: %w", err)
+			}
+			manifest, err := supportbundle.Write(f, supportbundle.Options{RunRecord: runFile, ToolVersion: cmd.Root().Version, Now: now})
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return validator.NewError(validator.KindInvalidInput, err)
+			}
+
+			w := cmd.ErrOrStderr()
+			fmt.Fprintf(w, "Support bundle written to: %s\n", output)
+			if manifest.CorrelationID != "" {
+				fmt.Fprintf(w, "Correlation ID: %s\n", manifest.CorrelationID)
+			}
+			for _, name := range slices.Sorted(maps.Keys(manifest.Missing)) {
+				fmt.Fprintf(w, "Not included: %s (%s)\n", name, manifest.Missing[name])
+			}
+			return nil
+		},
+	}
+
+	bundleCmd.Flags().StringVar(&outputPath, "output-path", DefaultOutputPath, "Output path holding the run to bundle")
+	bundleCmd.Flags().StringVar(&runFile, "run", "", "Run record to bundle (default: newest run-*.json in --output-path)")
+	bundleCmd.Flags().StringVar(&output, "output", "", "Zip file to write (default: support-bundle-<timestamp>.zip in --output-path)")
+
+	return bundleCmd
+}
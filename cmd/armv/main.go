@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/AaronSaikovski/armv/cmd/armv/app"
 )
@@ -25,13 +27,21 @@ func fullVersion() string {
 }
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// SIGINT/SIGTERM cancel the root context so the run can stop polling and
+	// write a partial report. Once cancelled, the default handlers are
+	// restored so a second Ctrl-C terminates immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
 	rootCmd := app.NewRootCommand(fullVersion())
 	rootCmd.SetContext(ctx)
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(app.ExitCode(err))
 	}
 }
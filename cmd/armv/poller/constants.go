@@ -3,10 +3,18 @@ package poller
 import "time"
 
 const (
-	progressBarMax = 100
-	// Azure long-running operations typically take minutes; poll every 2s.
-	sleepDuration  = 2 * time.Second
-	pollingTimeout = 30 * time.Minute
+	// Azure long-running operations typically take minutes; poll every 2s
+	// unless the caller or a Retry-After header says otherwise.
+	DefaultPollInterval = 2 * time.Second
+	DefaultPollTimeout  = 30 * time.Minute
+
+	// MinPollInterval matches the Azure SDK's own lower bound for LRO polling.
+	MinPollInterval = 1 * time.Second
+
+	// maxRetryAfter caps a server-suggested delay so a bogus header cannot
+	// stall the loop; maxBackoff caps exponential backoff while throttled.
+	maxRetryAfter = 2 * time.Minute
+	maxBackoff    = 1 * time.Minute
 
 	// HTTP status codes returned by the validate-move API.
 	//   204 — validation succeeded, no issues found
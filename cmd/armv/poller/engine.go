@@ -0,0 +1,180 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// EventKind identifies a step of the polling loop.
+type EventKind int
+
+const (
+	// EventStarted fires once before the first wait.
+	EventStarted EventKind = iota
+	// EventTick fires immediately before each poll request.
+	EventTick
+	// EventThrottled fires when Azure answers 429/503; Delay is the backoff.
+	EventThrottled
+	// EventDone fires once the operation reaches a terminal state.
+	EventDone
+	// EventFailed fires when polling stops without a verdict (error, timeout, cancellation).
+	EventFailed
+)
+
+// String returns a lower-case name for the kind, used in log output.
+func (k EventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "started"
+	case EventTick:
+		return "tick"
+	case EventThrottled:
+		return "throttled"
+	case EventDone:
+		return "done"
+	case EventFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event describes one step of the polling loop. Fields that do not apply to
+// a kind are left zero.
+type Event struct {
+	Kind EventKind
+	// Poll is the number of poll requests issued so far.
+	Poll int
+	// Elapsed is the wall-clock time since polling started.
+	Elapsed time.Duration
+	// Delay is the wait before the next poll (started, throttled).
+	Delay time.Duration
+	// StatusCode is the HTTP status of the response that triggered the event
+	// (throttled, done).
+	StatusCode int
+	// Err is the cause for throttled (when raised as an error) and failed.
+	Err error
+}
+
+// Observer receives polling events. Observe is called synchronously from the
+// polling loop, so implementations must not block.
+type Observer interface {
+	Observe(Event)
+}
+
+// ObserverFunc adapts a plain function to Observer.
+type ObserverFunc func(Event)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e Event) { f(e) }
+
+// Poll drives respPoller to a terminal state and returns the final response.
+// It is the single polling loop behind PollApi (CLI) and the validator
+// library: the wait is bounded by opts.Timeout, paced by opts.Interval or a
+// server Retry-After, backs off while Azure throttles, and respects ctx at
+// every wait. Each step is logged to slog.Default and reported to observers;
+// nil observers are ignored.
+func Poll[T any](ctx context.Context, respPoller *runtime.Poller[T], opts PollOptions, observers ...Observer) (*PollerResponseData, PollStats, error) {
+	opts = opts.WithDefaults()
+	observers = append([]Observer{NewLogObserver(slog.Default())}, observers...)
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	stats := PollStats{}
+	emit := func(e Event) {
+		e.Poll = stats.Count
+		e.Elapsed = time.Since(start)
+		for _, o := range observers {
+			if o != nil {
+				o.Observe(e)
+			}
+		}
+	}
+	fail := func(err error) (*PollerResponseData, PollStats, error) {
+		stats.Duration = time.Since(start)
+		emit(Event{Kind: EventFailed, Err: err})
+		return nil, stats, err
+	}
+
+	pace := newPacer(opts.Interval)
+
+	// Reusable timer avoids allocating a new Timer per iteration across
+	// the polling window.
+	timer := time.NewTimer(opts.Interval)
+	defer timer.Stop()
+	emit(Event{Kind: EventStarted, Delay: opts.Interval})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fail(fmt.Errorf("polling timeout or cancelled: %w", ctx.Err()))
+		case <-timer.C:
+		}
+
+		stats.Count++
+		emit(Event{Kind: EventTick})
+
+		w, err := respPoller.Poll(ctx)
+		stats.Duration = time.Since(start)
+		if err != nil {
+			if delay, throttled := pace.throttle(err); throttled {
+				emit(Event{Kind: EventThrottled, Delay: delay, StatusCode: statusCode(err), Err: err})
+				timer.Reset(delay)
+				continue
+			}
+			return fail(fmt.Errorf("poll: %w", err))
+		}
+
+		if !respPoller.Done() {
+			if delay, throttled := pace.throttledResponse(w); throttled {
+				emit(Event{Kind: EventThrottled, Delay: delay, StatusCode: w.StatusCode})
+				timer.Reset(delay)
+				continue
+			}
+			timer.Reset(pace.next(w))
+			continue
+		}
+
+		var (
+			respBody []byte
+			code     int
+			status   string
+		)
+		if w != nil {
+			code, status = w.StatusCode, w.Status
+			if w.Body != nil {
+				respBody, err = io.ReadAll(w.Body)
+				_ = w.Body.Close()
+				if err != nil {
+					return fail(fmt.Errorf("read response body: %w", err))
+				}
+			}
+		}
+
+		emit(Event{Kind: EventDone, StatusCode: code})
+		resp := NewPollerResponseData(respBody, code, status)
+		return &resp, stats, nil
+	}
+}
+
+// statusCode extracts the HTTP status from an azcore response error, or 0.
+func statusCode(err error) int {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode
+	}
+	return 0
+}
+
+// ResourceMoveOK reports whether the HTTP status indicates a successful (empty-body) validation.
+func ResourceMoveOK(statusCode int) bool {
+	return statusCode == StatusMoveOK
+}
@@ -0,0 +1,168 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const fakePollURL = "https://management.azure.com/operationResults/fake"
+
+// scriptedTransport answers each poll with the next canned response.
+type scriptedTransport struct {
+	mu        sync.Mutex
+	responses []*http.Response
+}
+
+func (s *scriptedTransport) Do(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.responses) == 0 {
+		return nil, errors.New("unexpected extra poll")
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	resp.Request = req
+	return resp, nil
+}
+
+func fakeResponse(code int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// newScriptedPoller returns a Location-header poller, the kind ARM uses for
+// validateMoveResources, whose polls are answered from responses.
+func newScriptedPoller(t *testing.T, responses ...*http.Response) *runtime.Poller[struct{}] {
+	t.Helper()
+
+	pl := runtime.NewPipeline("armv-test", "v0", runtime.PipelineOptions{}, &policy.ClientOptions{
+		Transport: &scriptedTransport{responses: responses},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+	initial := fakeResponse(http.StatusAccepted, "", http.Header{"Location": []string{fakePollURL}})
+	initial.Request, _ = http.NewRequest(http.MethodPost, "https://management.azure.com/validateMoveResources", nil)
+
+	p, err := runtime.NewPoller[struct{}](initial, pl, nil)
+	if err != nil {
+		t.Fatalf("NewPoller() error = %v", err)
+	}
+	return p
+}
+
+func collectEvents() (*[]Event, Observer) {
+	var events []Event
+	return &events, ObserverFunc(func(e Event) { events = append(events, e) })
+}
+
+func eventKinds(events []Event) []EventKind {
+	kinds := make([]EventKind, len(events))
+	for i, e := range events {
+		kinds[i] = e.Kind
+	}
+	return kinds
+}
+
+func TestPollEmitsLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	p := newScriptedPoller(t,
+		fakeResponse(http.StatusAccepted, "", http.Header{"Retry-After-Ms": []string{"1"}}),
+		fakeResponse(http.StatusConflict, `{"error":{"code":"ResourceMoveValidationFailed"}}`, nil),
+	)
+	events, obs := collectEvents()
+
+	resp, stats, err := Poll(context.Background(), p, PollOptions{Interval: MinPollInterval}, nil, obs)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if resp.RespStatusCode != http.StatusConflict || !strings.Contains(string(resp.RespBody), "ResourceMoveValidationFailed") {
+		t.Errorf("Poll() response = %d %q", resp.RespStatusCode, resp.RespBody)
+	}
+	if stats.Count != 2 {
+		t.Errorf("stats.Count = %d, want 2", stats.Count)
+	}
+
+	want := []EventKind{EventStarted, EventTick, EventTick, EventDone}
+	if got := eventKinds(*events); !equalKinds(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if last := (*events)[len(*events)-1]; last.StatusCode != http.StatusConflict || last.Poll != 2 {
+		t.Errorf("done event = %+v", last)
+	}
+}
+
+func TestPollReportsThrottledResponses(t *testing.T) {
+	t.Parallel()
+
+	p := newScriptedPoller(t,
+		fakeResponse(http.StatusTooManyRequests, "", http.Header{"Retry-After-Ms": []string{"5"}}),
+		fakeResponse(http.StatusNoContent, "", nil),
+	)
+	events, obs := collectEvents()
+
+	resp, _, err := Poll(context.Background(), p, PollOptions{Interval: MinPollInterval}, obs)
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if !ResourceMoveOK(resp.RespStatusCode) {
+		t.Errorf("final status = %d, want 204", resp.RespStatusCode)
+	}
+
+	want := []EventKind{EventStarted, EventTick, EventThrottled, EventTick, EventDone}
+	if got := eventKinds(*events); !equalKinds(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if th := (*events)[2]; th.StatusCode != http.StatusTooManyRequests || th.Delay != 5*time.Millisecond {
+		t.Errorf("throttled event = %+v", th)
+	}
+}
+
+func TestPollEmitsFailedOnCancellation(t *testing.T) {
+	t.Parallel()
+
+	p := newScriptedPoller(t)
+	events, obs := collectEvents()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := Poll(ctx, p, PollOptions{}, obs)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Poll() error = %v, want context.Canceled", err)
+	}
+	want := []EventKind{EventStarted, EventFailed}
+	if got := eventKinds(*events); !equalKinds(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if (*events)[1].Err == nil {
+		t.Error("failed event carries no error")
+	}
+}
+
+func equalKinds(a, b []EventKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+const (
+	// historyFileName is the polling-history file under the user cache dir.
+	historyFileName = "poll-history.json"
+
+	// maxHistoryEntries bounds the history file; the oldest entries go first.
+	maxHistoryEntries = 100
+
+	// similarCountFactor is how far a past run's resource count may differ
+	// (as a ratio either way) and still inform the estimate.
+	similarCountFactor = 2
+)
+
+// HistoryEntry records how long one validate-move took to poll to a verdict.
+type HistoryEntry struct {
+	ResourceCount int           `json:"resource_count"`
+	Duration      time.Duration `json:"duration_ns"`
+	RecordedAt    time.Time     `json:"recorded_at"`
+}
+
+// History is the set of past polling durations used to estimate how long a
+// new validation will take. It is stored as JSON in the user cache directory.
+type History struct {
+	path    string
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// DefaultHistoryPath returns <user cache dir>/armv/poll-history.json.
+func DefaultHistoryPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "armv", historyFileName), nil
+}
+
+// LoadHistory reads the history at path. A missing file yields an empty
+// history; a corrupt one is discarded, since it only feeds an estimate.
+func LoadHistory(path string) *History {
+	h := &History{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		h.Entries = nil
+	}
+	return h
+}
+
+// Estimate returns the median polling duration of past runs whose resource
+// count is within a factor of two of resourceCount. ok is false when there
+// is no comparable run.
+func (h *History) Estimate(resourceCount int) (estimate time.Duration, ok bool) {
+	if h == nil || resourceCount <= 0 {
+		return 0, false
+	}
+
+	var similar []time.Duration
+	for _, e := range h.Entries {
+		if e.ResourceCount*similarCountFactor >= resourceCount && e.ResourceCount <= resourceCount*similarCountFactor {
+			similar = append(similar, e.Duration)
+		}
+	}
+	if len(similar) == 0 {
+		return 0, false
+	}
+
+	slices.Sort(similar)
+	mid := len(similar) / 2
+	if len(similar)%2 == 0 {
+		return (similar[mid-1] + similar[mid]) / 2, true
+	}
+	return similar[mid], true
+}
+
+// Record appends a completed run and saves the history, keeping at most
+// maxHistoryEntries.
+func (h *History) Record(resourceCount int, d time.Duration, at time.Time) error {
+	if h == nil || h.path == "" {
+		return errors.New("history has no file path")
+	}
+
+	h.Entries = append(h.Entries, HistoryEntry{ResourceCount: resourceCount, Duration: d, RecordedAt: at.UTC()})
+	if len(h.Entries) > maxHistoryEntries {
+		h.Entries = h.Entries[len(h.Entries)-maxHistoryEntries:]
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode poll history: %w", err)
+	}
+	return utils.WriteOutputFile(filepath.Dir(h.path), filepath.Base(h.path), string(data)+"\n")
+}
@@ -0,0 +1,31 @@
+package poller
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewLogObserver returns an Observer that writes each polling event to
+// logger: ticks at debug level, everything else at info. Failures are not
+// logged at warn because the caller always returns them as errors.
+func NewLogObserver(logger *slog.Logger) Observer {
+	return ObserverFunc(func(e Event) {
+		attrs := []slog.Attr{
+			slog.String("event", e.Kind.String()),
+			slog.Int("poll", e.Poll),
+			slog.Duration("elapsed", e.Elapsed),
+		}
+		level := slog.LevelInfo
+		switch e.Kind {
+		case EventTick:
+			level = slog.LevelDebug
+		case EventThrottled:
+			attrs = append(attrs, slog.Int("status", e.StatusCode), slog.Duration("retry_in", e.Delay))
+		case EventDone:
+			attrs = append(attrs, slog.Int("status", e.StatusCode))
+		case EventFailed:
+			attrs = append(attrs, slog.Any("error", e.Err))
+		}
+		logger.LogAttrs(context.Background(), level, "validate-move poll", attrs...)
+	})
+}
@@ -0,0 +1,152 @@
+package poller
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// PollOptions controls the polling cadence. Zero fields fall back to
+// DefaultPollInterval and DefaultPollTimeout.
+type PollOptions struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// PollStats summarises a completed poll loop for the report.
+type PollStats struct {
+	Count    int
+	Duration time.Duration
+}
+
+// WithDefaults returns o with zero fields replaced by the package defaults.
+func (o PollOptions) WithDefaults() PollOptions {
+	if o.Interval == 0 {
+		o.Interval = DefaultPollInterval
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultPollTimeout
+	}
+	return o
+}
+
+// Validate rejects intervals below MinPollInterval and timeouts shorter
+// than one interval. Zero fields are valid and mean "use the default".
+func (o PollOptions) Validate() error {
+	if o.Interval < 0 || (o.Interval > 0 && o.Interval < MinPollInterval) {
+		return fmt.Errorf("poll interval %s is below the minimum of %s", o.Interval, MinPollInterval)
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("poll timeout %s must be positive", o.Timeout)
+	}
+	if d := o.WithDefaults(); d.Timeout < d.Interval {
+		return fmt.Errorf("poll timeout %s is shorter than the poll interval %s", d.Timeout, d.Interval)
+	}
+	return nil
+}
+
+// pacer decides how long to wait before each poll. It honours a server's
+// Retry-After on normal responses and backs off exponentially, with full
+// jitter, while Azure is throttling.
+type pacer struct {
+	interval  time.Duration
+	throttled int
+	jitter    func() float64
+}
+
+func newPacer(interval time.Duration) *pacer {
+	return &pacer{interval: interval, jitter: rand.Float64}
+}
+
+// next returns the delay after a successful (non-terminal) poll response.
+func (p *pacer) next(resp *http.Response) time.Duration {
+	p.throttled = 0
+	if d, ok := retryAfter(resp); ok {
+		return d
+	}
+	return p.interval
+}
+
+// throttle reports whether err is a throttling response and, if so, the
+// delay before retrying. Retry-After on the error response wins over the
+// computed backoff.
+func (p *pacer) throttle(err error) (time.Duration, bool) {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || !isThrottled(respErr.StatusCode) {
+		return 0, false
+	}
+	return p.delayAfterThrottle(respErr.RawResponse), true
+}
+
+// throttledResponse is throttle for a 429/503 that arrives as a normal,
+// non-terminal poll response, which is how azcore's Location poller reports
+// throttling once its own retries are exhausted.
+func (p *pacer) throttledResponse(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || !isThrottled(resp.StatusCode) {
+		return 0, false
+	}
+	return p.delayAfterThrottle(resp), true
+}
+
+func (p *pacer) delayAfterThrottle(resp *http.Response) time.Duration {
+	p.throttled++
+	if d, ok := retryAfter(resp); ok {
+		return d
+	}
+	return backoff(p.interval, p.throttled, p.jitter())
+}
+
+func isThrottled(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// backoff returns a full-jitter exponential delay: a uniformly random point
+// between base and min(maxBackoff, base*2^attempt).
+func backoff(base time.Duration, attempt int, jitter float64) time.Duration {
+	ceiling := base
+	for i := 0; i < attempt && ceiling < maxBackoff; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, maxBackoff)
+	return base + time.Duration(jitter*float64(ceiling-base))
+}
+
+// retryAfter extracts a server-suggested delay from resp, checking the
+// millisecond headers Azure uses before the standard Retry-After (which may
+// be delta-seconds or an HTTP date). The result is capped at maxRetryAfter.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	var d time.Duration
+	for _, h := range []string{"Retry-After-Ms", "X-Ms-Retry-After-Ms"} {
+		if v := resp.Header.Get(h); v != "" {
+			if ms, err := strconv.Atoi(v); err == nil && ms > 0 {
+				d = time.Duration(ms) * time.Millisecond
+				break
+			}
+		}
+	}
+	if d == 0 {
+		v := resp.Header.Get("Retry-After")
+		if v == "" {
+			return 0, false
+		}
+		if secs, err := strconv.Atoi(v); err == nil {
+			d = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(v); err == nil {
+			d = time.Until(at)
+		}
+	}
+
+	if d <= 0 {
+		return 0, false
+	}
+	return min(d, maxRetryAfter), true
+}
@@ -0,0 +1,165 @@
+package poller
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestPollOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    PollOptions
+		wantErr bool
+	}{
+		{name: "zero uses defaults", opts: PollOptions{}},
+		{name: "explicit values", opts: PollOptions{Interval: 5 * time.Second, Timeout: time.Hour}},
+		{name: "interval below minimum", opts: PollOptions{Interval: 500 * time.Millisecond}, wantErr: true},
+		{name: "negative timeout", opts: PollOptions{Timeout: -time.Second}, wantErr: true},
+		{name: "timeout shorter than interval", opts: PollOptions{Interval: 10 * time.Second, Timeout: 5 * time.Second}, wantErr: true},
+		{name: "timeout shorter than default interval", opts: PollOptions{Timeout: time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPollOptionsWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	got := PollOptions{}.WithDefaults()
+	if got.Interval != DefaultPollInterval || got.Timeout != DefaultPollTimeout {
+		t.Errorf("WithDefaults() = %+v", got)
+	}
+	custom := PollOptions{Interval: 7 * time.Second}.WithDefaults()
+	if custom.Interval != 7*time.Second || custom.Timeout != DefaultPollTimeout {
+		t.Errorf("WithDefaults() overwrote an explicit field: %+v", custom)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    time.Duration
+		wantOK  bool
+	}{
+		{name: "no header", headers: nil},
+		{name: "delta seconds", headers: map[string]string{"Retry-After": "15"}, want: 15 * time.Second, wantOK: true},
+		{name: "retry-after-ms wins", headers: map[string]string{"Retry-After": "15", "Retry-After-Ms": "1500"}, want: 1500 * time.Millisecond, wantOK: true},
+		{name: "x-ms-retry-after-ms", headers: map[string]string{"X-Ms-Retry-After-Ms": "250"}, want: 250 * time.Millisecond, wantOK: true},
+		{name: "capped", headers: map[string]string{"Retry-After": "86400"}, want: maxRetryAfter, wantOK: true},
+		{name: "zero is ignored", headers: map[string]string{"Retry-After": "0"}},
+		{name: "garbage is ignored", headers: map[string]string{"Retry-After": "soon"}},
+		{name: "date in the past is ignored", headers: map[string]string{"Retry-After": "Wed, 21 Oct 2015 07:28:00 GMT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp := &http.Response{Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			got, ok := retryAfter(resp)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("retryAfter() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := retryAfter(nil); ok {
+		t.Error("retryAfter(nil) reported a delay")
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	t.Parallel()
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+
+	got, ok := retryAfter(resp)
+	if !ok || got <= 20*time.Second || got > 30*time.Second {
+		t.Errorf("retryAfter(date +30s) = (%v, %v)", got, ok)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	base := 2 * time.Second
+	tests := []struct {
+		name    string
+		attempt int
+		jitter  float64
+		want    time.Duration
+	}{
+		{name: "zero jitter returns base", attempt: 3, jitter: 0, want: base},
+		{name: "full jitter first attempt doubles", attempt: 1, jitter: 1, want: 4 * time.Second},
+		{name: "full jitter third attempt", attempt: 3, jitter: 1, want: 16 * time.Second},
+		{name: "half jitter second attempt", attempt: 2, jitter: 0.5, want: 5 * time.Second},
+		{name: "capped at maxBackoff", attempt: 20, jitter: 1, want: maxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := backoff(base, tt.attempt, tt.jitter); got != tt.want {
+				t.Errorf("backoff(%v, %d, %v) = %v, want %v", base, tt.attempt, tt.jitter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPacerThrottle(t *testing.T) {
+	t.Parallel()
+
+	p := newPacer(2 * time.Second)
+	p.jitter = func() float64 { return 1 }
+
+	if _, ok := p.throttle(errors.New("boom")); ok {
+		t.Error("plain error treated as throttling")
+	}
+	if _, ok := p.throttle(&azcore.ResponseError{StatusCode: http.StatusInternalServerError}); ok {
+		t.Error("500 treated as throttling")
+	}
+
+	d1, ok := p.throttle(&azcore.ResponseError{StatusCode: http.StatusTooManyRequests})
+	if !ok || d1 != 4*time.Second {
+		t.Errorf("first 429 = (%v, %v), want (4s, true)", d1, ok)
+	}
+	d2, _ := p.throttle(&azcore.ResponseError{StatusCode: http.StatusServiceUnavailable})
+	if d2 != 8*time.Second {
+		t.Errorf("second consecutive throttle = %v, want 8s", d2)
+	}
+
+	throttledWithHint := &azcore.ResponseError{
+		StatusCode:  http.StatusTooManyRequests,
+		RawResponse: &http.Response{Header: http.Header{"Retry-After": []string{"9"}}},
+	}
+	if d, _ := p.throttle(throttledWithHint); d != 9*time.Second {
+		t.Errorf("429 with Retry-After = %v, want 9s", d)
+	}
+
+	// A normal response resets the backoff sequence.
+	if d := p.next(&http.Response{Header: http.Header{}}); d != 2*time.Second {
+		t.Errorf("next() without Retry-After = %v, want the interval", d)
+	}
+	if d, _ := p.throttle(&azcore.ResponseError{StatusCode: http.StatusTooManyRequests}); d != 4*time.Second {
+		t.Errorf("throttle after reset = %v, want 4s", d)
+	}
+}
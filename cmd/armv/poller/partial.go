@@ -0,0 +1,83 @@
+package poller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+// Phase names a step of the validation workflow. It is recorded in partial
+// reports so an interrupted run shows how far it got.
+type Phase string
+
+const (
+	PhaseAuthenticating Phase = "authenticating"
+	PhaseLoginCheck     Phase = "checking login"
+	PhaseResourceGroups Phase = "checking resource groups"
+	PhaseEnumerating    Phase = "enumerating resources"
+	PhaseSubmitting     Phase = "submitting validate-move request"
+	PhasePolling        Phase = "polling validate-move"
+)
+
+// PartialReport describes a run that was interrupted before Azure returned a
+// verdict. ResumeToken is empty if the request was never accepted.
+type PartialReport struct {
+	InterruptedAt time.Time
+	Phase         Phase
+	Elapsed       time.Duration
+	Context       ReportContext
+	ResourceIDs   []string
+	ResumeToken   string
+	ResumeFile    string
+}
+
+// RenderMarkdown produces the Markdown body for an interrupted run.
+func (p PartialReport) RenderMarkdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Azure Resource Move Validation Report (INTERRUPTED)\n\n")
+	fmt.Fprintf(&b, "- **Interrupted:** %s\n", p.InterruptedAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(&b, "- **Phase reached:** %s\n", p.Phase)
+	fmt.Fprintf(&b, "- **Elapsed:** %s\n", p.Elapsed.Round(time.Second))
+	fmt.Fprintf(&b, "- **Source:** `%s` / `%s`\n", p.Context.SourceSubscriptionID, p.Context.SourceResourceGroup)
+	fmt.Fprintf(&b, "- **Target:** `%s` / `%s`\n", p.Context.TargetSubscriptionID, p.Context.TargetResourceGroup)
+	b.WriteString("\n")
+
+	if p.ResumeToken == "" {
+		b.WriteString("The validate-move request had not been accepted by Azure; re-run the validation.\n\n")
+	} else {
+		b.WriteString("Azure accepted the validate-move request, which may still be running. ")
+		if p.ResumeFile != "" {
+			fmt.Fprintf(&b, "Resume it with:\n\n```\narmv resume --token-file %s\n```\n\n", p.ResumeFile)
+		} else {
+			b.WriteString("Save the resume token below to a resume file to reattach with `armv resume`.\n\n")
+		}
+		b.WriteString("## Resume Token\n\n```json\n")
+		b.WriteString(p.ResumeToken)
+		b.WriteString("\n```\n\n")
+	}
+
+	fmt.Fprintf(&b, "## Resource Inventory (%d)\n\n", len(p.ResourceIDs))
+	if len(p.ResourceIDs) == 0 {
+		b.WriteString("Resources had not been enumerated.\n")
+		return b.String()
+	}
+	b.WriteString("| # | Resource Type | Name |\n|---|---|---|\n")
+	for i, id := range p.ResourceIDs {
+		typ, name := ParseResourceID(id)
+		fmt.Fprintf(&b, "| %d | %s | %s |\n", i+1, mdEscape(typ), mdEscape(name))
+	}
+	return b.String()
+}
+
+// WritePartialReport writes p to outputPath as partial-<timestamp>.md and
+// returns the file name.
+func WritePartialReport(outputPath string, p PartialReport) (string, error) {
+	fileName := fmt.Sprintf("partial-%s.md", p.InterruptedAt.Format("2006-01-02-15-04-05"))
+	if err := utils.WriteOutputFile(outputPath, fileName, p.RenderMarkdown()); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
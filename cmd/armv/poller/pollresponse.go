@@ -2,34 +2,73 @@ package poller
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/AaronSaikovski/armv/pkg/utils"
 )
 
 // writeOutput builds a ValidationReport, renders it as Markdown, and writes
-// it to a timestamped .md file under outputPath. The returned report is
-// returned so the caller can drive the console summary from the same data.
-func (pollResp *PollerResponseData) writeOutput(outputPath string, ctx ReportContext) (ValidationReport, error) {
-	fileName := fmt.Sprintf("output-%s.md", time.Now().Format("2006-01-02-15-04-05"))
-
-	// Pretty-print the raw Azure body (if any). Non-JSON bodies are kept
-	// verbatim rather than failing the operation — the markdown rendering
-	// handles unparseable JSON by showing a FAILED header with no table.
-	var prettyJSON string
-	if pollResp.RespStatusCode != StatusMoveOK && len(pollResp.RespBody) > 0 {
-		if pj, err := utils.PrettyJsonString(string(pollResp.RespBody)); err == nil {
-			prettyJSON = pj
-		} else {
-			prettyJSON = string(pollResp.RespBody)
-		}
-	}
+// it to a timestamped .md file under outputPath. The raw response is saved
+// next to it as run-<stamp>.json so the report can be re-rendered offline.
+// The returned report is returned so the caller can drive the console
+// summary from the same data. diag, if non-nil, is stored in the run record.
+func (pollResp *PollerResponseData) writeOutput(outputPath string, ctx ReportContext, diag *RunDiagnostics) (ValidationReport, error) {
+	now := time.Now()
+	stamp := reportStamp(now)
 
-	report := BuildValidationReport(pollResp.RespStatusCode, pollResp.RespStatus, pollResp.RespBody, prettyJSON, ctx)
+	// Building the report from the record keeps the .md byte-identical to a
+	// later `armv report render --format md` of the same run.
+	rec := NewRunRecord(*pollResp, ctx, now)
+	rec.Diagnostics = diag
+	report := rec.Report()
 	markdown := RenderMarkdown(report)
 
-	if err := utils.WriteOutputFile(outputPath, fileName, markdown); err != nil {
+	if err := utils.WriteOutputFile(outputPath, fmt.Sprintf("output-%s.md", stamp), markdown); err != nil {
 		return ValidationReport{}, fmt.Errorf("failed to write output file: %w", err)
 	}
+
+	if err := WriteRunRecord(outputPath, fmt.Sprintf("run-%s.json", stamp), rec); err != nil {
+		return ValidationReport{}, fmt.Errorf("failed to write run record: %w", err)
+	}
+	slog.Debug("poller: wrote report", "output_path", outputPath, "stamp", stamp, "success", report.Success, "errors", len(report.Errors))
 	return report, nil
 }
+
+// WriteReportFormats renders report in each of formats next to its Markdown
+// report, as output-<stamp><ext> with the report's timestamp, and returns
+// the file names written.
+func WriteReportFormats(outputPath string, report ValidationReport, formats []ReportFormat) ([]string, error) {
+	stamp := reportStamp(report.GeneratedAt)
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		rendered, err := Render(report, f)
+		if err != nil {
+			return names, err
+		}
+		name := fmt.Sprintf("output-%s%s", stamp, f.Extension())
+		if err := utils.WriteOutputFile(outputPath, name, rendered); err != nil {
+			return names, fmt.Errorf("failed to write %s report: %w", f, err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// reportStamp formats the local-time stamp shared by a run's output files.
+func reportStamp(t time.Time) string {
+	return t.Local().Format("2006-01-02-15-04-05")
+}
+
+// prettyBody pretty-prints the raw Azure body (if any). Non-JSON bodies are
+// kept verbatim rather than failing the operation — the markdown rendering
+// handles unparseable JSON by showing a FAILED header with no table.
+func prettyBody(statusCode int, body []byte) string {
+	if statusCode == StatusMoveOK || len(body) == 0 {
+		return ""
+	}
+	if pj, err := utils.PrettyJsonString(string(body)); err == nil {
+		return pj
+	}
+	return string(body)
+}
@@ -132,7 +132,7 @@ func TestWriteOutputEndToEnd(t *testing.T) {
 				ResourceCount:        3,
 			}
 
-			report, err := resp.writeOutput(outDir, ctx)
+			report, err := resp.writeOutput(outDir, ctx, nil)
 			if err != nil {
 				t.Fatalf("writeOutput: %v", err)
 			}
@@ -143,18 +143,8 @@ func TestWriteOutputEndToEnd(t *testing.T) {
 				t.Errorf("report.Success = %v, want %v", report.Success, want)
 			}
 
-			// Find the timestamped file that writeOutput created.
-			entries, err := os.ReadDir(outDir)
-			if err != nil {
-				t.Fatalf("ReadDir(%q): %v", outDir, err)
-			}
-			if len(entries) != 1 {
-				t.Fatalf("expected exactly 1 file in %q, got %d", outDir, len(entries))
-			}
-			name := entries[0].Name()
-			if !strings.HasPrefix(name, "output-") || !strings.HasSuffix(name, ".md") {
-				t.Errorf("file name %q does not match output-*.md pattern", name)
-			}
+			// Find the timestamped report that writeOutput created.
+			name := onlyFile(t, outDir, "output-*.md")
 
 			data, err := os.ReadFile(filepath.Join(outDir, name))
 			if err != nil {
@@ -178,7 +168,7 @@ func TestWriteOutputCreatesNestedOutputDir(t *testing.T) {
 	nested := filepath.Join(t.TempDir(), "a", "b", "c")
 	resp := NewPollerResponseData(nil, StatusMoveOK, "No Content")
 
-	if _, err := resp.writeOutput(nested, ReportContext{}); err != nil {
+	if _, err := resp.writeOutput(nested, ReportContext{}, nil); err != nil {
 		t.Fatalf("writeOutput into non-existent nested dir: %v", err)
 	}
 	if _, err := os.Stat(nested); err != nil {
@@ -195,20 +185,12 @@ func TestWriteOutputFilenameFormat(t *testing.T) {
 
 	outDir := t.TempDir()
 	resp := NewPollerResponseData(nil, StatusMoveOK, "No Content")
-	if _, err := resp.writeOutput(outDir, ReportContext{}); err != nil {
+	if _, err := resp.writeOutput(outDir, ReportContext{}, nil); err != nil {
 		t.Fatalf("writeOutput: %v", err)
 	}
 
-	entries, err := os.ReadDir(outDir)
-	if err != nil {
-		t.Fatalf("ReadDir: %v", err)
-	}
-	if len(entries) != 1 {
-		t.Fatalf("expected 1 file, got %d", len(entries))
-	}
-
 	// Pattern: output-YYYY-MM-DD-HH-MM-SS.md
-	name := entries[0].Name()
+	name := onlyFile(t, outDir, "output-*.md")
 	const (
 		prefix = "output-"
 		ext    = ".md"
@@ -227,3 +209,85 @@ func TestWriteOutputFilenameFormat(t *testing.T) {
 		}
 	}
 }
+
+// TestWriteOutputSavesRunRecord verifies the raw response is persisted next to
+// the Markdown report under the same timestamp, and that re-rendering the
+// record reproduces the report byte for byte.
+func TestWriteOutputSavesRunRecord(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	body := []byte(`{"error":{"code":"ResourceMoveValidationFailed","message":"m","details":[{"code":"C1","target":"/subscriptions/s/resourceGroups/r/providers/P/T/one","message":"m1"}]}}`)
+	resp := NewPollerResponseData(body, StatusMoveFailure, "Conflict")
+	ctx := ReportContext{SourceSubscriptionID: "sub-src", SourceResourceGroup: "rg-src", ResourceCount: 1}
+	diag := &RunDiagnostics{
+		ToolVersion:   "v1.2.3",
+		CorrelationID: "corr-1",
+		ResourceIDs:   []string{"/subscriptions/s/resourceGroups/r/providers/P/T/one"},
+		TraceFile:     "trace-2026-04-20-10-45-00.jsonl",
+	}
+
+	if _, err := resp.writeOutput(outDir, ctx, diag); err != nil {
+		t.Fatalf("writeOutput: %v", err)
+	}
+
+	mdName := onlyFile(t, outDir, "output-*.md")
+	runName := onlyFile(t, outDir, "run-*.json")
+	if stamp := strings.TrimSuffix(strings.TrimPrefix(mdName, "output-"), ".md"); runName != "run-"+stamp+".json" {
+		t.Errorf("run record %q does not share the report timestamp %q", runName, stamp)
+	}
+
+	rec, err := LoadRunRecord(filepath.Join(outDir, runName))
+	if err != nil {
+		t.Fatalf("LoadRunRecord: %v", err)
+	}
+	if rec.StatusCode != StatusMoveFailure || rec.Body != string(body) || rec.Context != ctx {
+		t.Errorf("round-tripped record mismatch: %+v", rec)
+	}
+	if rec.Diagnostics == nil || rec.Diagnostics.CorrelationID != "corr-1" || rec.Diagnostics.TraceFile != diag.TraceFile || len(rec.Diagnostics.ResourceIDs) != 1 {
+		t.Errorf("round-tripped diagnostics mismatch: %+v", rec.Diagnostics)
+	}
+
+	md, err := os.ReadFile(filepath.Join(outDir, mdName))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if got := RenderMarkdown(rec.Report()); got != string(md) {
+		t.Errorf("re-rendered markdown differs from the original.\ngot:\n%s\nwant:\n%s", got, md)
+	}
+}
+
+// onlyFile returns the name of the single file in dir matching pattern.
+func onlyFile(t *testing.T, dir, pattern string) string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		t.Fatalf("Glob(%q): %v", pattern, err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected exactly 1 %s file in %q, got %d", pattern, dir, len(matches))
+	}
+	return filepath.Base(matches[0])
+}
+
+// TestWriteReportFormatsSharesTimestamp verifies extra formats are written
+// under the same stamp as the Markdown report they accompany.
+func TestWriteReportFormatsSharesTimestamp(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	resp := NewPollerResponseData(nil, StatusMoveOK, "No Content")
+	report, err := resp.writeOutput(outDir, ReportContext{}, nil)
+	if err != nil {
+		t.Fatalf("writeOutput: %v", err)
+	}
+
+	names, err := WriteReportFormats(outDir, report, []ReportFormat{FormatHTML, FormatSARIF})
+	if err != nil {
+		t.Fatalf("WriteReportFormats: %v", err)
+	}
+	stamp := strings.TrimSuffix(onlyFile(t, outDir, "output-*.md"), ".md")
+	if len(names) != 2 || names[0] != stamp+".html" || names[1] != stamp+".sarif" {
+		t.Errorf("WriteReportFormats() = %v, want %s.html and %s.sarif", names, stamp, stamp)
+	}
+}
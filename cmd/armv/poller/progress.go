@@ -0,0 +1,124 @@
+package poller
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// plainTickEvery spaces out polling status lines when not on a terminal, so
+// CI logs get a heartbeat rather than a line every poll.
+const plainTickEvery = 30 * time.Second
+
+// Progress reports the CLI's progress through each Phase and, while polling,
+// the elapsed time and an estimate of the time remaining based on History.
+// On a terminal the polling status is a single line updated in place;
+// otherwise every update is a plain timestamped log line. Progress is an
+// Observer, so it is passed straight to PollApi.
+type Progress struct {
+	mu            sync.Mutex
+	w             io.Writer
+	interactive   bool
+	history       *History
+	now           func() time.Time
+	resourceCount int
+	lastPlainTick time.Time
+	lineOpen      bool
+}
+
+// NewProgress returns a Progress writing to w. interactive selects the
+// in-place status line; history may be nil, in which case no ETA is shown.
+func NewProgress(w io.Writer, interactive bool, history *History) *Progress {
+	return &Progress{w: w, interactive: interactive, history: history, now: time.Now}
+}
+
+// Phase announces the start of a workflow phase. detail, if non-empty, is
+// appended in parentheses (e.g. the resource count after enumeration).
+func (p *Progress) Phase(phase Phase, detail string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	msg := string(phase)
+	if detail != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, detail)
+	}
+	p.line(msg)
+}
+
+// SetResourceCount sets the number of resources being validated, which
+// selects comparable runs for the ETA.
+func (p *Progress) SetResourceCount(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resourceCount = n
+}
+
+// Observe renders a polling event.
+func (p *Progress) Observe(e Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e.Kind {
+	case EventStarted:
+		if est, ok := p.history.Estimate(p.resourceCount); ok {
+			p.line(fmt.Sprintf("similar validations took about %s", roundDuration(est)))
+		}
+	case EventTick:
+		status := fmt.Sprintf("%s: elapsed %s, %s (poll %d)", PhasePolling, roundDuration(e.Elapsed), p.eta(e.Elapsed), e.Poll)
+		if p.interactive {
+			p.status(status)
+			return
+		}
+		if p.lastPlainTick.IsZero() || p.now().Sub(p.lastPlainTick) >= plainTickEvery {
+			p.lastPlainTick = p.now()
+			p.line(status)
+		}
+	case EventThrottled:
+		status := fmt.Sprintf("%s: Azure is throttling (HTTP %d), retrying in %s", PhasePolling, e.StatusCode, roundDuration(e.Delay))
+		if p.interactive {
+			p.status(status)
+			return
+		}
+		p.line(status)
+	case EventDone:
+		p.line(fmt.Sprintf("validate-move finished in %s (HTTP %d, %d %s)", roundDuration(e.Elapsed), e.StatusCode, e.Poll, pluralise("poll", e.Poll)))
+	case EventFailed:
+		p.line(fmt.Sprintf("polling stopped after %s: %v", roundDuration(e.Elapsed), e.Err))
+	}
+}
+
+// eta describes the expected remaining time.
+func (p *Progress) eta(elapsed time.Duration) string {
+	est, ok := p.history.Estimate(p.resourceCount)
+	if !ok {
+		return "no estimate yet"
+	}
+	if remaining := est - elapsed; remaining > 0 {
+		return fmt.Sprintf("about %s remaining", roundDuration(remaining))
+	}
+	return fmt.Sprintf("taking longer than usual (typically %s)", roundDuration(est))
+}
+
+// line writes a complete line, first terminating any open status line.
+func (p *Progress) line(msg string) {
+	if p.lineOpen {
+		fmt.Fprintln(p.w)
+		p.lineOpen = false
+	}
+	if p.interactive {
+		fmt.Fprintf(p.w, "» %s\n", msg)
+		return
+	}
+	fmt.Fprintf(p.w, "%s armv: %s\n", p.now().UTC().Format(time.RFC3339), msg)
+}
+
+// status rewrites the in-place status line (terminal only).
+func (p *Progress) status(msg string) {
+	fmt.Fprintf(p.w, "\r\033[K» %s", msg)
+	p.lineOpen = true
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Second)
+}
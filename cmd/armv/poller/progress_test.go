@@ -0,0 +1,153 @@
+package poller
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryEstimate(t *testing.T) {
+	t.Parallel()
+
+	h := &History{Entries: []HistoryEntry{
+		{ResourceCount: 10, Duration: 60 * time.Second},
+		{ResourceCount: 12, Duration: 90 * time.Second},
+		{ResourceCount: 15, Duration: 120 * time.Second},
+		{ResourceCount: 200, Duration: 20 * time.Minute},
+	}}
+
+	tests := []struct {
+		name   string
+		count  int
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "median of similar runs", count: 11, want: 90 * time.Second, wantOK: true},
+		{name: "even number of matches averages the middle pair", count: 24, want: 105 * time.Second, wantOK: true},
+		{name: "large run only matches large history", count: 150, want: 20 * time.Minute, wantOK: true},
+		{name: "nothing comparable", count: 50},
+		{name: "zero resources", count: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := h.Estimate(tt.count)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Estimate(%d) = (%v, %v), want (%v, %v)", tt.count, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	var nilHistory *History
+	if _, ok := nilHistory.Estimate(10); ok {
+		t.Error("nil history produced an estimate")
+	}
+}
+
+func TestHistoryRecordRoundTripAndCap(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "armv", historyFileName)
+	h := LoadHistory(path)
+	if len(h.Entries) != 0 {
+		t.Fatalf("missing file loaded %d entries", len(h.Entries))
+	}
+
+	at := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	for i := range maxHistoryEntries + 5 {
+		if err := h.Record(i+1, time.Duration(i+1)*time.Second, at); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	reloaded := LoadHistory(path)
+	if len(reloaded.Entries) != maxHistoryEntries {
+		t.Fatalf("reloaded %d entries, want %d", len(reloaded.Entries), maxHistoryEntries)
+	}
+	if first := reloaded.Entries[0]; first.ResourceCount != 6 || first.Duration != 6*time.Second {
+		t.Errorf("oldest entries were not dropped first: %+v", first)
+	}
+}
+
+func TestLoadHistoryIgnoresCorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), historyFileName)
+	if err := os.WriteFile(path, []byte("{nope"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if h := LoadHistory(path); len(h.Entries) != 0 {
+		t.Errorf("corrupt history loaded %d entries", len(h.Entries))
+	}
+}
+
+func TestProgressPlainLines(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	h := &History{Entries: []HistoryEntry{{ResourceCount: 10, Duration: 2 * time.Minute}}}
+	p := NewProgress(&buf, false, h)
+	now := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	p.Phase(PhaseLoginCheck, "")
+	p.SetResourceCount(10)
+	p.Phase(PhaseSubmitting, "10 resources")
+	p.Observe(Event{Kind: EventStarted})
+	p.Observe(Event{Kind: EventTick, Poll: 1, Elapsed: 2 * time.Second})
+	now = now.Add(10 * time.Second)
+	p.Observe(Event{Kind: EventTick, Poll: 2, Elapsed: 12 * time.Second}) // suppressed
+	now = now.Add(plainTickEvery)
+	p.Observe(Event{Kind: EventTick, Poll: 3, Elapsed: 3 * time.Minute})
+	p.Observe(Event{Kind: EventThrottled, StatusCode: 429, Delay: 8 * time.Second})
+	p.Observe(Event{Kind: EventDone, Poll: 4, Elapsed: 200 * time.Second, StatusCode: 204})
+
+	got := buf.String()
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want 7:\n%s", len(lines), got)
+	}
+	for _, want := range []string{
+		"2026-04-20T10:00:00Z armv: checking login",
+		"submitting validate-move request (10 resources)",
+		"similar validations took about 2m0s",
+		"elapsed 2s, about 1m58s remaining (poll 1)",
+		"elapsed 3m0s, taking longer than usual (typically 2m0s) (poll 3)",
+		"Azure is throttling (HTTP 429), retrying in 8s",
+		"validate-move finished in 3m20s (HTTP 204, 4 polls)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\r") {
+		t.Error("plain output contains carriage returns")
+	}
+}
+
+func TestProgressInteractiveStatusLine(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	p := NewProgress(&buf, true, nil)
+
+	p.Observe(Event{Kind: EventTick, Poll: 1, Elapsed: time.Second})
+	p.Observe(Event{Kind: EventTick, Poll: 2, Elapsed: 3 * time.Second})
+	p.Observe(Event{Kind: EventFailed, Elapsed: 4 * time.Second, Err: errors.New("context canceled")})
+
+	got := buf.String()
+	if strings.Count(got, "\r\033[K") != 2 {
+		t.Errorf("expected two in-place updates:\n%q", got)
+	}
+	if !strings.Contains(got, "no estimate yet") {
+		t.Errorf("missing no-estimate text:\n%q", got)
+	}
+	if !strings.HasSuffix(got, "(poll 2)\n» polling stopped after 4s: context canceled\n") {
+		t.Errorf("status line not terminated before the failure line:\n%q", got)
+	}
+}
@@ -0,0 +1,43 @@
+package poller
+
+import "strings"
+
+// remediations maps the Azure error codes validate-move commonly returns to
+// a short suggestion of what to change before retrying. Keys are lower case.
+var remediations = map[string]string{
+	"missingmovedependentresources":            "Add the dependent resources named in the message to the move, or move them first.",
+	"resourcemovenotsupported":                 "This resource type cannot be moved; recreate it in the target resource group instead.",
+	"resourcenottoplevel":                      "Child resources move with their parent; move the parent resource instead.",
+	"missingsubscriptionregistration":          "Register the resource provider in the target subscription (az provider register --namespace <namespace>).",
+	"subscriptionnotregistered":                "Register the resource provider in the target subscription (az provider register --namespace <namespace>).",
+	"scopelocked":                              "Remove the lock on the source or target resource group (or resource) for the move, then restore it.",
+	"requestdisallowedbypolicy":                "An Azure Policy assignment blocks the move; review the policies on the target scope.",
+	"authorizationfailed":                      "Grant the credential moveResources/action on the source resource group and write access on the target.",
+	"linkedauthorizationfailed":                "Grant the credential write access on both the source and target resource groups.",
+	"resourcegroupnotfound":                    "Check the resource group name and subscription; create the target resource group first.",
+	"crosssubscriptionmovewithdifferenttenant": "Source and target subscriptions must be in the same Entra ID tenant; transfer the subscription first.",
+}
+
+// Remediation returns a short hint for the Azure error code, or "" when the
+// code is not one armv has advice for. Codes compare case-insensitively.
+func Remediation(code string) string {
+	return remediations[strings.ToLower(code)]
+}
+
+// Remediations returns the distinct hints for the report's top-level code
+// and per-resource errors, in the order the codes first appear.
+func Remediations(r ValidationReport) []string {
+	var hints []string
+	seen := make(map[string]bool)
+	add := func(code string) {
+		if hint := Remediation(code); hint != "" && !seen[hint] {
+			seen[hint] = true
+			hints = append(hints, hint)
+		}
+	}
+	add(r.TopLevel.Code)
+	for _, e := range r.Errors {
+		add(e.Code)
+	}
+	return hints
+}
@@ -9,11 +9,16 @@ import (
 
 // ReportContext holds the validation-run metadata used to populate the report header.
 type ReportContext struct {
-	SourceSubscriptionID string
-	SourceResourceGroup  string
-	TargetSubscriptionID string
-	TargetResourceGroup  string
-	ResourceCount        int
+	SourceSubscriptionID string `json:"source_subscription_id"`
+	SourceResourceGroup  string `json:"source_resource_group"`
+	TargetSubscriptionID string `json:"target_subscription_id"`
+	TargetResourceGroup  string `json:"target_resource_group"`
+	ResourceCount        int    `json:"resource_count"`
+
+	// PollCount and PollDuration describe the polling phase; both are zero
+	// when the run did not reach it.
+	PollCount    int           `json:"poll_count,omitempty"`
+	PollDuration time.Duration `json:"poll_duration_ns,omitempty"`
 }
 
 // AzureErrorResponse mirrors the shape of the JSON returned by the
@@ -94,6 +99,44 @@ func BuildValidationReport(statusCode int, statusText string, rawBody []byte, pr
 	return report
 }
 
+// RemediatedError is a failing resource together with the suggested fix for
+// its code, when one is known.
+type RemediatedError struct {
+	ValidationError
+	Remediation string
+}
+
+// ValidationSummary is the verdict the REST and MCP servers return for a
+// completed run: the parsed report, its errors with remediation hints, and
+// the errors counted by code and by resource type.
+type ValidationSummary struct {
+	Report         ValidationReport
+	Errors         []RemediatedError
+	ByCode         map[string]int // nil when there are no errors
+	ByResourceType map[string]int // nil when there are no errors
+}
+
+// BuildValidationSummary parses the recorded response into a
+// ValidationSummary.
+func BuildValidationSummary(rec RunRecord) ValidationSummary {
+	summary := ValidationSummary{
+		Report: BuildValidationReport(rec.StatusCode, rec.Status, []byte(rec.Body), "", rec.Context),
+	}
+	if len(summary.Report.Errors) == 0 {
+		return summary
+	}
+
+	summary.Errors = make([]RemediatedError, len(summary.Report.Errors))
+	summary.ByCode = make(map[string]int)
+	summary.ByResourceType = make(map[string]int)
+	for i, e := range summary.Report.Errors {
+		summary.Errors[i] = RemediatedError{ValidationError: e, Remediation: Remediation(e.Code)}
+		summary.ByCode[e.Code]++
+		summary.ByResourceType[e.ResourceType]++
+	}
+	return summary
+}
+
 // ParseResourceID extracts the provider/type and name from an Azure resource ID like
 // /subscriptions/<sub>/resourceGroups/<rg>/providers/<ns>/<type>/<name>.
 // If the shape is not recognised, both return values fall back to the original target.
@@ -130,6 +173,9 @@ func RenderMarkdown(r ValidationReport) string {
 	fmt.Fprintf(&b, "- **Target:** `%s` / `%s`\n", r.Context.TargetSubscriptionID, r.Context.TargetResourceGroup)
 	fmt.Fprintf(&b, "- **Resources validated:** %d\n", r.Context.ResourceCount)
 	fmt.Fprintf(&b, "- **HTTP status:** %d %s\n", r.StatusCode, r.StatusText)
+	if r.Context.PollCount > 0 {
+		fmt.Fprintf(&b, "- **Polling:** %d %s over %s\n", r.Context.PollCount, pluralise("poll", r.Context.PollCount), r.Context.PollDuration.Round(time.Second))
+	}
 	if !r.Success && r.TopLevel.Code != "" {
 		fmt.Fprintf(&b, "- **Top-level code:** `%s`\n", r.TopLevel.Code)
 	}
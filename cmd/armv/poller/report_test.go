@@ -239,3 +239,80 @@ func TestParseResourceID_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestRemediations(t *testing.T) {
+	t.Parallel()
+
+	raw := []byte(`{
+		"error": {
+			"code": "ResourceMoveProviderValidationFailed",
+			"message": "Resource move validation failed.",
+			"details": [
+				{"code": "MissingMoveDependentResources", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/a"},
+				{"code": "ResourceMoveNotSupported", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Foo/bars/b"},
+				{"code": "missingmovedependentresources", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/c"},
+				{"code": "SomethingUnknown", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Foo/bars/d"}
+			]
+		}
+	}`)
+	report := BuildValidationReport(409, "Conflict", raw, "", ReportContext{})
+
+	got := Remediations(report)
+	want := []string{Remediation("MissingMoveDependentResources"), Remediation("ResourceMoveNotSupported")}
+	if len(got) != len(want) {
+		t.Fatalf("Remediations() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if want[i] == "" || got[i] != want[i] {
+			t.Errorf("Remediations()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if hint := Remediation("SomethingUnknown"); hint != "" {
+		t.Errorf("Remediation(unknown) = %q, want empty", hint)
+	}
+}
+
+func TestBuildValidationSummary(t *testing.T) {
+	t.Parallel()
+
+	rec := RunRecord{
+		Context:    ReportContext{SourceResourceGroup: "rg", ResourceCount: 3},
+		StatusCode: 409,
+		Status:     "409 Conflict",
+		Body: `{
+		"error": {
+			"code": "ResourceMoveProviderValidationFailed",
+			"details": [
+				{"code": "ResourceMoveNotSupported", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Foo/bars/a"},
+				{"code": "ResourceMoveNotSupported", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/b"},
+				{"code": "SomethingUnknown", "target": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/c"}
+			]
+		}
+	}`,
+	}
+	summary := BuildValidationSummary(rec)
+
+	if summary.Report.TopLevel.Code != "ResourceMoveProviderValidationFailed" || summary.Report.Context != rec.Context {
+		t.Errorf("Report = %+v", summary.Report)
+	}
+	if len(summary.Errors) != 3 {
+		t.Fatalf("Errors = %+v, want 3", summary.Errors)
+	}
+	if e := summary.Errors[0]; e.ResourceName != "a" || e.Remediation != Remediation("ResourceMoveNotSupported") || e.Remediation == "" {
+		t.Errorf("Errors[0] = %+v", e)
+	}
+	if e := summary.Errors[2]; e.Remediation != "" {
+		t.Errorf("unknown code got remediation %q", e.Remediation)
+	}
+	if summary.ByCode["ResourceMoveNotSupported"] != 2 || summary.ByCode["SomethingUnknown"] != 1 {
+		t.Errorf("ByCode = %v", summary.ByCode)
+	}
+	if summary.ByResourceType["Microsoft.Web/sites"] != 2 || summary.ByResourceType["Microsoft.Foo/bars"] != 1 {
+		t.Errorf("ByResourceType = %v", summary.ByResourceType)
+	}
+
+	ok := BuildValidationSummary(RunRecord{StatusCode: StatusMoveOK, Status: "204 No Content"})
+	if !ok.Report.Success || ok.Errors != nil || ok.ByCode != nil || ok.ByResourceType != nil {
+		t.Errorf("summary of a successful run = %+v", ok)
+	}
+}
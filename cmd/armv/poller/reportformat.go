@@ -0,0 +1,68 @@
+package poller
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReportFormat names an output format a ValidationReport can be rendered to.
+type ReportFormat string
+
+const (
+	FormatMarkdown ReportFormat = "md"
+	FormatHTML     ReportFormat = "html"
+	FormatSARIF    ReportFormat = "sarif"
+	FormatJUnit    ReportFormat = "junit"
+)
+
+// ReportFormats lists every supported format, in the order shown in help text.
+var ReportFormats = []ReportFormat{FormatMarkdown, FormatHTML, FormatSARIF, FormatJUnit}
+
+// ParseReportFormat resolves a user-supplied format name. "markdown" is
+// accepted as an alias for "md".
+func ParseReportFormat(s string) (ReportFormat, error) {
+	switch f := ReportFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatMarkdown, "markdown":
+		return FormatMarkdown, nil
+	case FormatHTML, FormatSARIF, FormatJUnit:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported report format %q: must be one of %s", s, joinFormats())
+	}
+}
+
+// Extension returns the conventional file extension for the format.
+func (f ReportFormat) Extension() string {
+	switch f {
+	case FormatSARIF:
+		return ".sarif"
+	case FormatJUnit:
+		return ".xml"
+	default:
+		return "." + string(f)
+	}
+}
+
+// Render renders r in the requested format.
+func Render(r ValidationReport, format ReportFormat) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		return RenderMarkdown(r), nil
+	case FormatHTML:
+		return RenderHTML(r)
+	case FormatSARIF:
+		return RenderSARIF(r)
+	case FormatJUnit:
+		return RenderJUnit(r)
+	default:
+		return "", fmt.Errorf("unsupported report format %q: must be one of %s", format, joinFormats())
+	}
+}
+
+func joinFormats() string {
+	names := make([]string, len(ReportFormats))
+	for i, f := range ReportFormats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
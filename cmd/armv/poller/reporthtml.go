@@ -0,0 +1,96 @@
+package poller
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// htmlReportTemplate mirrors the section layout of RenderMarkdown so the two
+// formats read the same. html/template handles all escaping.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc":       func(i int) int { return i + 1 },
+	"pluralise": pluralise,
+	"roundSeconds": func(d time.Duration) time.Duration {
+		return d.Round(time.Second)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Azure Resource Move Validation Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.4rem 0.75rem; text-align: left; }
+th { background: #f6f8fa; }
+code, pre { font-family: ui-monospace, Consolas, monospace; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; }
+.success { color: #1a7f37; }
+.failed { color: #cf222e; }
+blockquote { border-left: 4px solid #d0d7de; margin: 0 0 1rem; padding: 0 1rem; color: #57606a; }
+</style>
+</head>
+<body>
+<h1>Azure Resource Move Validation Report</h1>
+<ul>
+<li><strong>Generated:</strong> {{.GeneratedAt.Format "2006-01-02 15:04:05 UTC"}}</li>
+{{- if .Success}}
+<li><strong>Status:</strong> <span class="success">SUCCESS</span></li>
+{{- else}}
+<li><strong>Status:</strong> <span class="failed">FAILED ({{len .Errors}} {{pluralise "error" (len .Errors)}})</span></li>
+{{- end}}
+<li><strong>Source:</strong> <code>{{.Context.SourceSubscriptionID}}</code> / <code>{{.Context.SourceResourceGroup}}</code></li>
+<li><strong>Target:</strong> <code>{{.Context.TargetSubscriptionID}}</code> / <code>{{.Context.TargetResourceGroup}}</code></li>
+<li><strong>Resources validated:</strong> {{.Context.ResourceCount}}</li>
+<li><strong>HTTP status:</strong> {{.StatusCode}} {{.StatusText}}</li>
+{{- if .Context.PollCount}}
+<li><strong>Polling:</strong> {{.Context.PollCount}} {{pluralise "poll" .Context.PollCount}} over {{roundSeconds .Context.PollDuration}}</li>
+{{- end}}
+{{- if and (not .Success) .TopLevel.Code}}
+<li><strong>Top-level code:</strong> <code>{{.TopLevel.Code}}</code></li>
+{{- end}}
+</ul>
+{{- if .Success}}
+<p>No validation issues found. All resources are eligible to move.</p>
+{{- else}}
+{{- if .TopLevel.Message}}
+<blockquote>{{.TopLevel.Message}}</blockquote>
+{{- end}}
+{{- if .Errors}}
+<h2>Summary</h2>
+<table>
+<tr><th>#</th><th>Resource Type</th><th>Name</th><th>Code</th></tr>
+{{- range $i, $e := .Errors}}
+<tr><td>{{inc $i}}</td><td>{{$e.ResourceType}}</td><td>{{$e.ResourceName}}</td><td>{{$e.Code}}</td></tr>
+{{- end}}
+</table>
+<h2>Details</h2>
+{{- range $i, $e := .Errors}}
+<h3>{{inc $i}}. {{$e.ResourceName}}</h3>
+<ul>
+<li><strong>Type:</strong> <code>{{$e.ResourceType}}</code></li>
+<li><strong>Resource ID:</strong> <code>{{$e.ResourceID}}</code></li>
+<li><strong>Code:</strong> <code>{{$e.Code}}</code></li>
+<li><strong>Message:</strong> {{$e.Message}}</li>
+</ul>
+{{- end}}
+{{- end}}
+{{- if .RawJSON}}
+<h2>Raw Azure API Response</h2>
+<pre><code>{{.RawJSON}}</code></pre>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML produces a self-contained HTML page for the report.
+func RenderHTML(r ValidationReport) (string, error) {
+	var b strings.Builder
+	if err := htmlReportTemplate.Execute(&b, r); err != nil {
+		return "", fmt.Errorf("render html report: %w", err)
+	}
+	return b.String(), nil
+}
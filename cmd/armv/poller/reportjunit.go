@@ -0,0 +1,108 @@
+package poller
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// The junit* types follow the de-facto JUnit XML schema understood by CI
+// systems (GitHub Actions, Azure DevOps, GitLab, Jenkins).
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// RenderJUnit produces a JUnit XML document for the report. The suite always
+// contains one "validate-move" case for the overall outcome, followed by one
+// failing case per resource Azure reported, so CI dashboards show both the
+// verdict and the individual blockers.
+func RenderJUnit(r ValidationReport) (string, error) {
+	suiteName := fmt.Sprintf("%s -> %s", r.Context.SourceResourceGroup, r.Context.TargetResourceGroup)
+
+	overall := junitTestCase{Name: "validate-move", ClassName: "armv"}
+	if !r.Success {
+		message := r.TopLevel.Message
+		if message == "" {
+			message = fmt.Sprintf("Azure validate-move returned HTTP %d %s", r.StatusCode, r.StatusText)
+		}
+		code := r.TopLevel.Code
+		if code == "" {
+			code = fallbackRuleID
+		}
+		overall.Failure = &junitFailure{Message: message, Type: code, Text: r.RawJSON}
+	}
+
+	suite := junitTestSuite{
+		Name:      suiteName,
+		Time:      fmt.Sprintf("%.3f", r.Context.PollDuration.Seconds()),
+		Timestamp: r.GeneratedAt.UTC().Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "source_subscription_id", Value: r.Context.SourceSubscriptionID},
+			{Name: "source_resource_group", Value: r.Context.SourceResourceGroup},
+			{Name: "target_subscription_id", Value: r.Context.TargetSubscriptionID},
+			{Name: "target_resource_group", Value: r.Context.TargetResourceGroup},
+			{Name: "resource_count", Value: fmt.Sprint(r.Context.ResourceCount)},
+			{Name: "http_status", Value: fmt.Sprintf("%d %s", r.StatusCode, r.StatusText)},
+			{Name: "poll_count", Value: fmt.Sprint(r.Context.PollCount)},
+		},
+		Cases: []junitTestCase{overall},
+	}
+
+	for _, e := range r.Errors {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      e.ResourceName,
+			ClassName: e.ResourceType,
+			Failure:   &junitFailure{Message: e.Message, Type: e.Code, Text: e.ResourceID},
+		})
+	}
+
+	for _, c := range suite.Cases {
+		suite.Tests++
+		if c.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	doc := junitTestSuites{
+		Name:     "armv",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("render junit report: %w", err)
+	}
+	return xml.Header + string(data) + "\n", nil
+}
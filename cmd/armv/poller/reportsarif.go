@@ -0,0 +1,139 @@
+package poller
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolInfoURI  = "https://github.com/AaronSaikovski/armv"
+
+	// fallbackRuleID is used when Azure fails the validation without
+	// returning a parseable error code.
+	fallbackRuleID = "ResourceMoveValidationFailed"
+)
+
+// The sarif* types cover the subset of SARIF 2.1.0 that code-scanning
+// dashboards need: one run, a rule per Azure error code, and one result per
+// failing resource addressed by a logical location (its ARM ID).
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool      `json:"tool"`
+	Results    []sarifResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// RenderSARIF produces a SARIF 2.1.0 log for the report. A successful
+// validation yields a run with no results.
+func RenderSARIF(r ValidationReport) (string, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "armv",
+			InformationURI: toolInfoURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+		Properties: map[string]any{
+			"sourceSubscriptionId": r.Context.SourceSubscriptionID,
+			"sourceResourceGroup":  r.Context.SourceResourceGroup,
+			"targetSubscriptionId": r.Context.TargetSubscriptionID,
+			"targetResourceGroup":  r.Context.TargetResourceGroup,
+			"resourceCount":        r.Context.ResourceCount,
+			"httpStatusCode":       r.StatusCode,
+			"pollCount":            r.Context.PollCount,
+			"pollDurationSeconds":  r.Context.PollDuration.Seconds(),
+		},
+	}
+
+	seen := make(map[string]bool)
+	addRule := func(id, description string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+
+	if !r.Success {
+		for _, e := range r.Errors {
+			ruleID := e.Code
+			if ruleID == "" {
+				ruleID = fallbackRuleID
+			}
+			addRule(ruleID, e.Message)
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  ruleID,
+				Level:   "error",
+				Message: sarifMessage{Text: e.Message},
+				Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+					Name:               e.ResourceName,
+					FullyQualifiedName: e.ResourceID,
+					Kind:               "resource",
+				}}}},
+			})
+		}
+
+		// A failure with no per-resource details still needs to surface.
+		if len(r.Errors) == 0 {
+			ruleID := r.TopLevel.Code
+			if ruleID == "" {
+				ruleID = fallbackRuleID
+			}
+			message := r.TopLevel.Message
+			if message == "" {
+				message = fmt.Sprintf("Azure validate-move returned HTTP %d %s", r.StatusCode, r.StatusText)
+			}
+			addRule(ruleID, message)
+			run.Results = append(run.Results, sarifResult{RuleID: ruleID, Level: "error", Message: sarifMessage{Text: message}})
+		}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("render sarif report: %w", err)
+	}
+	return string(data) + "\n", nil
+}
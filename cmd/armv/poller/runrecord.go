@@ -0,0 +1,86 @@
+package poller
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+// RunRecord is the raw, format-independent record of one validation run. It is
+// written alongside every Markdown report so any output format can be rebuilt
+// offline with BuildValidationReport, without calling Azure again.
+type RunRecord struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Context     ReportContext `json:"context"`
+	StatusCode  int           `json:"status_code"`
+	Status      string        `json:"status"`
+	Body        string        `json:"body,omitempty"`
+
+	// Diagnostics is absent from records written before support bundles
+	// existed and from records built outside the CLI.
+	Diagnostics *RunDiagnostics `json:"diagnostics,omitempty"`
+}
+
+// RunDiagnostics is what a support escalation needs beyond the report: the
+// request that was sent and how to find this run in Azure's own logs.
+type RunDiagnostics struct {
+	ToolVersion           string    `json:"tool_version,omitempty"`
+	CorrelationID         string    `json:"correlation_id,omitempty"`
+	StartedAt             time.Time `json:"started_at"`
+	TargetResourceGroupID string    `json:"target_resource_group_id,omitempty"`
+	ResourceIDs           []string  `json:"resource_ids,omitempty"`
+
+	// TraceFile is the run's redacted HTTP trace, relative to the record.
+	TraceFile string `json:"trace_file,omitempty"`
+}
+
+// NewRunRecord captures a completed poll response together with its report context.
+func NewRunRecord(pollResp PollerResponseData, ctx ReportContext, generatedAt time.Time) RunRecord {
+	return RunRecord{
+		GeneratedAt: generatedAt.UTC(),
+		Context:     ctx,
+		StatusCode:  pollResp.RespStatusCode,
+		Status:      pollResp.RespStatus,
+		Body:        string(pollResp.RespBody),
+	}
+}
+
+// Report rebuilds the ValidationReport for the recorded run. GeneratedAt is
+// taken from the record rather than the clock so re-renders are reproducible.
+func (rec RunRecord) Report() ValidationReport {
+	body := []byte(rec.Body)
+	report := BuildValidationReport(rec.StatusCode, rec.Status, body, prettyBody(rec.StatusCode, body), rec.Context)
+	if !rec.GeneratedAt.IsZero() {
+		report.GeneratedAt = rec.GeneratedAt.UTC()
+	}
+	return report
+}
+
+// WriteRunRecord writes rec as indented JSON to outputPath/fileName.
+func WriteRunRecord(outputPath, fileName string, rec RunRecord) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run record: %w", err)
+	}
+	return utils.WriteOutputFile(outputPath, fileName, string(data)+"\n")
+}
+
+// LoadRunRecord reads a run record previously written by WriteRunRecord.
+func LoadRunRecord(path string) (RunRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RunRecord{}, fmt.Errorf("read run record: %w", err)
+	}
+
+	var rec RunRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return RunRecord{}, fmt.Errorf("parse run record %s: %w", path, err)
+	}
+	if rec.StatusCode == 0 {
+		return RunRecord{}, fmt.Errorf("parse run record %s: missing status_code", path)
+	}
+	return rec, nil
+}
@@ -5,7 +5,9 @@ package auth
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
@@ -20,7 +22,9 @@ func CheckLogin(ctx context.Context, cred azcore.TokenCredential, subscriptionID
 		return false, fmt.Errorf("auth: creating subscription client: %w", err)
 	}
 
+	slog.DebugContext(ctx, "auth: checking subscription access", "subscription_id", subscriptionID)
 	if _, err := client.Get(ctx, subscriptionID, nil); err != nil {
+		slog.DebugContext(ctx, "auth: subscription access check failed", "subscription_id", subscriptionID, "error", err)
 		return false, fmt.Errorf("auth: subscription %q get: %w", subscriptionID, err)
 	}
 
@@ -35,6 +39,7 @@ func GetAzureDefaultCredential() (*azidentity.DefaultAzureCredential, error) {
 	if err != nil {
 		return nil, fmt.Errorf("auth: default credential: %w", err)
 	}
+	slog.Debug("auth: using DefaultAzureCredential")
 	return cred, nil
 }
 
@@ -46,12 +51,13 @@ func NewClientSecretCredential(tenantID, clientID, clientSecret string) (*aziden
 	if err != nil {
 		return nil, fmt.Errorf("auth: client secret credential: %w", err)
 	}
+	slog.Debug("auth: using service principal credential", "tenant_id", tenantID, "client_id", clientID)
 	return cred, nil
 }
 
 // NewResourceClient creates an armresources.Client for the given subscription.
 func NewResourceClient(subscriptionID string, cred azcore.TokenCredential) (*armresources.Client, error) {
-	clientFactory, err := armresources.NewClientFactory(subscriptionID, cred, nil)
+	clientFactory, err := armresources.NewClientFactory(subscriptionID, cred, pipeline.ClientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("auth: new resources client factory: %w", err)
 	}
@@ -60,7 +66,7 @@ func NewResourceClient(subscriptionID string, cred azcore.TokenCredential) (*arm
 
 // SubscriptionClientCred creates an armsubscription.SubscriptionsClient.
 func SubscriptionClientCred(cred azcore.TokenCredential) (*armsubscription.SubscriptionsClient, error) {
-	client, err := armsubscription.NewSubscriptionsClient(cred, nil)
+	client, err := armsubscription.NewSubscriptionsClient(cred, pipeline.ClientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("auth: new subscriptions client: %w", err)
 	}
@@ -85,5 +91,6 @@ func ListSubscriptions(ctx context.Context, cred azcore.TokenCredential) ([]*arm
 		}
 		subs = append(subs, page.Value...)
 	}
+	slog.DebugContext(ctx, "auth: listed subscriptions", "count", len(subs))
 	return subs, nil
 }
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Mode selects how the CLI obtains an Azure credential.
+type Mode string
+
+const (
+	// ModeDefault walks the DefaultAzureCredential chain.
+	ModeDefault Mode = "default"
+	// ModeAzureCLI uses the `az login` session only.
+	ModeAzureCLI Mode = "azure-cli"
+	// ModeManagedIdentity uses the host's managed identity; ClientID selects
+	// a user-assigned identity.
+	ModeManagedIdentity Mode = "managed-identity"
+	// ModeClientSecret uses a service principal. The secret is never read
+	// from flags or config files, only from AZURE_CLIENT_SECRET.
+	ModeClientSecret Mode = "client-secret"
+)
+
+// Modes lists every supported Mode, in the order shown in help text.
+var Modes = []Mode{ModeDefault, ModeAzureCLI, ModeManagedIdentity, ModeClientSecret}
+
+// ParseMode resolves a user-supplied auth mode; empty means ModeDefault.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return ModeDefault, nil
+	case ModeDefault, ModeAzureCLI, ModeManagedIdentity, ModeClientSecret:
+		return m, nil
+	default:
+		names := make([]string, len(Modes))
+		for i, m := range Modes {
+			names[i] = string(m)
+		}
+		return "", fmt.Errorf("auth: unsupported auth mode %q: must be one of %s", s, strings.Join(names, ", "))
+	}
+}
+
+// CredentialOptions configures NewCredential.
+type CredentialOptions struct {
+	Mode         Mode
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+}
+
+// NewCredential builds the credential for opts.Mode.
+func NewCredential(opts CredentialOptions) (azcore.TokenCredential, error) {
+	switch opts.Mode {
+	case ModeDefault, "":
+		return GetAzureDefaultCredential()
+	case ModeAzureCLI:
+		cred, err := azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: opts.TenantID})
+		if err != nil {
+			return nil, fmt.Errorf("auth: azure cli credential: %w", err)
+		}
+		slog.Debug("auth: using AzureCLICredential", "tenant_id", opts.TenantID)
+		return cred, nil
+	case ModeManagedIdentity:
+		miOpts := &azidentity.ManagedIdentityCredentialOptions{}
+		if opts.ClientID != "" {
+			miOpts.ID = azidentity.ClientID(opts.ClientID)
+		}
+		cred, err := azidentity.NewManagedIdentityCredential(miOpts)
+		if err != nil {
+			return nil, fmt.Errorf("auth: managed identity credential: %w", err)
+		}
+		slog.Debug("auth: using ManagedIdentityCredential", "client_id", opts.ClientID)
+		return cred, nil
+	case ModeClientSecret:
+		if opts.TenantID == "" || opts.ClientID == "" || opts.ClientSecret == "" {
+			return nil, errors.New("auth: client-secret mode needs a tenant ID, a client ID and AZURE_CLIENT_SECRET")
+		}
+		return NewClientSecretCredential(opts.TenantID, opts.ClientID, opts.ClientSecret)
+	default:
+		return nil, fmt.Errorf("auth: unsupported auth mode %q", opts.Mode)
+	}
+}
@@ -0,0 +1,116 @@
+// Package completion caches the Azure lookups behind dynamic shell
+// completion. Every Tab press runs a fresh armv process, so without a cache
+// each one would pay for a token and an ARM round trip; results are kept as
+// small JSON files in the user cache directory for a short TTL instead.
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+// DefaultTTL is how long cached completions are used before Azure is asked
+// again.
+const DefaultTTL = 5 * time.Minute
+
+// TTLEnv overrides DefaultTTL with a Go duration; "0" disables the cache.
+const TTLEnv = "ARMV_COMPLETION_TTL"
+
+// Candidate is one completion: the value inserted on the command line and a
+// description the shell may show beside it.
+type Candidate struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type entry struct {
+	Key        string      `json:"key"`
+	FetchedAt  time.Time   `json:"fetched_at"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// Cache stores candidate lists by key, one file per key under Dir.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+}
+
+// DefaultCache returns a cache in <user cache dir>/armv/completion with the
+// TTL from TTLEnv, else DefaultTTL.
+func DefaultCache() (*Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("completion: locate user cache directory: %w", err)
+	}
+	ttl := DefaultTTL
+	if v := strings.TrimSpace(os.Getenv(TTLEnv)); v != "" {
+		if ttl, err = time.ParseDuration(v); err != nil || ttl < 0 {
+			return nil, fmt.Errorf("completion: invalid %s %q", TTLEnv, v)
+		}
+	}
+	return &Cache{Dir: filepath.Join(dir, "armv", "completion"), TTL: ttl}, nil
+}
+
+// Get returns the candidates cached under key if they are younger than the
+// TTL, and otherwise calls fetch and caches its result. If fetch fails but
+// an expired entry exists, the expired candidates are returned: stale
+// names complete better than none. Failing to write the cache is ignored.
+func (c *Cache) Get(key string, fetch func() ([]Candidate, error)) ([]Candidate, error) {
+	cached, found := c.load(key)
+	if found && c.TTL > 0 && c.now().Sub(cached.FetchedAt) < c.TTL {
+		return cached.Candidates, nil
+	}
+
+	candidates, err := fetch()
+	if err != nil {
+		if found {
+			return cached.Candidates, nil
+		}
+		return nil, err
+	}
+	if c.TTL > 0 {
+		c.store(entry{Key: key, FetchedAt: c.now().UTC(), Candidates: candidates})
+	}
+	return candidates, nil
+}
+
+func (c *Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// fileName hashes key so that subscription IDs and tenant names never need
+// escaping and different credentials never share an entry by accident.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:12]) + ".json"
+}
+
+func (c *Cache) load(key string) (entry, bool) {
+	var e entry
+	data, err := os.ReadFile(filepath.Join(c.Dir, fileName(key)))
+	if err != nil || json.Unmarshal(data, &e) != nil || e.Key != key {
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (c *Cache) store(e entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = utils.WriteOutputFile(c.Dir, fileName(e.Key), string(data))
+}
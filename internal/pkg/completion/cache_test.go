@@ -0,0 +1,53 @@
+package completion
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCacheGet(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	c := &Cache{Dir: t.TempDir(), TTL: time.Minute, Now: func() time.Time { return now }}
+
+	calls := 0
+	fetch := func() ([]Candidate, error) {
+		calls++
+		return []Candidate{{Value: "rg-a", Description: "australiaeast"}}, nil
+	}
+	failing := func() ([]Candidate, error) {
+		calls++
+		return nil, errors.New("offline")
+	}
+
+	if got, err := c.Get("groups/sub", fetch); err != nil || len(got) != 1 || calls != 1 {
+		t.Fatalf("first Get() = (%v, %v), calls %d", got, err, calls)
+	}
+	if _, err := c.Get("groups/sub", fetch); err != nil || calls != 1 {
+		t.Errorf("fresh entry refetched: calls %d, err %v", calls, err)
+	}
+
+	// Once expired, a failing fetch falls back to the stale entry.
+	now = now.Add(2 * time.Minute)
+	if got, err := c.Get("groups/sub", failing); err != nil || calls != 2 || len(got) != 1 {
+		t.Errorf("stale fallback = (%v, %v), calls %d", got, err, calls)
+	}
+	if _, err := c.Get("groups/other", failing); err == nil {
+		t.Error("Get() without a cached entry hid the fetch error")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	t.Parallel()
+
+	c := &Cache{Dir: t.TempDir()}
+	calls := 0
+	fetch := func() ([]Candidate, error) { calls++; return nil, nil }
+	_, _ = c.Get("k", fetch)
+	_, _ = c.Get("k", fetch)
+	if calls != 2 {
+		t.Errorf("TTL 0 fetched %d times, want every time", calls)
+	}
+}
@@ -0,0 +1,222 @@
+// Package config reads the armv config file: a YAML document of named
+// profiles, each holding the settings for one recurring validation (the four
+// IDs, auth mode, report formats, poll settings). The CLI layers profiles
+// under ARMV_* environment variables and flags; this package only parses
+// the file and exposes each profile as flag-name/value pairs.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to a flag name (upper-cased, dashes to
+// underscores) to form its environment variable, e.g. ARMV_POLL_TIMEOUT.
+const EnvPrefix = "ARMV_"
+
+// File is the parsed config file.
+type File struct {
+	// DefaultProfile is used when no profile is selected explicitly.
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is one named set of settings. Every field is optional; unset
+// fields fall through to the flag default.
+type Profile struct {
+	SourceSubscriptionID string        `yaml:"source_subscription_id,omitempty"`
+	SourceResourceGroup  string        `yaml:"source_resource_group,omitempty"`
+	TargetSubscriptionID string        `yaml:"target_subscription_id,omitempty"`
+	TargetResourceGroup  string        `yaml:"target_resource_group,omitempty"`
+	ResourceIDs          []string      `yaml:"resource_ids,omitempty"`
+	Auth                 Auth          `yaml:"auth,omitempty"`
+	OutputPath           string        `yaml:"output_path,omitempty"`
+	ReportFormats        []string      `yaml:"report_formats,omitempty"`
+	PollInterval         time.Duration `yaml:"poll_interval,omitempty"`
+	PollTimeout          time.Duration `yaml:"poll_timeout,omitempty"`
+	LogLevel             string        `yaml:"log_level,omitempty"`
+	LogFormat            string        `yaml:"log_format,omitempty"`
+}
+
+// Auth selects the credential. There is deliberately no secret field: a
+// client secret is only ever read from AZURE_CLIENT_SECRET.
+type Auth struct {
+	Mode     string `yaml:"mode,omitempty"`
+	TenantID string `yaml:"tenant_id,omitempty"`
+	ClientID string `yaml:"client_id,omitempty"`
+}
+
+// SettingFlags names the run settings, in the order Settings sets them:
+// the root command's flags a profile can hold. They are also the flags
+// bound to ARMV_* variables on every command.
+var SettingFlags = []string{
+	"source-subscription-id",
+	"source-resource-group",
+	"target-subscription-id",
+	"target-resource-group",
+	"resource-ids",
+	"auth-mode",
+	"tenant-id",
+	"client-id",
+	"output-path",
+	"report-formats",
+	"poll-interval",
+	"poll-timeout",
+	"log-level",
+	"log-format",
+}
+
+// Settings returns the profile's non-empty values keyed by the CLI flag
+// they set.
+func (p Profile) Settings() map[string]string {
+	out := map[string]string{}
+	set := func(flag, value string) {
+		if value != "" {
+			out[flag] = value
+		}
+	}
+	set("source-subscription-id", p.SourceSubscriptionID)
+	set("source-resource-group", p.SourceResourceGroup)
+	set("target-subscription-id", p.TargetSubscriptionID)
+	set("target-resource-group", p.TargetResourceGroup)
+	set("resource-ids", strings.Join(p.ResourceIDs, ","))
+	set("auth-mode", p.Auth.Mode)
+	set("tenant-id", p.Auth.TenantID)
+	set("client-id", p.Auth.ClientID)
+	set("output-path", p.OutputPath)
+	set("report-formats", strings.Join(p.ReportFormats, ","))
+	if p.PollInterval != 0 {
+		set("poll-interval", p.PollInterval.String())
+	}
+	if p.PollTimeout != 0 {
+		set("poll-timeout", p.PollTimeout.String())
+	}
+	set("log-level", p.LogLevel)
+	set("log-format", p.LogFormat)
+	return out
+}
+
+// EnvName returns the environment variable bound to flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/armv/config.yaml, falling back to
+// ~/.config/armv/config.yaml on every platform so the documented location
+// is the same everywhere.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "armv", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("config: locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "armv", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty File
+// when optional is true (the default location), and an error otherwise.
+// Unknown keys are rejected so a typo does not silently drop a setting.
+func Load(path string, optional bool) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("config: read %s: %w", path, err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	if f.DefaultProfile != "" {
+		if _, ok := f.Profiles[f.DefaultProfile]; !ok {
+			return nil, fmt.Errorf("config: %s: default_profile %q is not defined", path, f.DefaultProfile)
+		}
+	}
+	return &f, nil
+}
+
+// Profile returns the named profile, or the default profile when name is
+// empty, together with the name actually used. resolved is empty when no
+// profile applies (no name given and no default_profile).
+func (f *File) Profile(name string) (p Profile, resolved string, err error) {
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, "", nil
+	}
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, "", fmt.Errorf("config: profile %q not found (have: %s)", name, strings.Join(f.ProfileNames(), ", "))
+	}
+	return p, name, nil
+}
+
+// ProfileNames returns the defined profile names, sorted.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SetProfile adds or replaces the named profile.
+func (f *File) SetProfile(name string, p Profile) {
+	if f.Profiles == nil {
+		f.Profiles = map[string]Profile{}
+	}
+	f.Profiles[name] = p
+}
+
+// Save writes f to path, creating the directory if needed. The file is
+// replaced atomically and is readable only by the owner. Comments in an
+// existing file are not preserved.
+func Save(path string, f *File) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("config: encode: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("config: create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProfileSettingsUseFlagNames(t *testing.T) {
+	t.Parallel()
+
+	p := Profile{
+		SourceSubscriptionID: "sub",
+		Auth:                 Auth{Mode: "client-secret", ClientID: "app"},
+		ReportFormats:        []string{"md", "html"},
+		PollTimeout:          45 * time.Minute,
+	}
+	want := map[string]string{
+		"source-subscription-id": "sub",
+		"auth-mode":              "client-secret",
+		"client-id":              "app",
+		"report-formats":         "md,html",
+		"poll-timeout":           "45m0s",
+	}
+	if got := p.Settings(); !maps.Equal(got, want) {
+		t.Errorf("Settings() = %v, want %v", got, want)
+	}
+}
+
+func TestSettingFlagsCoverEveryProfileField(t *testing.T) {
+	t.Parallel()
+
+	full := Profile{
+		SourceSubscriptionID: "a", SourceResourceGroup: "b", TargetSubscriptionID: "c", TargetResourceGroup: "d",
+		ResourceIDs: []string{"e"}, Auth: Auth{Mode: "f", TenantID: "g", ClientID: "h"}, OutputPath: "i",
+		ReportFormats: []string{"j"}, PollInterval: time.Second, PollTimeout: time.Minute, LogLevel: "k", LogFormat: "l",
+	}
+	got := slices.Sorted(maps.Keys(full.Settings()))
+	if want := slices.Sorted(slices.Values(SettingFlags)); !slices.Equal(got, want) {
+		t.Errorf("Settings() keys = %v, SettingFlags = %v", got, want)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	if got := EnvName("source-subscription-id"); got != "ARMV_SOURCE_SUBSCRIPTION_ID" {
+		t.Errorf("EnvName() = %q", got)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	f, err := Load(path, true)
+	if err != nil || len(f.Profiles) != 0 {
+		t.Errorf("Load(optional) = (%+v, %v), want empty file", f, err)
+	}
+	if _, err := Load(path, false); err == nil {
+		t.Error("Load(explicit) of a missing file succeeded")
+	}
+}
+
+func TestLoadDurationsAndDefaultProfile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "default_profile: a\nprofiles:\n  a:\n    poll_interval: 3s\n  b: {}\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path, false)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p, name, err := f.Profile("")
+	if err != nil || name != "a" || p.PollInterval != 3*time.Second {
+		t.Errorf("Profile(\"\") = (%+v, %q, %v)", p, name, err)
+	}
+	if _, _, err := f.Profile("c"); err == nil {
+		t.Error("Profile(\"c\") succeeded for an undefined profile")
+	}
+	if names := f.ProfileNames(); len(names) != 2 || names[0] != "a" {
+		t.Errorf("ProfileNames() = %v", names)
+	}
+}
+
+func TestDefaultPathHonoursXDG(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	got, err := DefaultPath()
+	if err != nil || got != filepath.Join("/xdg", "armv", "config.yaml") {
+		t.Errorf("DefaultPath() = (%q, %v)", got, err)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "armv", "config.yaml")
+	f := &File{}
+	f.SetProfile("wizard", Profile{
+		SourceSubscriptionID: "sub",
+		ResourceIDs:          []string{"/a", "/b"},
+		PollTimeout:          20 * time.Minute,
+	})
+	if err := Save(path, f); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Stat() = (%v, %v), want mode 0600", info, err)
+	}
+
+	got, err := Load(path, false)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p, _, err := got.Profile("wizard")
+	if err != nil || p.PollTimeout != 20*time.Minute || len(p.ResourceIDs) != 2 {
+		t.Errorf("Profile(wizard) = (%+v, %v)", p, err)
+	}
+	if s := p.Settings()["resource-ids"]; s != "/a,/b" {
+		t.Errorf("Settings()[resource-ids] = %q", s)
+	}
+}
@@ -0,0 +1,126 @@
+// Package discovery lists what a credential can see — subscriptions,
+// resource groups and resources — as flat, presentation-free records. It is
+// shared by `armv list` and the MCP discovery tools so both front ends
+// filter and sort the same way.
+//
+// The struct tags carry both the JSON field names and the jsonschema
+// descriptions the MCP server publishes for its tool outputs.
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/resourcegroups"
+	"github.com/AaronSaikovski/armv/internal/pkg/resources"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// Subscription is one subscription visible to the credential.
+type Subscription struct {
+	SubscriptionID string `json:"subscription_id"          jsonschema:"Azure subscription UUID — use this value as source_subscription_id / target_subscription_id in validate_move"`
+	DisplayName    string `json:"display_name,omitempty"   jsonschema:"human-readable subscription name"`
+	State          string `json:"state,omitempty"          jsonschema:"subscription state (Enabled, Disabled, Warned, etc.)"`
+	ID             string `json:"id,omitempty"             jsonschema:"fully qualified ARM ID (/subscriptions/{sub})"`
+}
+
+// ResourceGroup is one resource group in a subscription.
+type ResourceGroup struct {
+	Name     string `json:"name"               jsonschema:"resource group name — use as source_resource_group / target_resource_group in validate_move"`
+	ID       string `json:"id"                 jsonschema:"fully qualified ARM ID (/subscriptions/{sub}/resourceGroups/{rg})"`
+	Location string `json:"location,omitempty" jsonschema:"Azure region (e.g. australiaeast, eastus)"`
+	// Tags are kept out of the JSON form; select them by projection.
+	Tags map[string]string `json:"-"`
+}
+
+// Resource is one resource in a resource group.
+type Resource struct {
+	Name     string `json:"name"               jsonschema:"resource name"`
+	Type     string `json:"type,omitempty"     jsonschema:"ARM resource type (e.g. Microsoft.Storage/storageAccounts)"`
+	ID       string `json:"id"                 jsonschema:"fully qualified ARM resource ID — valid input for validate_move"`
+	Location string `json:"location,omitempty" jsonschema:"Azure region"`
+	// Tags are kept out of the JSON form; select them by projection.
+	Tags map[string]string `json:"-"`
+}
+
+// ListSubscriptions returns every subscription cred can enumerate.
+func ListSubscriptions(ctx context.Context, cred azcore.TokenCredential) ([]Subscription, error) {
+	subs, err := auth.ListSubscriptions(ctx, cred)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: list subscriptions: %w", err)
+	}
+
+	out := make([]Subscription, 0, len(subs))
+	for _, s := range subs {
+		if s == nil {
+			continue
+		}
+		info := Subscription{SubscriptionID: deref(s.SubscriptionID), DisplayName: deref(s.DisplayName), ID: deref(s.ID)}
+		if s.State != nil {
+			info.State = string(*s.State)
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// ListResourceGroups returns every resource group in subscriptionID.
+func ListResourceGroups(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) ([]ResourceGroup, error) {
+	client, err := resourcegroups.GetResourceGroupClient(cred, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: resource group client: %w", err)
+	}
+	rgs, err := resourcegroups.ListResourceGroup(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: list resource groups: %w", err)
+	}
+
+	out := make([]ResourceGroup, 0, len(rgs))
+	for _, rg := range rgs {
+		if rg == nil {
+			continue
+		}
+		out = append(out, ResourceGroup{Name: deref(rg.Name), ID: deref(rg.ID), Location: deref(rg.Location), Tags: tags(rg.Tags)})
+	}
+	return out, nil
+}
+
+// ListResources returns every resource in resourceGroup.
+func ListResources(ctx context.Context, cred azcore.TokenCredential, subscriptionID, resourceGroup string) ([]Resource, error) {
+	client, err := resources.GetResourcesClient(cred, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: resources client: %w", err)
+	}
+	items, err := resources.GetResources(ctx, client, resourceGroup)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: list resources: %w", err)
+	}
+
+	out := make([]Resource, 0, len(items))
+	for _, r := range items {
+		if r == nil {
+			continue
+		}
+		out = append(out, Resource{Name: deref(r.Name), Type: deref(r.Type), ID: deref(r.ID), Location: deref(r.Location), Tags: tags(r.Tags)})
+	}
+	return out, nil
+}
+
+func tags(in map[string]*string) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = deref(v)
+	}
+	return out
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,144 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxPageSize caps one page of a paginated listing.
+	MaxPageSize = 1000
+	// DefaultPageSize applies when a cursor is passed without a page size.
+	DefaultPageSize = 100
+)
+
+// ErrInvalidCursor is returned, wrapped, for a cursor that is malformed or
+// belongs to a different listing.
+var ErrInvalidCursor = errors.New("discovery: invalid cursor")
+
+// Paginate returns the page of items starting at cursor ("" for the first
+// page), at most size items long, and the cursor of the next page ("" after
+// the last). scope identifies the listing (what was listed and how it was
+// filtered), so a cursor cannot be replayed against another listing. Every
+// page is cut from a fresh listing, so items must be in a stable order:
+// Sort them first.
+func Paginate[T any](items []T, cursor string, size int, scope string) (page []T, next string, err error) {
+	if size < 1 || size > MaxPageSize {
+		return nil, "", fmt.Errorf("discovery: page size must be between 1 and %d, got %d", MaxPageSize, size)
+	}
+	offset := 0
+	if cursor != "" {
+		if offset, err = decodeCursor(cursor, scope); err != nil {
+			return nil, "", err
+		}
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := min(offset+size, len(items))
+	if end < len(items) {
+		next = encodeCursor(end, scope)
+	}
+	return items[offset:end], next, nil
+}
+
+// Page sorts filtered items by name and returns the page at cursor, at most
+// size items long (DefaultPageSize when size is 0), the number of items on
+// all pages together and the cursor of the next page. scope names what was
+// listed; f is added to it, so a cursor only continues the listing and
+// filter it was issued for. This is how `armv serve` and the MCP discovery
+// tools page their listings.
+func Page[T Record](items []T, f Filter, cursor string, size int, scope string) (page []T, total int, next string, err error) {
+	if size == 0 {
+		size = DefaultPageSize
+	}
+	_ = Sort(items, "name")
+	scope = fmt.Sprintf("%s|%q|%q|%q|%q", scope, strings.ToLower(f.Text), strings.ToLower(f.Type), strings.ToLower(f.Location), strings.ToLower(f.Tag))
+	page, next, err = Paginate(items, cursor, size, scope)
+	return page, len(items), next, err
+}
+
+// A cursor is the base64 of "offset:scope hash".
+func encodeCursor(offset int, scope string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset) + ":" + scopeHash(scope)))
+}
+
+func decodeCursor(cursor, scope string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	off, hash, ok := strings.Cut(string(raw), ":")
+	offset, err := strconv.Atoi(off)
+	if !ok || err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidCursor, cursor)
+	}
+	if hash != scopeHash(scope) {
+		return 0, fmt.Errorf("%w %q: it was issued for a different listing or filter", ErrInvalidCursor, cursor)
+	}
+	return offset, nil
+}
+
+func scopeHash(scope string) string {
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Fields lists the names Project accepts for the record kind: its columns,
+// plus "tags" for Tagged records.
+func Fields[T Record]() []string {
+	var zero T
+	fields := zero.Columns()
+	if _, ok := any(zero).(Tagged); ok {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+// Project returns each item as a map holding only the named fields, in the
+// shape of the record's JSON form. Unknown fields are an error.
+func Project[T Record](items []T, fields []string) ([]map[string]any, error) {
+	allowed := Fields[T]()
+	for _, f := range fields {
+		if !slices.Contains(allowed, f) {
+			return nil, fmt.Errorf("discovery: unknown field %q: must be one of %s", f, strings.Join(allowed, ", "))
+		}
+	}
+
+	out := make([]map[string]any, len(items))
+	for i, item := range items {
+		m := make(map[string]any, len(fields))
+		for _, f := range fields {
+			if f == "tags" {
+				if tags := any(item).(Tagged).TagSet(); len(tags) > 0 {
+					m[f] = tags
+				}
+				continue
+			}
+			m[f], _ = item.Field(f)
+		}
+		out[i] = m
+	}
+	return out, nil
+}
+
+// CountBy returns how many items have each value of column, or nil if the
+// record kind has no such column.
+func CountBy[T Record](items []T, column string) map[string]int {
+	var zero T
+	if _, ok := zero.Field(column); !ok {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, item := range items {
+		v, _ := item.Field(column)
+		counts[v]++
+	}
+	return counts
+}
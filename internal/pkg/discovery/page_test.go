@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	items := []int{1, 2, 3, 4, 5}
+	var got []int
+	cursor, pages := "", 0
+	for {
+		page, next, err := Paginate(items, cursor, 2, "scope")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !slices.Equal(got, items) || pages != 3 {
+		t.Errorf("paged %v in %d pages, want %v in 3", got, pages, items)
+	}
+
+	if _, _, err := Paginate(items, cursor, 2, "other scope"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor from another scope: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, _, err := Paginate(items, "not-a-cursor!", 2, "scope"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("garbage cursor: err = %v, want ErrInvalidCursor", err)
+	}
+	for _, size := range []int{0, MaxPageSize + 1} {
+		if _, _, err := Paginate(items, "", size, "scope"); err == nil {
+			t.Errorf("page size %d accepted", size)
+		}
+	}
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+
+	items := slices.Clone(testResources)
+	page, total, next, err := Page(items, Filter{}, "", 0, "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(testResources) || len(page) != len(testResources) || next != "" {
+		t.Errorf("Page() = %d of %d items, next %q; want all in one default-size page", len(page), total, next)
+	}
+	if got, want := names(page), []string{"plan", "Store", "web"}; !slices.Equal(got, want) {
+		t.Errorf("Page() names = %v, want %v", got, want)
+	}
+
+	_, _, next, err = Page(items, Filter{Location: "australiaeast"}, "", 1, "resources")
+	if err != nil || next == "" {
+		t.Fatalf("Page() next = %q, err = %v", next, err)
+	}
+	if _, _, _, err := Page(items, Filter{Location: "AustraliaEast"}, next, 1, "resources"); err != nil {
+		t.Errorf("cursor rejected for the same filter in another case: %v", err)
+	}
+	if _, _, _, err := Page(items, Filter{Location: "eastus"}, next, 1, "resources"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor for another filter: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	got, err := Project(testResources[:2], []string{"name", "tags"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0]["name"] != "web" || len(got[0]) != 2 {
+		t.Errorf("Project()[0] = %v", got[0])
+	}
+	if tags, _ := got[1]["tags"].(map[string]string); !maps.Equal(tags, map[string]string{"env": "dev"}) {
+		t.Errorf("Project()[1] tags = %v", got[1]["tags"])
+	}
+	if _, err := Project(testResources, []string{"size"}); err == nil {
+		t.Error("Project() accepted an unknown field")
+	}
+	if _, err := Project([]Subscription{}, []string{"tags"}); err == nil {
+		t.Error("Project() accepted tags for subscriptions")
+	}
+}
+
+func TestCountBy(t *testing.T) {
+	t.Parallel()
+
+	if got, want := CountBy(testResources, "location"), map[string]int{"australiaeast": 2, "eastus": 1}; !maps.Equal(got, want) {
+		t.Errorf("CountBy(location) = %v, want %v", got, want)
+	}
+	if got := CountBy([]ResourceGroup{}, "type"); got != nil {
+		t.Errorf("CountBy(type) on resource groups = %v, want nil", got)
+	}
+}
@@ -0,0 +1,207 @@
+package discovery
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Record is a listed item whose columns can be read by name, so filtering,
+// sorting and tabular output work the same for every kind of listing.
+type Record interface {
+	// Columns lists the column names in display order.
+	Columns() []string
+	// Field returns the named column; ok is false for an unknown column.
+	Field(name string) (value string, ok bool)
+}
+
+// Columns implements Record.
+func (Subscription) Columns() []string {
+	return []string{"subscription_id", "display_name", "state", "id"}
+}
+
+// Field implements Record. "name" is accepted as an alias for display_name.
+func (s Subscription) Field(name string) (string, bool) {
+	switch name {
+	case "subscription_id":
+		return s.SubscriptionID, true
+	case "display_name", "name":
+		return s.DisplayName, true
+	case "state":
+		return s.State, true
+	case "id":
+		return s.ID, true
+	}
+	return "", false
+}
+
+// Columns implements Record.
+func (ResourceGroup) Columns() []string {
+	return []string{"name", "location", "id"}
+}
+
+// Field implements Record.
+func (g ResourceGroup) Field(name string) (string, bool) {
+	switch name {
+	case "name":
+		return g.Name, true
+	case "location":
+		return g.Location, true
+	case "id":
+		return g.ID, true
+	}
+	return "", false
+}
+
+// Columns implements Record.
+func (Resource) Columns() []string {
+	return []string{"name", "type", "location", "id"}
+}
+
+// Field implements Record.
+func (r Resource) Field(name string) (string, bool) {
+	switch name {
+	case "name":
+		return r.Name, true
+	case "type":
+		return r.Type, true
+	case "location":
+		return r.Location, true
+	case "id":
+		return r.ID, true
+	}
+	return "", false
+}
+
+// Tagged is a Record that carries Azure tags: resource groups and resources.
+type Tagged interface {
+	Record
+	TagSet() map[string]string
+}
+
+// TagSet implements Tagged.
+func (g ResourceGroup) TagSet() map[string]string { return g.Tags }
+
+// TagSet implements Tagged.
+func (r Resource) TagSet() map[string]string { return r.Tags }
+
+// Filter narrows a listing. Empty fields match everything and all given
+// fields must match.
+type Filter struct {
+	// Text is a case-insensitive substring of the name (or, for
+	// subscriptions, the display name or subscription ID).
+	Text string
+	// Type is a case-insensitive resource type, e.g. Microsoft.Web/sites.
+	// A trailing "/" matches a whole provider namespace.
+	Type string
+	// Location is an Azure region; case and spaces are ignored, so
+	// "Australia East" matches "australiaeast".
+	Location string
+	// Tag is a tag name, or name=value; both compare case-insensitively.
+	Tag string
+}
+
+// Apply returns the items matching f. It is an error to filter on a column
+// the record kind does not have (e.g. --type on resource groups).
+func Apply[T Record](items []T, f Filter) ([]T, error) {
+	var zero T
+	if f.Type != "" {
+		if _, ok := zero.Field("type"); !ok {
+			return nil, fmt.Errorf("discovery: type filter applies only to resources")
+		}
+	}
+	if f.Location != "" {
+		if _, ok := zero.Field("location"); !ok {
+			return nil, fmt.Errorf("discovery: location filter does not apply to subscriptions")
+		}
+	}
+	if f.Tag != "" {
+		if _, ok := any(zero).(Tagged); !ok {
+			return nil, fmt.Errorf("discovery: tag filter does not apply to subscriptions")
+		}
+		if name, _, _ := strings.Cut(f.Tag, "="); strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("discovery: tag filter %q has no tag name: use name or name=value", f.Tag)
+		}
+	}
+
+	text := strings.ToLower(strings.TrimSpace(f.Text))
+	typ := strings.ToLower(strings.TrimSpace(f.Type))
+	loc := normaliseLocation(f.Location)
+	tagName, tagValue, withValue := strings.Cut(strings.TrimSpace(f.Tag), "=")
+
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if text != "" && !matchesText(item, text) {
+			continue
+		}
+		if typ != "" {
+			v, _ := item.Field("type")
+			v = strings.ToLower(v)
+			if v != typ && !(strings.HasSuffix(typ, "/") && strings.HasPrefix(v, typ)) {
+				continue
+			}
+		}
+		if loc != "" {
+			if v, _ := item.Field("location"); normaliseLocation(v) != loc {
+				continue
+			}
+		}
+		if tagName != "" && !matchesTag(any(item).(Tagged), tagName, tagValue, withValue) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func matchesTag(item Tagged, name, value string, withValue bool) bool {
+	for k, v := range item.TagSet() {
+		if strings.EqualFold(k, strings.TrimSpace(name)) && (!withValue || strings.EqualFold(v, strings.TrimSpace(value))) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesText(item Record, text string) bool {
+	for _, col := range []string{"name", "subscription_id"} {
+		if v, ok := item.Field(col); ok && strings.Contains(strings.ToLower(v), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func normaliseLocation(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+}
+
+// Sort orders items in place by the column key, case-insensitively; a
+// leading "-" sorts descending. Ties are broken by ID so output is stable
+// across calls.
+func Sort[T Record](items []T, key string) error {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	var zero T
+	if _, ok := zero.Field(key); !ok {
+		return fmt.Errorf("discovery: cannot sort by %q: must be one of %s", key, strings.Join(zero.Columns(), ", "))
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		av, _ := a.Field(key)
+		bv, _ := b.Field(key)
+		c := cmp.Compare(strings.ToLower(av), strings.ToLower(bv))
+		if c == 0 {
+			aid, _ := a.Field("id")
+			bid, _ := b.Field("id")
+			c = cmp.Compare(aid, bid)
+		}
+		if desc {
+			return -c
+		}
+		return c
+	})
+	return nil
+}
@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"slices"
+	"testing"
+)
+
+var testResources = []Resource{
+	{Name: "web", Type: "Microsoft.Web/sites", Location: "australiaeast", ID: "/r/3", Tags: map[string]string{"Env": "Prod", "owner": "web-team"}},
+	{Name: "plan", Type: "Microsoft.Web/serverFarms", Location: "eastus", ID: "/r/2", Tags: map[string]string{"env": "dev"}},
+	{Name: "Store", Type: "Microsoft.Storage/storageAccounts", Location: "australiaeast", ID: "/r/1"},
+}
+
+func names[T Record](items []T) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i], _ = item.Field("name")
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "empty filter", filter: Filter{}, want: []string{"web", "plan", "Store"}},
+		{name: "text is case-insensitive", filter: Filter{Text: "STO"}, want: []string{"Store"}},
+		{name: "exact type", filter: Filter{Type: "microsoft.web/sites"}, want: []string{"web"}},
+		{name: "provider namespace", filter: Filter{Type: "Microsoft.Web/"}, want: []string{"web", "plan"}},
+		{name: "location ignores case and spaces", filter: Filter{Location: "Australia East"}, want: []string{"web", "Store"}},
+		{name: "all fields must match", filter: Filter{Type: "Microsoft.Web/", Location: "eastus"}, want: []string{"plan"}},
+		{name: "tag name", filter: Filter{Tag: "ENV"}, want: []string{"web", "plan"}},
+		{name: "tag name and value", filter: Filter{Tag: "env=prod"}, want: []string{"web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Apply(testResources, tt.filter)
+			if err != nil || !slices.Equal(names(got), tt.want) {
+				t.Errorf("Apply() = (%v, %v), want %v", names(got), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejectsMissingColumns(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Apply([]ResourceGroup{}, Filter{Type: "x"}); err == nil {
+		t.Error("type filter on resource groups succeeded")
+	}
+	if _, err := Apply([]Subscription{}, Filter{Location: "x"}); err == nil {
+		t.Error("location filter on subscriptions succeeded")
+	}
+	if _, err := Apply([]Subscription{}, Filter{Tag: "env"}); err == nil {
+		t.Error("tag filter on subscriptions succeeded")
+	}
+	if _, err := Apply([]Resource{}, Filter{Tag: "=prod"}); err == nil {
+		t.Error("tag filter without a name succeeded")
+	}
+}
+
+func TestApplyMatchesSubscriptionID(t *testing.T) {
+	t.Parallel()
+
+	subs := []Subscription{{SubscriptionID: "aaaa-1111", DisplayName: "Prod"}, {SubscriptionID: "bbbb-2222", DisplayName: "Dev"}}
+	got, err := Apply(subs, Filter{Text: "bbbb"})
+	if err != nil || len(got) != 1 || got[0].DisplayName != "Dev" {
+		t.Errorf("Apply() = (%v, %v)", got, err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	items := slices.Clone(testResources)
+	if err := Sort(items, "name"); err != nil || !slices.Equal(names(items), []string{"plan", "Store", "web"}) {
+		t.Errorf("Sort(name) = (%v, %v)", names(items), err)
+	}
+	// Equal locations fall back to ID order, so the result is deterministic.
+	if err := Sort(items, "-location"); err != nil || !slices.Equal(names(items), []string{"plan", "web", "Store"}) {
+		t.Errorf("Sort(-location) = (%v, %v)", names(items), err)
+	}
+	if err := Sort(items, "size"); err == nil {
+		t.Error("Sort(size) succeeded for an unknown column")
+	}
+}
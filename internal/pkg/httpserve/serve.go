@@ -0,0 +1,58 @@
+// Package httpserve runs ARMV's HTTP server modes (`armv serve` and
+// `armv mcp serve --listen`) until they are asked to stop, then shuts them
+// down gracefully.
+package httpserve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ShutdownTimeout bounds how long in-flight requests may finish once the
+// server is asked to stop.
+const ShutdownTimeout = 10 * time.Second
+
+// Serve serves handler on addr until ctx is cancelled, then shuts down
+// gracefully, letting in-flight requests finish for up to ShutdownTimeout.
+// name prefixes the log messages and errors, e.g. "restapi"; attrs are
+// logged with the listen address once the server is up. TLS is expected to
+// be terminated in front of the server (ingress, sidecar or load balancer).
+func Serve(ctx context.Context, name, addr string, handler http.Handler, logger *slog.Logger, attrs ...any) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%s: listen on %s: %w", name, addr, err)
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	logger.Info(name+": serving", append([]any{"addr", ln.Addr().String()}, attrs...)...)
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("%s: serve: %w", name, err)
+	case <-ctx.Done():
+	}
+
+	logger.Info(name + ": shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Open event streams never go idle, so they are cut off here.
+		logger.Warn(name+": closing connections still open after shutdown timeout", "timeout", ShutdownTimeout)
+		_ = srv.Close()
+	}
+	if err := <-errc; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: serve: %w", name, err)
+	}
+	return nil
+}
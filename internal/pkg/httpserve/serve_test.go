@@ -0,0 +1,46 @@
+package httpserve
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeStopsWhenContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	ctx, cancel := context.WithCancel(t.Context())
+	errc := make(chan error, 1)
+	go func() { errc <- Serve(ctx, "test", "127.0.0.1:0", http.NotFoundHandler(), logger, "path", "/x") }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("Serve() = %v, want nil after cancellation", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("Serve() did not return after cancellation")
+	}
+	for _, want := range []string{"test: serving", "path=/x", "test: shutting down"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestServeReportsListenError(t *testing.T) {
+	t.Parallel()
+
+	err := Serve(t.Context(), "test", "127.0.0.1:-1", http.NotFoundHandler(), slog.Default())
+	if err == nil || !strings.HasPrefix(err.Error(), "test: listen on 127.0.0.1:-1") {
+		t.Errorf("Serve() = %v, want a listen error", err)
+	}
+}
@@ -0,0 +1,306 @@
+// Package jobs runs validations in the background for the server modes:
+// the REST API's /v1/validations endpoints and the MCP server's job tools.
+// A Manager queues submitted jobs, runs a bounded number at once, keeps
+// finished ones for a retention period and tracks them in the
+// armv_jobs_in_flight gauge. What a job runs and how it is shown to clients
+// is left to the server.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+)
+
+const (
+	// DefaultMaxRunning is how many jobs run at once.
+	DefaultMaxRunning = 4
+	// DefaultRetention is how long a finished job stays available.
+	DefaultRetention = time.Hour
+
+	// MaxPending bounds the jobs queued behind the running ones, so a
+	// runaway client cannot grow the queue without limit.
+	MaxPending = 32
+)
+
+// State is where a job is in its lifecycle.
+type State string
+
+const (
+	Queued    State = "queued"
+	Running   State = "running"
+	Completed State = "completed"
+	Failed    State = "failed"
+	Cancelled State = "cancelled"
+)
+
+// Finished reports whether a job in state s has ended.
+func (s State) Finished() bool {
+	return s == Completed || s == Failed || s == Cancelled
+}
+
+// ErrQueueFull is returned, wrapped, by Submit when MaxPending jobs are
+// already queued.
+var ErrQueueFull = errors.New("jobs: validation queue is full")
+
+// Func runs one job. notify records progress messages and resumeID records
+// where the operation's resume state was saved.
+type Func[T any] func(ctx context.Context, notify validator.ProgressFn, resumeID func(string)) (T, error)
+
+// Job is one submitted job. Request is what it was submitted with; the
+// rest of its state is read through Snapshot.
+type Job[R, T any] struct {
+	ID        string
+	Request   R
+	CreatedAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the job has finished
+
+	mu         sync.Mutex
+	state      State
+	message    string
+	resumeID   string
+	startedAt  time.Time
+	result     T
+	err        error
+	finishedAt time.Time
+}
+
+// Snapshot is a job's state at one moment.
+type Snapshot[T any] struct {
+	State    State
+	Message  string
+	ResumeID string
+	// StartedAt is zero while the job is queued, FinishedAt until it has
+	// finished.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Result and Err are what the job's Func returned, once it has
+	// finished. Err is a *validator.Error; its kind is interrupted when the
+	// job was cancelled.
+	Result T
+	Err    error
+}
+
+// Snapshot returns the job's current state.
+func (j *Job[R, T]) Snapshot() Snapshot[T] {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return Snapshot[T]{
+		State:      j.state,
+		Message:    j.message,
+		ResumeID:   j.resumeID,
+		StartedAt:  j.startedAt,
+		FinishedAt: j.finishedAt,
+		Result:     j.result,
+		Err:        j.err,
+	}
+}
+
+// Cancel stops the job: a queued job never runs and a running one has its
+// context cancelled. Cancelling a finished job changes nothing.
+func (j *Job[R, T]) Cancel() { j.cancel() }
+
+// Done is closed once the job has finished.
+func (j *Job[R, T]) Done() <-chan struct{} { return j.done }
+
+// Manager runs jobs in the background. At most maxRunning jobs run at once;
+// the rest wait in the queue. Finished jobs are kept for the retention
+// period and pruned lazily on the next call.
+type Manager[R, T any] struct {
+	// Now is the manager's clock; tests replace it.
+	Now func() time.Time
+
+	idPrefix  string
+	retention time.Duration
+	slots     chan struct{}
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*Job[R, T]
+}
+
+// NewManager returns a manager whose job IDs start with idPrefix. Zero or
+// negative maxRunning and retention mean DefaultMaxRunning and
+// DefaultRetention.
+func NewManager[R, T any](idPrefix string, maxRunning int, retention time.Duration) *Manager[R, T] {
+	if maxRunning <= 0 {
+		maxRunning = DefaultMaxRunning
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Manager[R, T]{
+		Now:       time.Now,
+		idPrefix:  idPrefix,
+		retention: retention,
+		slots:     make(chan struct{}, maxRunning),
+		jobs:      make(map[string]*Job[R, T]),
+	}
+}
+
+// Retention is how long a finished job is kept.
+func (m *Manager[R, T]) Retention() time.Duration { return m.retention }
+
+// ExpiresAt is when the finished job described by s is discarded.
+func (m *Manager[R, T]) ExpiresAt(s Snapshot[T]) time.Time { return s.FinishedAt.Add(m.retention) }
+
+// Submit queues run as a new job for req and returns it. The job's context
+// is independent of the submitting request, so it outlives it.
+func (m *Manager[R, T]) Submit(req R, run Func[T]) (*Job[R, T], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+
+	pending := 0
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		if j.state == Queued {
+			pending++
+		}
+		j.mu.Unlock()
+	}
+	if pending >= MaxPending {
+		return nil, fmt.Errorf("%w: %d jobs are waiting", ErrQueueFull, pending)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Job[R, T]{
+		ID:        m.idPrefix + strings.ToLower(rand.Text()[:16]),
+		Request:   req,
+		CreatedAt: m.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		state:     Queued,
+	}
+	m.jobs[j.ID] = j
+	metrics.JobsInFlight.With(string(Queued)).Inc()
+	m.wg.Go(func() { m.run(ctx, j, run) })
+	return j, nil
+}
+
+func (m *Manager[R, T]) run(ctx context.Context, j *Job[R, T], run Func[T]) {
+	defer j.cancel()
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		var zero T
+		j.finish(zero, validator.NewError(validator.KindInterrupted, errors.New("validation job cancelled before it started")), m.Now())
+		return
+	}
+
+	j.mu.Lock()
+	j.state, j.startedAt = Running, m.Now()
+	metrics.JobsInFlight.With(string(Queued)).Dec()
+	metrics.JobsInFlight.With(string(Running)).Inc()
+	j.mu.Unlock()
+
+	notify := func(message string) {
+		j.mu.Lock()
+		j.message = message
+		j.mu.Unlock()
+	}
+	resumeID := func(id string) {
+		j.mu.Lock()
+		j.resumeID = id
+		j.mu.Unlock()
+	}
+	out, err := run(ctx, notify, resumeID)
+	if err != nil && ctx.Err() != nil {
+		// Whatever the call failed with, the cause was the cancellation.
+		err = &validator.Error{Kind: validator.KindInterrupted, Err: fmt.Errorf("validation job cancelled: %w", err)}
+	}
+	j.finish(out, err, m.Now())
+}
+
+// Get returns the job with id, or nil if there is none (any more).
+func (m *Manager[R, T]) Get(id string) *Job[R, T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	return m.jobs[id]
+}
+
+// List returns every job still kept, newest first.
+func (m *Manager[R, T]) List() []*Job[R, T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	jobs := make([]*Job[R, T], 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	slices.SortFunc(jobs, func(a, b *Job[R, T]) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return jobs
+}
+
+// Stop cancels every unfinished job and waits up to timeout for them to
+// end. It reports how many were still unfinished when asked to stop.
+func (m *Manager[R, T]) Stop(timeout time.Duration) int {
+	m.mu.Lock()
+	cancelled := 0
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		if !j.state.Finished() {
+			cancelled++
+		}
+		j.mu.Unlock()
+		j.cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+	return cancelled
+}
+
+// pruneLocked drops finished jobs older than the retention period.
+func (m *Manager[R, T]) pruneLocked() {
+	now := m.Now()
+	for id, j := range m.jobs {
+		j.mu.Lock()
+		expired := j.state.Finished() && now.Sub(j.finishedAt) >= m.retention
+		j.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (j *Job[R, T]) finish(out T, err error, now time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	defer close(j.done)
+	metrics.JobsInFlight.With(string(j.state)).Dec()
+	j.result, j.err, j.finishedAt = out, err, now
+	switch {
+	case err == nil:
+		j.state, j.message = Completed, "Validation complete"
+	case validator.KindOf(err) == validator.KindInterrupted:
+		j.state = Cancelled
+	default:
+		j.state = Failed
+	}
+}
@@ -0,0 +1,163 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+)
+
+// waitDone fails the test if j does not finish promptly.
+func waitDone[R, T any](t *testing.T, j *Job[R, T]) {
+	t.Helper()
+	select {
+	case <-j.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job %s did not finish", j.ID)
+	}
+}
+
+// waitState polls until j reaches state.
+func waitState[R, T any](t *testing.T, j *Job[R, T], state State) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if j.Snapshot().State == state {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s state = %s, want %s", j.ID, j.Snapshot().State, state)
+}
+
+// blockUntilCancelled is a Func that runs until its job is cancelled.
+func blockUntilCancelled(ctx context.Context, _ validator.ProgressFn, _ func(string)) (string, error) {
+	<-ctx.Done()
+	return "", validator.PollError(ctx.Err())
+}
+
+func TestJobRecordsProgressAndExpires(t *testing.T) {
+	m := NewManager[string, string]("job-", 1, time.Minute)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+
+	j, err := m.Submit("request", func(_ context.Context, notify validator.ProgressFn, resumeID func(string)) (string, error) {
+		notify("Polling")
+		resumeID("resume-x.json")
+		return "result", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitDone(t, j)
+
+	if !strings.HasPrefix(j.ID, "job-") || j.Request != "request" {
+		t.Errorf("job = %s for %q", j.ID, j.Request)
+	}
+	s := j.Snapshot()
+	if s.State != Completed || s.Result != "result" || s.Err != nil || s.ResumeID != "resume-x.json" || s.Message != "Validation complete" {
+		t.Errorf("snapshot = %+v", s)
+	}
+	if !m.ExpiresAt(s).Equal(now.Add(time.Minute)) {
+		t.Errorf("ExpiresAt = %s", m.ExpiresAt(s))
+	}
+
+	now = now.Add(time.Minute)
+	if m.Get(j.ID) != nil || len(m.List()) != 0 {
+		t.Error("job kept after its retention period")
+	}
+}
+
+func TestJobFailureAndCancellation(t *testing.T) {
+	m := NewManager[struct{}, string]("job-", 1, time.Minute)
+	failed, _ := m.Submit(struct{}{}, func(context.Context, validator.ProgressFn, func(string)) (string, error) {
+		return "", validator.NewError(validator.KindResourceGroupNotFound, errors.New("no such group"))
+	})
+	waitDone(t, failed)
+	if s := failed.Snapshot(); s.State != Failed || validator.KindOf(s.Err) != validator.KindResourceGroupNotFound {
+		t.Errorf("failed job = %+v", s)
+	}
+
+	running, _ := m.Submit(struct{}{}, blockUntilCancelled)
+	waitState(t, running, Running)
+	queued, _ := m.Submit(struct{}{}, func(context.Context, validator.ProgressFn, func(string)) (string, error) {
+		t.Error("cancelled queued job ran")
+		return "", nil
+	})
+	for _, j := range []*Job[struct{}, string]{queued, running} {
+		j.Cancel()
+		waitDone(t, j)
+		if s := j.Snapshot(); s.State != Cancelled || validator.KindOf(s.Err) != validator.KindInterrupted {
+			t.Errorf("job %s after cancel = %+v", j.ID, s)
+		}
+	}
+	if s := queued.Snapshot(); !s.StartedAt.IsZero() {
+		t.Errorf("job cancelled while queued has StartedAt %s", s.StartedAt)
+	}
+}
+
+func TestQueueIsBounded(t *testing.T) {
+	m := NewManager[struct{}, string]("job-", 1, time.Minute)
+	defer m.Stop(5 * time.Second)
+
+	running, _ := m.Submit(struct{}{}, blockUntilCancelled)
+	waitState(t, running, Running)
+	for range MaxPending {
+		if _, err := m.Submit(struct{}{}, blockUntilCancelled); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Submit(struct{}{}, blockUntilCancelled); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("submit with a full queue: err = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestListIsNewestFirst(t *testing.T) {
+	m := NewManager[int, string]("job-", 0, 0)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.Now = func() time.Time { return now }
+	for i := range 3 {
+		j, _ := m.Submit(i, func(context.Context, validator.ProgressFn, func(string)) (string, error) { return "", nil })
+		waitDone(t, j)
+		now = now.Add(time.Second)
+	}
+	var got []int
+	for _, j := range m.List() {
+		got = append(got, j.Request)
+	}
+	if len(got) != 3 || got[0] != 2 || got[2] != 0 {
+		t.Errorf("List() requests = %v, want newest first", got)
+	}
+}
+
+func TestStopCancelsUnfinishedJobsAndBalancesGauge(t *testing.T) {
+	queued, running := metrics.JobsInFlight.With(string(Queued)), metrics.JobsInFlight.With(string(Running))
+	queuedBefore, runningBefore := queued.Value(), running.Value()
+
+	m := NewManager[struct{}, string]("job-", 1, time.Minute)
+	first, _ := m.Submit(struct{}{}, blockUntilCancelled)
+	waitState(t, first, Running)
+	second, _ := m.Submit(struct{}{}, blockUntilCancelled)
+	if got := running.Value() - runningBefore; got != 1 {
+		t.Errorf("running gauge delta = %v, want 1", got)
+	}
+	if got := queued.Value() - queuedBefore; got != 1 {
+		t.Errorf("queued gauge delta = %v, want 1", got)
+	}
+
+	if n := m.Stop(5 * time.Second); n != 2 {
+		t.Errorf("Stop() = %d, want 2", n)
+	}
+	for _, j := range []*Job[struct{}, string]{first, second} {
+		if s := j.Snapshot(); s.State != Cancelled {
+			t.Errorf("job %s after Stop = %s", j.ID, s.State)
+		}
+	}
+	if queued.Value() != queuedBefore || running.Value() != runningBefore {
+		t.Errorf("gauge not balanced after Stop: queued %v (was %v), running %v (was %v)", queued.Value(), queuedBefore, running.Value(), runningBefore)
+	}
+}
@@ -0,0 +1,152 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CredentialInput selects the Azure credential for a tool call and is
+// embedded in every tool input that calls Azure. By default a call may only
+// name a credential profile configured on the server; the inline fields are
+// accepted only when Options.AllowInlineCredentials is set, since anything a
+// client sends also lands in the conversation and the client's logs.
+type CredentialInput struct {
+	CredentialProfile string `json:"credential_profile,omitempty" jsonschema:"optional name of a credential profile configured on the server; omit to use the server's default credential"`
+
+	TenantID     string `json:"tenant_id,omitempty"     jsonschema:"optional service principal tenant UUID; supply with client_id and client_secret"`
+	ClientID     string `json:"client_id,omitempty"     jsonschema:"optional service principal client (application) UUID"`
+	ClientSecret string `json:"client_secret,omitempty" jsonschema:"optional service principal client secret"`
+	BearerToken  string `json:"bearer_token,omitempty"  jsonschema:"optional Azure AD bearer token for https://management.azure.com (obtain via 'az account get-access-token' or similar); takes precedence over the service principal fields"`
+}
+
+// inlineCredentialFields are the CredentialInput properties that carry a
+// credential rather than name one.
+var inlineCredentialFields = []string{"tenant_id", "client_id", "client_secret", "bearer_token"}
+
+func (in CredentialInput) inline() bool {
+	return in.TenantID != "" || in.ClientID != "" || in.ClientSecret != "" || in.BearerToken != ""
+}
+
+// credentials resolves each tool call's Azure credential from the server's
+// configuration.
+type credentials struct {
+	server      azcore.TokenCredential // nil means DefaultAzureCredential, built per call
+	profiles    map[string]azcore.TokenCredential
+	allowInline bool
+}
+
+func newCredentials(opts Options) *credentials {
+	return &credentials{server: opts.Credential, profiles: opts.CredentialProfiles, allowInline: opts.AllowInlineCredentials}
+}
+
+// resolve returns the credential in selects: a named profile, inline
+// credentials if the server allows them, else the server's default.
+func (c *credentials) resolve(in CredentialInput) (azcore.TokenCredential, error) {
+	profile := strings.TrimSpace(in.CredentialProfile)
+	if in.inline() {
+		if !c.allowInline {
+			return nil, fmt.Errorf("tenant_id, client_id, client_secret and bearer_token are disabled on this server; omit them to use the server's credential%s", c.profileHint())
+		}
+		if profile != "" {
+			return nil, errors.New("credential_profile cannot be combined with tenant_id/client_id/client_secret/bearer_token; pick one auth method")
+		}
+		return selectCredential(in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken)
+	}
+	if profile != "" {
+		cred, ok := c.profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown credential_profile %q%s", profile, c.profileHint())
+		}
+		return cred, nil
+	}
+	if c.server != nil {
+		return c.server, nil
+	}
+	return auth.GetAzureDefaultCredential()
+}
+
+// fingerprint identifies the credential in selects without revealing it, so
+// validations run as the same identity can share results and others cannot.
+// Call it only once resolve has accepted in.
+func (c *credentials) fingerprint(in CredentialInput) string {
+	if in.inline() {
+		sum := sha256.Sum256([]byte(strings.Join([]string{in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken}, "\x00")))
+		return "inline:" + hex.EncodeToString(sum[:8])
+	}
+	if profile := strings.TrimSpace(in.CredentialProfile); profile != "" {
+		return "profile:" + profile
+	}
+	return "server"
+}
+
+// profileNames returns the configured profile names, sorted.
+func (c *credentials) profileNames() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (c *credentials) profileHint() string {
+	if len(c.profiles) == 0 {
+		return " (no credential profiles are configured)"
+	}
+	return fmt.Sprintf(" (credential profiles: %s)", strings.Join(c.profileNames(), ", "))
+}
+
+// inputSchema infers In's input schema and trims its CredentialInput fields
+// to what this server accepts: the inline fields are dropped unless allowed,
+// and credential_profile lists the configured profiles, or is dropped when
+// there are none. Clients then never ask the user for a secret the server
+// would refuse.
+func inputSchema[In any](c *credentials) *jsonschema.Schema {
+	s, err := jsonschema.For[In](nil)
+	if err != nil {
+		panic(fmt.Errorf("mcpserver: infer input schema: %w", err))
+	}
+	drop := func(name string) {
+		delete(s.Properties, name)
+		s.PropertyOrder = slices.DeleteFunc(s.PropertyOrder, func(p string) bool { return p == name })
+	}
+	if !c.allowInline {
+		for _, name := range inlineCredentialFields {
+			drop(name)
+		}
+	}
+	if p, ok := s.Properties["credential_profile"]; ok {
+		if len(c.profiles) == 0 {
+			drop("credential_profile")
+		} else {
+			for _, name := range c.profileNames() {
+				p.Enum = append(p.Enum, name)
+			}
+		}
+	}
+	return s
+}
+
+// logToolCalls logs every tool call with its arguments redacted, so the
+// server log records who asked for what without the secrets a client may
+// still send.
+func logToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil {
+			slog.InfoContext(ctx, "mcpserver: tool call", "tool", call.Params.Name, "arguments", pipeline.RedactBody(call.Params.Arguments))
+		}
+		return next(ctx, method, req)
+	}
+}
@@ -0,0 +1,116 @@
+package mcpserver
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCredentialsResolve(t *testing.T) {
+	server := auth.NewStaticTokenCredential("server-token")
+	prod := auth.NewStaticTokenCredential("prod-token")
+	profiles := map[string]azcore.TokenCredential{"prod": prod}
+
+	tests := []struct {
+		name        string
+		allowInline bool
+		in          CredentialInput
+		want        azcore.TokenCredential // nil: any credential
+		wantErr     string
+	}{
+		{name: "no fields -> server credential", in: CredentialInput{}, want: server},
+		{name: "known profile", in: CredentialInput{CredentialProfile: "prod"}, want: prod},
+		{name: "unknown profile lists the configured ones", in: CredentialInput{CredentialProfile: "dev"}, wantErr: `unknown credential_profile "dev" (credential profiles: prod)`},
+		{name: "inline secret refused by default", in: CredentialInput{ClientSecret: "s3cret"}, wantErr: "disabled on this server"},
+		{name: "inline bearer refused by default", in: CredentialInput{BearerToken: "eyJ.a.b"}, wantErr: "disabled on this server"},
+		{name: "inline bearer when allowed", allowInline: true, in: CredentialInput{BearerToken: "eyJ.a.b"}},
+		{name: "inline with profile is ambiguous", allowInline: true, in: CredentialInput{CredentialProfile: "prod", BearerToken: "eyJ.a.b"}, wantErr: "cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCredentials(Options{Credential: server, CredentialProfiles: profiles, AllowInlineCredentials: tt.allowInline})
+			cred, err := c.resolve(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolve() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve() error = %v", err)
+			}
+			if tt.want != nil && cred != tt.want {
+				t.Errorf("resolve() = %v, want %v", cred, tt.want)
+			}
+			if cred == nil {
+				t.Error("resolve() returned a nil credential")
+			}
+		})
+	}
+}
+
+func TestInputSchemaTrimsCredentialFields(t *testing.T) {
+	props := func(c *credentials) []string {
+		s := inputSchema[ListSubscriptionsInput](c)
+		var names []string
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := props(&credentials{}); len(got) != 0 {
+		t.Errorf("default schema properties = %v, want none", got)
+	}
+	if got, want := props(&credentials{allowInline: true}), []string{"bearer_token", "client_id", "client_secret", "tenant_id"}; !slices.Equal(got, want) {
+		t.Errorf("inline schema properties = %v, want %v", got, want)
+	}
+
+	c := &credentials{profiles: map[string]azcore.TokenCredential{"prod": nil, "dev": nil}}
+	s := inputSchema[ListSubscriptionsInput](c)
+	p, ok := s.Properties["credential_profile"]
+	if !ok {
+		t.Fatal("credential_profile missing although profiles are configured")
+	}
+	if want := []any{"dev", "prod"}; !slices.Equal(p.Enum, want) {
+		t.Errorf("credential_profile enum = %v, want %v", p.Enum, want)
+	}
+}
+
+// TestToolCallArgumentsAreRedactedInLogs sends secrets a server with inline
+// credentials disabled will refuse, and checks they never reach the log.
+func TestToolCallArgumentsAreRedactedInLogs(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	cs := connectInMemory(t, t.Context(), newTestServer(t), nil, nil)
+	res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+		Name: "list_subscriptions",
+		Arguments: map[string]any{
+			"client_secret": "very-secret-value",
+			"bearer_token":  "eyJhbGciOi.fake.token",
+		},
+	})
+	if err == nil && !res.IsError {
+		t.Error("list_subscriptions accepted inline credentials on a server that disallows them")
+	}
+
+	log := buf.String()
+	if !strings.Contains(log, "tool=list_subscriptions") {
+		t.Errorf("tool call was not logged:\n%s", log)
+	}
+	for _, secret := range []string{"very-secret-value", "eyJhbGciOi.fake.token"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("log contains secret %q:\n%s", secret, log)
+		}
+	}
+}
@@ -1,14 +1,13 @@
-//go:build ignore
-
 package mcpserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/AaronSaikovski/armv/internal/pkg/auth"
-	"github.com/AaronSaikovski/armv/internal/pkg/resourcegroups"
-	"github.com/AaronSaikovski/armv/internal/pkg/resources"
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 	"github.com/AaronSaikovski/armv/pkg/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -22,56 +21,97 @@ import (
 // ListSubscriptionsInput carries only auth fields; there are no resource
 // parameters because the operation is scoped to the credential's tenant.
 type ListSubscriptionsInput struct {
-	TenantID     string `json:"tenant_id,omitempty"     jsonschema:"optional service principal tenant UUID"`
-	ClientID     string `json:"client_id,omitempty"     jsonschema:"optional service principal client UUID"`
-	ClientSecret string `json:"client_secret,omitempty" jsonschema:"optional service principal client secret"`
-	BearerToken  string `json:"bearer_token,omitempty"  jsonschema:"optional Azure AD bearer token for https://management.azure.com"`
+	CredentialInput
 }
 
-type SubscriptionInfo struct {
-	SubscriptionID string `json:"subscription_id"          jsonschema:"Azure subscription UUID — use this value as source_subscription_id / target_subscription_id in validate_move"`
-	DisplayName    string `json:"display_name,omitempty"   jsonschema:"human-readable subscription name"`
-	State          string `json:"state,omitempty"          jsonschema:"subscription state (Enabled, Disabled, Warned, etc.)"`
-	ID             string `json:"id,omitempty"             jsonschema:"fully qualified ARM ID (/subscriptions/{sub})"`
-}
+// SubscriptionInfo, ResourceGroupInfo and ResourceInfo are the shared
+// discovery records; the CLI's `armv list` returns the same shapes.
+type SubscriptionInfo = discovery.Subscription
 
 type ListSubscriptionsOutput struct {
 	Subscriptions []SubscriptionInfo `json:"subscriptions" jsonschema:"subscriptions visible to the supplied credential"`
 	Count         int                `json:"count"         jsonschema:"number of subscriptions returned"`
 }
 
-func listSubscriptionsHandler(ctx context.Context, _ *mcp.CallToolRequest, in ListSubscriptionsInput) (*mcp.CallToolResult, ListSubscriptionsOutput, error) {
-	cred, err := selectCredential(in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken)
-	if err != nil {
-		return toolError(err), ListSubscriptionsOutput{}, nil
+func listSubscriptionsHandler(creds *credentials) mcp.ToolHandlerFor[ListSubscriptionsInput, ListSubscriptionsOutput] {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, in ListSubscriptionsInput) (*mcp.CallToolResult, ListSubscriptionsOutput, error) {
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListSubscriptionsOutput{}, nil
+		}
+
+		subs, err := discovery.ListSubscriptions(ctx, cred)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInternal, fmt.Errorf("failed to list subscriptions: %w", err))), ListSubscriptionsOutput{}, nil
+		}
+		return nil, ListSubscriptionsOutput{Subscriptions: subs, Count: len(subs)}, nil
 	}
+}
 
-	subs, err := auth.ListSubscriptions(ctx, cred)
-	if err != nil {
-		return toolError(fmt.Errorf("failed to list subscriptions: %w", err)), ListSubscriptionsOutput{}, nil
+// --- list options ---------------------------------------------------------
+
+// ListOptions filter, page and shape the list_resource_groups and
+// list_resources results. With none set the tools return every item in full,
+// as they always have.
+type ListOptions struct {
+	NameContains string   `json:"name_contains,omitempty" jsonschema:"optional case-insensitive substring of the name"`
+	Location     string   `json:"location,omitempty"      jsonschema:"optional Azure region, e.g. australiaeast (case and spaces ignored)"`
+	Tag          string   `json:"tag,omitempty"           jsonschema:"optional tag filter: a tag name, or name=value (case-insensitive)"`
+	Fields       []string `json:"fields,omitempty"        jsonschema:"optional fields to return for each item (e.g. name and id); results then come in items instead of the full records. tags is only returned when asked for here"`
+	Summary      bool     `json:"summary,omitempty"       jsonschema:"optional; return only counts of the matching items (per type and location) instead of the items. Use first on large groups"`
+	PageSize     int      `json:"page_size,omitempty"     jsonschema:"optional maximum number of items per call (1-1000); items are then sorted by name and next_cursor fetches the next page"`
+	Cursor       string   `json:"cursor,omitempty"        jsonschema:"next_cursor returned by the previous call, to fetch the next page with the same filters"`
+}
+
+// ListPage holds what the list options add to a listing's output.
+type ListPage struct {
+	Items      []map[string]any `json:"items,omitzero"        jsonschema:"the requested fields of each item, when fields was set"`
+	Summary    *ListSummary     `json:"summary,omitempty"     jsonschema:"counts of the matching items, when summary was set"`
+	TotalCount int              `json:"total_count,omitempty" jsonschema:"number of items matching the filters across all pages, when paging"`
+	NextCursor string           `json:"next_cursor,omitempty" jsonschema:"pass as cursor to fetch the next page; absent on the last page"`
+}
+
+// ListSummary counts the items matching the filters.
+type ListSummary struct {
+	Total      int            `json:"total"                 jsonschema:"number of matching items"`
+	ByType     map[string]int `json:"by_type,omitempty"     jsonschema:"matching items per resource type"`
+	ByLocation map[string]int `json:"by_location,omitempty" jsonschema:"matching items per Azure region"`
+}
+
+// applyListOptions filters items, then either summarises them or sorts,
+// pages and projects them. It returns the full records when neither a
+// summary nor a projection was asked for, and the number of items the
+// response carries. Calling it with no items checks opts without calling
+// Azure. scope names the listing the cursor belongs to.
+func applyListOptions[T discovery.Record](items []T, filter discovery.Filter, opts ListOptions, scope string) ([]T, ListPage, int, error) {
+	var page ListPage
+	paging := opts.PageSize != 0 || opts.Cursor != ""
+	if opts.Summary && (paging || len(opts.Fields) > 0) {
+		return nil, page, 0, errors.New("summary cannot be combined with fields, page_size or cursor")
 	}
 
-	out := ListSubscriptionsOutput{
-		Subscriptions: make([]SubscriptionInfo, 0, len(subs)),
-		Count:         len(subs),
+	items, err := discovery.Apply(items, filter)
+	if err != nil {
+		return nil, page, 0, err
 	}
-	for _, s := range subs {
-		info := SubscriptionInfo{}
-		if s.SubscriptionID != nil {
-			info.SubscriptionID = *s.SubscriptionID
-		}
-		if s.DisplayName != nil {
-			info.DisplayName = *s.DisplayName
-		}
-		if s.State != nil {
-			info.State = string(*s.State)
+	if opts.Summary {
+		page.Summary = &ListSummary{Total: len(items), ByType: discovery.CountBy(items, "type"), ByLocation: discovery.CountBy(items, "location")}
+		return nil, page, len(items), nil
+	}
+
+	if paging {
+		if items, page.TotalCount, page.NextCursor, err = discovery.Page(items, filter, opts.Cursor, opts.PageSize, scope); err != nil {
+			return nil, page, 0, err
 		}
-		if s.ID != nil {
-			info.ID = *s.ID
+	}
+
+	if len(opts.Fields) > 0 {
+		if page.Items, err = discovery.Project(items, opts.Fields); err != nil {
+			return nil, page, 0, err
 		}
-		out.Subscriptions = append(out.Subscriptions, info)
+		return nil, page, len(items), nil
 	}
-	return nil, out, nil
+	return items, page, len(items), nil
 }
 
 // --- list_resource_groups -------------------------------------------------
@@ -79,63 +119,46 @@ func listSubscriptionsHandler(ctx context.Context, _ *mcp.CallToolRequest, in Li
 type ListResourceGroupsInput struct {
 	SubscriptionID string `json:"subscription_id" jsonschema:"Azure subscription UUID to enumerate resource groups in (required)"`
 
-	TenantID     string `json:"tenant_id,omitempty"     jsonschema:"optional service principal tenant UUID"`
-	ClientID     string `json:"client_id,omitempty"     jsonschema:"optional service principal client UUID"`
-	ClientSecret string `json:"client_secret,omitempty" jsonschema:"optional service principal client secret"`
-	BearerToken  string `json:"bearer_token,omitempty"  jsonschema:"optional Azure AD bearer token for https://management.azure.com"`
+	ListOptions
+	CredentialInput
 }
 
-type ResourceGroupInfo struct {
-	Name     string `json:"name"               jsonschema:"resource group name — use as source_resource_group / target_resource_group in validate_move"`
-	ID       string `json:"id"                 jsonschema:"fully qualified ARM ID (/subscriptions/{sub}/resourceGroups/{rg})"`
-	Location string `json:"location,omitempty" jsonschema:"Azure region (e.g. australiaeast, eastus)"`
-}
+type ResourceGroupInfo = discovery.ResourceGroup
 
 type ListResourceGroupsOutput struct {
 	SubscriptionID string              `json:"subscription_id" jsonschema:"subscription that was enumerated"`
-	ResourceGroups []ResourceGroupInfo `json:"resource_groups" jsonschema:"resource groups found in the subscription"`
+	ResourceGroups []ResourceGroupInfo `json:"resource_groups,omitzero" jsonschema:"resource groups found in the subscription; absent when fields or summary was set"`
 	Count          int                 `json:"count"           jsonschema:"number of resource groups returned"`
-}
-
-func listResourceGroupsHandler(ctx context.Context, _ *mcp.CallToolRequest, in ListResourceGroupsInput) (*mcp.CallToolResult, ListResourceGroupsOutput, error) {
-	if err := validateListResourceGroupsInput(in); err != nil {
-		return toolError(err), ListResourceGroupsOutput{}, nil
-	}
-
-	cred, err := selectCredential(in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken)
-	if err != nil {
-		return toolError(err), ListResourceGroupsOutput{}, nil
-	}
 
-	client, err := resourcegroups.GetResourceGroupClient(cred, in.SubscriptionID)
-	if err != nil {
-		return toolError(fmt.Errorf("failed to build resource group client: %w", err)), ListResourceGroupsOutput{}, nil
-	}
+	ListPage
+}
 
-	rgs, err := resourcegroups.ListResourceGroup(ctx, client)
-	if err != nil {
-		return toolError(fmt.Errorf("failed to list resource groups: %w", err)), ListResourceGroupsOutput{}, nil
-	}
+func listResourceGroupsHandler(creds *credentials) mcp.ToolHandlerFor[ListResourceGroupsInput, ListResourceGroupsOutput] {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, in ListResourceGroupsInput) (*mcp.CallToolResult, ListResourceGroupsOutput, error) {
+		filter := discovery.Filter{Text: in.NameContains, Location: in.Location, Tag: in.Tag}
+		scope := "resource_groups|" + in.SubscriptionID
+		if err := validateListResourceGroupsInput(in); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourceGroupsOutput{}, nil
+		}
+		if _, _, _, err := applyListOptions([]ResourceGroupInfo{}, filter, in.ListOptions, scope); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourceGroupsOutput{}, nil
+		}
 
-	out := ListResourceGroupsOutput{
-		SubscriptionID: in.SubscriptionID,
-		ResourceGroups: make([]ResourceGroupInfo, 0, len(rgs)),
-		Count:          len(rgs),
-	}
-	for _, rg := range rgs {
-		info := ResourceGroupInfo{}
-		if rg.Name != nil {
-			info.Name = *rg.Name
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourceGroupsOutput{}, nil
 		}
-		if rg.ID != nil {
-			info.ID = *rg.ID
+
+		rgs, err := discovery.ListResourceGroups(ctx, cred, in.SubscriptionID)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInternal, fmt.Errorf("failed to list resource groups: %w", err))), ListResourceGroupsOutput{}, nil
 		}
-		if rg.Location != nil {
-			info.Location = *rg.Location
+		rgs, page, count, err := applyListOptions(rgs, filter, in.ListOptions, scope)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourceGroupsOutput{}, nil
 		}
-		out.ResourceGroups = append(out.ResourceGroups, info)
+		return nil, ListResourceGroupsOutput{SubscriptionID: in.SubscriptionID, ResourceGroups: rgs, Count: count, ListPage: page}, nil
 	}
-	return nil, out, nil
 }
 
 // --- list_resources -------------------------------------------------------
@@ -144,69 +167,48 @@ type ListResourcesInput struct {
 	SubscriptionID string `json:"subscription_id" jsonschema:"Azure subscription UUID containing the resource group (required)"`
 	ResourceGroup  string `json:"resource_group"  jsonschema:"name of the resource group to enumerate (required)"`
 
-	TenantID     string `json:"tenant_id,omitempty"     jsonschema:"optional service principal tenant UUID"`
-	ClientID     string `json:"client_id,omitempty"     jsonschema:"optional service principal client UUID"`
-	ClientSecret string `json:"client_secret,omitempty" jsonschema:"optional service principal client secret"`
-	BearerToken  string `json:"bearer_token,omitempty"  jsonschema:"optional Azure AD bearer token for https://management.azure.com"`
+	Type string `json:"type,omitempty" jsonschema:"optional resource type, e.g. Microsoft.Web/sites; end with / to match a whole namespace, e.g. Microsoft.Web/"`
+	ListOptions
+	CredentialInput
 }
 
-type ResourceInfo struct {
-	Name     string `json:"name"               jsonschema:"resource name"`
-	Type     string `json:"type,omitempty"     jsonschema:"ARM resource type (e.g. Microsoft.Storage/storageAccounts)"`
-	ID       string `json:"id"                 jsonschema:"fully qualified ARM resource ID — valid input for validate_move"`
-	Location string `json:"location,omitempty" jsonschema:"Azure region"`
-}
+type ResourceInfo = discovery.Resource
 
 type ListResourcesOutput struct {
 	SubscriptionID string         `json:"subscription_id" jsonschema:"subscription that was queried"`
 	ResourceGroup  string         `json:"resource_group"  jsonschema:"resource group that was enumerated"`
-	Resources      []ResourceInfo `json:"resources"       jsonschema:"resources in the resource group"`
+	Resources      []ResourceInfo `json:"resources,omitzero" jsonschema:"resources in the resource group; absent when fields or summary was set"`
 	Count          int            `json:"count"           jsonschema:"number of resources returned"`
-}
-
-func listResourcesHandler(ctx context.Context, _ *mcp.CallToolRequest, in ListResourcesInput) (*mcp.CallToolResult, ListResourcesOutput, error) {
-	if err := validateListResourcesInput(in); err != nil {
-		return toolError(err), ListResourcesOutput{}, nil
-	}
 
-	cred, err := selectCredential(in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken)
-	if err != nil {
-		return toolError(err), ListResourcesOutput{}, nil
-	}
-
-	client, err := resources.GetResourcesClient(cred, in.SubscriptionID)
-	if err != nil {
-		return toolError(fmt.Errorf("failed to build resources client: %w", err)), ListResourcesOutput{}, nil
-	}
-
-	items, err := resources.GetResources(ctx, client, in.ResourceGroup)
-	if err != nil {
-		return toolError(fmt.Errorf("failed to list resources: %w", err)), ListResourcesOutput{}, nil
-	}
+	ListPage
+}
 
-	out := ListResourcesOutput{
-		SubscriptionID: in.SubscriptionID,
-		ResourceGroup:  in.ResourceGroup,
-		Resources:      make([]ResourceInfo, 0, len(items)),
-		Count:          len(items),
-	}
-	for _, r := range items {
-		info := ResourceInfo{}
-		if r.Name != nil {
-			info.Name = *r.Name
+func listResourcesHandler(creds *credentials) mcp.ToolHandlerFor[ListResourcesInput, ListResourcesOutput] {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, in ListResourcesInput) (*mcp.CallToolResult, ListResourcesOutput, error) {
+		filter := discovery.Filter{Text: in.NameContains, Type: in.Type, Location: in.Location, Tag: in.Tag}
+		scope := "resources|" + in.SubscriptionID + "|" + strings.ToLower(in.ResourceGroup)
+		if err := validateListResourcesInput(in); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourcesOutput{}, nil
 		}
-		if r.Type != nil {
-			info.Type = *r.Type
+		if _, _, _, err := applyListOptions([]ResourceInfo{}, filter, in.ListOptions, scope); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourcesOutput{}, nil
 		}
-		if r.ID != nil {
-			info.ID = *r.ID
+
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourcesOutput{}, nil
+		}
+
+		items, err := discovery.ListResources(ctx, cred, in.SubscriptionID, in.ResourceGroup)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInternal, fmt.Errorf("failed to list resources: %w", err))), ListResourcesOutput{}, nil
 		}
-		if r.Location != nil {
-			info.Location = *r.Location
+		items, page, count, err := applyListOptions(items, filter, in.ListOptions, scope)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ListResourcesOutput{}, nil
 		}
-		out.Resources = append(out.Resources, info)
+		return nil, ListResourcesOutput{SubscriptionID: in.SubscriptionID, ResourceGroup: in.ResourceGroup, Resources: items, Count: count, ListPage: page}, nil
 	}
-	return nil, out, nil
 }
 
 func validateListResourcesInput(in ListResourcesInput) error {
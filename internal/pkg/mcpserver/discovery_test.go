@@ -1,10 +1,13 @@
-//go:build ignore
-
 package mcpserver
 
 import (
+	"errors"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
 )
 
 func TestValidateListResourcesInput(t *testing.T) {
@@ -113,8 +116,81 @@ func TestValidateListResourceGroupsInput(t *testing.T) {
 // well-formed (valid json/jsonschema tags, no conflicting field names, etc.).
 // newServer() exercises the same path that Run() would at startup.
 func TestDiscoveryInputsShareAuthFields(t *testing.T) {
-	s := newServer("test-version")
+	s := newServer("test-version", Options{ReportDir: t.TempDir()})
 	if s == nil {
 		t.Fatal("newServer returned nil — discovery tool registration likely panicked")
 	}
 }
+
+var listTestResources = []ResourceInfo{
+	{Name: "web", Type: "Microsoft.Web/sites", Location: "australiaeast", ID: "/r/web", Tags: map[string]string{"env": "prod"}},
+	{Name: "api", Type: "Microsoft.Web/sites", Location: "australiaeast", ID: "/r/api", Tags: map[string]string{"env": "prod"}},
+	{Name: "plan", Type: "Microsoft.Web/serverFarms", Location: "eastus", ID: "/r/plan"},
+	{Name: "store", Type: "Microsoft.Storage/storageAccounts", Location: "australiaeast", ID: "/r/store"},
+}
+
+func TestApplyListOptionsWithoutOptionsKeepsEveryRecord(t *testing.T) {
+	items, page, count, err := applyListOptions(listTestResources, discovery.Filter{}, ListOptions{}, "scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(listTestResources) || len(items) != len(listTestResources) || items[0].Name != "web" {
+		t.Errorf("items = %v, count = %d; want all records in their original order", items, count)
+	}
+	if !reflect.DeepEqual(page, ListPage{}) {
+		t.Errorf("page = %+v, want zero", page)
+	}
+}
+
+func TestApplyListOptionsPagesAndProjects(t *testing.T) {
+	filter := discovery.Filter{Location: "australiaeast"}
+	opts := ListOptions{PageSize: 2, Fields: []string{"name"}}
+
+	var names []string
+	for {
+		items, page, count, err := applyListOptions(listTestResources, filter, opts, "scope")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if items != nil {
+			t.Errorf("records returned alongside a projection: %v", items)
+		}
+		if page.TotalCount != 3 || count != len(page.Items) {
+			t.Errorf("total_count = %d, count = %d for %d items", page.TotalCount, count, len(page.Items))
+		}
+		for _, item := range page.Items {
+			names = append(names, item["name"].(string))
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if want := []string{"api", "store", "web"}; !slices.Equal(names, want) {
+		t.Errorf("paged names = %v, want %v", names, want)
+	}
+
+	// A cursor only fits the filters it was issued for.
+	filter.Location = "eastus"
+	if _, _, _, err := applyListOptions(listTestResources, filter, opts, "scope"); !errors.Is(err, discovery.ErrInvalidCursor) {
+		t.Errorf("cursor reused with another filter: err = %v", err)
+	}
+}
+
+func TestApplyListOptionsSummary(t *testing.T) {
+	items, page, count, err := applyListOptions(listTestResources, discovery.Filter{Tag: "env=prod"}, ListOptions{Summary: true}, "scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ListSummary{Total: 2, ByType: map[string]int{"Microsoft.Web/sites": 2}, ByLocation: map[string]int{"australiaeast": 2}}
+	if items != nil || count != 2 || !reflect.DeepEqual(page.Summary, want) {
+		t.Errorf("summary = (%v, %+v, %d), want %+v", items, page.Summary, count, want)
+	}
+
+	if _, _, _, err := applyListOptions([]ResourceInfo{}, discovery.Filter{}, ListOptions{Summary: true, PageSize: 10}, "scope"); err == nil {
+		t.Error("summary with page_size accepted")
+	}
+	if _, _, _, err := applyListOptions([]ResourceGroupInfo{}, discovery.Filter{}, ListOptions{Fields: []string{"type"}}, "scope"); err == nil {
+		t.Error("type field accepted for resource groups")
+	}
+}
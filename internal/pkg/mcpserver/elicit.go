@@ -0,0 +1,253 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxElicitChoices caps the options offered in one question. A longer
+	// list is no easier to pick from than typing the name, so the call falls
+	// back to the usual error and the agent can filter with the list tools.
+	maxElicitChoices = 100
+
+	// multiRoundTripVersion is the first MCP protocol version in which a tool
+	// asks the client for input by returning InputRequests and being called
+	// again with the answers, instead of sending elicitation/create while the
+	// call is open.
+	multiRoundTripVersion = "2026-07-28"
+)
+
+// elicitor completes validate_move and start_validation arguments by asking
+// the user, through MCP elicitation, to pick from what discovery finds: a
+// missing target subscription or target resource group, or a resource group
+// name that matches several groups differing only in case. The listers are
+// the discovery functions, replaced in tests.
+type elicitor struct {
+	listSubscriptions  func(ctx context.Context, cred azcore.TokenCredential) ([]discovery.Subscription, error)
+	listResourceGroups func(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) ([]discovery.ResourceGroup, error)
+}
+
+func newElicitor() *elicitor {
+	return &elicitor{listSubscriptions: discovery.ListSubscriptions, listResourceGroups: discovery.ListResourceGroups}
+}
+
+// question asks the user to pick the value of one input field.
+type question struct {
+	field  string
+	params *mcp.ElicitParams
+}
+
+// choice is one option offered to the user.
+type choice struct {
+	value, title string
+}
+
+// complete fills the gaps in in by asking the user. It returns the completed
+// input, or, for a client on the multi round-trip protocol, a result
+// carrying the next question: the tool returns it as is and is called again
+// with the answer. The error is for a user who declined to answer.
+//
+// When the client cannot elicit, or discovery fails or finds nothing to
+// offer, in is returned unchanged, so validateInputs and Azure report the
+// problem as they would without elicitation.
+func (e *elicitor) complete(ctx context.Context, req *mcp.CallToolRequest, cred azcore.TokenCredential, in ValidateMoveInput) (ValidateMoveInput, *mcp.CallToolResult, error) {
+	if !canElicit(req) {
+		return in, nil, nil
+	}
+	answers, err := previousAnswers(req)
+	if err != nil {
+		return in, nil, err
+	}
+	for field, value := range answers {
+		in.set(field, value)
+	}
+
+	groups := map[string][]discovery.ResourceGroup{}
+	for {
+		q := e.next(ctx, cred, in, answers, groups)
+		if q == nil {
+			return in, nil, nil
+		}
+		if multiRoundTrip(req) {
+			return in, &mcp.CallToolResult{InputRequests: mcp.InputRequestMap{q.field: q.params}, RequestState: encodeAnswers(answers)}, nil
+		}
+		res, err := req.Session.Elicit(ctx, q.params)
+		if err != nil {
+			return in, nil, nil
+		}
+		value, err := answer(q.field, res)
+		if err != nil {
+			return in, nil, err
+		}
+		answers[q.field] = value
+		in.set(q.field, value)
+	}
+}
+
+// next returns the next question to ask about in, or nil if there is none.
+// Fields in answers are settled and never asked about again; groups caches
+// the resource group listing of each subscription.
+func (e *elicitor) next(ctx context.Context, cred azcore.TokenCredential, in ValidateMoveInput, answers map[string]string, groups map[string][]discovery.ResourceGroup) *question {
+	if _, ok := answers["target_subscription_id"]; !ok && strings.TrimSpace(in.TargetSubscriptionID) == "" {
+		subs, err := e.listSubscriptions(ctx, cred)
+		if err != nil {
+			return nil
+		}
+		choices := make([]choice, len(subs))
+		for i, s := range subs {
+			choices[i] = choice{value: s.SubscriptionID, title: titled(s.DisplayName, s.SubscriptionID)}
+		}
+		return ask("target_subscription_id", "Which subscription should the resources move to?", choices)
+	}
+
+	list := func(subscriptionID string) []discovery.ResourceGroup {
+		key := strings.ToLower(subscriptionID)
+		if rgs, ok := groups[key]; ok {
+			return rgs
+		}
+		rgs, _ := e.listResourceGroups(ctx, cred, subscriptionID)
+		groups[key] = rgs
+		return rgs
+	}
+	for _, g := range []struct {
+		field, subscriptionID, name string
+	}{
+		{"source_resource_group", in.SourceSubscriptionID, in.SourceResourceGroup},
+		{"target_resource_group", in.TargetSubscriptionID, in.TargetResourceGroup},
+	} {
+		if _, ok := answers[g.field]; ok || g.subscriptionID == "" || (g.name == "" && g.field != "target_resource_group") {
+			continue
+		}
+		var choices []choice
+		for _, rg := range list(g.subscriptionID) {
+			if g.name == "" || strings.EqualFold(rg.Name, g.name) {
+				choices = append(choices, choice{value: rg.Name, title: titled(rg.Name, rg.Location)})
+			}
+		}
+		if g.name == "" {
+			if q := ask(g.field, fmt.Sprintf("Which resource group in subscription %s should the resources move to?", g.subscriptionID), choices); q != nil {
+				return q
+			}
+		} else if len(choices) > 1 {
+			return ask(g.field, fmt.Sprintf("Subscription %s has %d resource groups named %q in different case. Which one did you mean for %s?", g.subscriptionID, len(choices), g.name, g.field), choices)
+		}
+	}
+	return nil
+}
+
+// ask builds the question for field, or returns nil when there is nothing
+// sensible to offer.
+func ask(field, message string, choices []choice) *question {
+	if len(choices) == 0 || len(choices) > maxElicitChoices {
+		return nil
+	}
+	options := make([]*jsonschema.Schema, len(choices))
+	for i, c := range choices {
+		options[i] = &jsonschema.Schema{Const: new(any(c.value)), Title: c.title}
+	}
+	return &question{field: field, params: &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{field: {Type: "string", Title: field, OneOf: options}},
+			Required:   []string{field},
+		},
+	}}
+}
+
+// answer returns the value the user picked for field, or an input error
+// when they declined.
+func answer(field string, res *mcp.ElicitResult) (string, error) {
+	if res == nil || res.Action != "accept" {
+		action := "no answer"
+		if res != nil {
+			action = res.Action
+		}
+		return "", validator.NewError(validator.KindInvalidInput, fmt.Errorf("%s is required: the user was asked to choose one and did not (%s)", field, action))
+	}
+	value, _ := res.Content[field].(string)
+	if value == "" {
+		return "", validator.NewError(validator.KindInvalidInput, fmt.Errorf("%s is required: the user's answer held none", field))
+	}
+	return value, nil
+}
+
+// previousAnswers returns the answers given in earlier rounds of a multi
+// round-trip call: those carried in its request state plus the ones it was
+// called again with. The state only holds what the client could as well
+// have passed as arguments, so it is not signed.
+func previousAnswers(req *mcp.CallToolRequest) (map[string]string, error) {
+	answers := map[string]string{}
+	if state := req.Params.RequestState; state != "" {
+		data, err := base64.RawURLEncoding.DecodeString(state)
+		if err == nil {
+			err = json.Unmarshal(data, &answers)
+		}
+		if err != nil {
+			return nil, validator.NewError(validator.KindInvalidInput, fmt.Errorf("invalid request state: %w", err))
+		}
+	}
+	for field, res := range req.Params.InputResponses {
+		er, _ := res.(*mcp.ElicitResult)
+		value, err := answer(field, er)
+		if err != nil {
+			return nil, err
+		}
+		answers[field] = value
+	}
+	return answers, nil
+}
+
+func encodeAnswers(answers map[string]string) string {
+	data, _ := json.Marshal(answers)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// set assigns the field complete asks about.
+func (in *ValidateMoveInput) set(field, value string) {
+	switch field {
+	case "target_subscription_id":
+		in.TargetSubscriptionID = value
+	case "source_resource_group":
+		in.SourceResourceGroup = value
+	case "target_resource_group":
+		in.TargetResourceGroup = value
+	}
+}
+
+// canElicit reports whether the client calling req accepts elicitation.
+func canElicit(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// multiRoundTrip reports whether the client asks for input by multi round
+// trip rather than being sent elicitation requests.
+func multiRoundTrip(req *mcp.CallToolRequest) bool {
+	return req.Session.InitializeParams().ProtocolVersion >= multiRoundTripVersion
+}
+
+// titled returns "name (detail)", leaving out whichever part is empty.
+func titled(name, detail string) string {
+	switch {
+	case name == "":
+		return detail
+	case detail == "":
+		return name
+	default:
+		return name + " (" + detail + ")"
+	}
+}
@@ -0,0 +1,182 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	elicitSourceSub = "11111111-1111-1111-1111-111111111111"
+	elicitTargetSub = "22222222-2222-2222-2222-222222222222"
+)
+
+// fakeElicitor returns an elicitor listing two subscriptions; the target
+// subscription holds case variants of rg-dst.
+func fakeElicitor(listed *int) *elicitor {
+	return &elicitor{
+		listSubscriptions: func(context.Context, azcore.TokenCredential) ([]discovery.Subscription, error) {
+			*listed++
+			return []discovery.Subscription{
+				{SubscriptionID: elicitSourceSub, DisplayName: "Source"},
+				{SubscriptionID: elicitTargetSub, DisplayName: "Target"},
+			}, nil
+		},
+		listResourceGroups: func(_ context.Context, _ azcore.TokenCredential, sub string) ([]discovery.ResourceGroup, error) {
+			*listed++
+			if sub == elicitTargetSub {
+				return []discovery.ResourceGroup{{Name: "rg-dst", Location: "eastus"}, {Name: "RG-DST", Location: "westus"}, {Name: "rg-other"}}, nil
+			}
+			return []discovery.ResourceGroup{{Name: "rg-src"}}, nil
+		},
+	}
+}
+
+// connectElicit runs e.complete as a tool on a server whose client, on
+// protocol version (the latest if empty), answers questions with answer, or
+// cannot elicit when answer is nil. It returns the completed input, or the
+// tool error text.
+func connectElicit(t *testing.T, e *elicitor, version string, answer func(*mcp.ElicitRequest) *mcp.ElicitResult, in ValidateMoveInput) (ValidateMoveInput, string) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "complete"}, func(ctx context.Context, req *mcp.CallToolRequest, in ValidateMoveInput) (*mcp.CallToolResult, ValidateMoveInput, error) {
+		out, pending, err := e.complete(ctx, req, nil, in)
+		if err != nil {
+			return toolError(err), ValidateMoveInput{}, nil
+		}
+		return pending, out, nil
+	})
+	var opts *mcp.ClientOptions
+	if answer != nil {
+		opts = &mcp.ClientOptions{ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return answer(req), nil
+		}}
+	}
+	cs := connectInMemory(t, t.Context(), server, opts, &mcp.ClientSessionOptions{ProtocolVersion: version})
+
+	res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "complete", Arguments: in})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if res.IsError {
+		return ValidateMoveInput{}, res.Content[0].(*mcp.TextContent).Text
+	}
+	var out ValidateMoveInput
+	data, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out, ""
+}
+
+// pick answers every elicitation with the option whose title contains want.
+func pick(t *testing.T, asked *[]string, want string) func(*mcp.ElicitRequest) *mcp.ElicitResult {
+	return func(req *mcp.ElicitRequest) *mcp.ElicitResult {
+		*asked = append(*asked, req.Params.Message)
+		schema := req.Params.RequestedSchema.(map[string]any)
+		for field, p := range schema["properties"].(map[string]any) {
+			for _, o := range p.(map[string]any)["oneOf"].([]any) {
+				opt := o.(map[string]any)
+				if strings.Contains(opt["title"].(string), want) {
+					return &mcp.ElicitResult{Action: "accept", Content: map[string]any{field: opt["const"]}}
+				}
+			}
+		}
+		t.Errorf("no option titled %q in %v", want, schema)
+		return &mcp.ElicitResult{Action: "cancel"}
+	}
+}
+
+func TestElicitMissingTarget(t *testing.T) {
+	// The latest protocol asks by multi round trip, older ones by
+	// elicitation requests sent during the call.
+	for name, version := range map[string]string{"latest": "", "2025-11-25": "2025-11-25"} {
+		t.Run(name, func(t *testing.T) {
+			testElicitMissingTarget(t, version)
+		})
+	}
+}
+
+func testElicitMissingTarget(t *testing.T, version string) {
+	var listed int
+	var asked []string
+	answers := []string{"Target", "westus"}
+	answer := func(req *mcp.ElicitRequest) *mcp.ElicitResult {
+		a := answers[len(asked)]
+		return pick(t, &asked, a)(req)
+	}
+	out, errText := connectElicit(t, fakeElicitor(&listed), version, answer, ValidateMoveInput{SourceSubscriptionID: elicitSourceSub, SourceResourceGroup: "rg-src"})
+	if errText != "" {
+		t.Fatalf("complete failed: %s", errText)
+	}
+	if out.TargetSubscriptionID != elicitTargetSub || out.TargetResourceGroup != "RG-DST" {
+		t.Errorf("completed target = %s/%s, want %s/RG-DST", out.TargetSubscriptionID, out.TargetResourceGroup, elicitTargetSub)
+	}
+	if len(asked) != 2 || !strings.Contains(asked[0], "subscription") || !strings.Contains(asked[1], elicitTargetSub) {
+		t.Errorf("asked %q", asked)
+	}
+}
+
+func TestElicitAmbiguousGroup(t *testing.T) {
+	var listed int
+	var asked []string
+	in := ValidateMoveInput{SourceSubscriptionID: elicitSourceSub, SourceResourceGroup: "rg-src", TargetSubscriptionID: elicitTargetSub, TargetResourceGroup: "Rg-Dst"}
+	out, errText := connectElicit(t, fakeElicitor(&listed), "", pick(t, &asked, "eastus"), in)
+	if errText != "" {
+		t.Fatalf("complete failed: %s", errText)
+	}
+	if out.TargetResourceGroup != "rg-dst" || out.SourceResourceGroup != "rg-src" {
+		t.Errorf("completed groups = %s -> %s", out.SourceResourceGroup, out.TargetResourceGroup)
+	}
+	if len(asked) != 1 || !strings.Contains(asked[0], `"Rg-Dst"`) {
+		t.Errorf("asked %q, want one question about the case variants", asked)
+	}
+
+	// A unique name is left alone without asking.
+	asked = nil
+	in.TargetResourceGroup = "RG-OTHER"
+	if out, _ := connectElicit(t, fakeElicitor(&listed), "", pick(t, &asked, "x"), in); out.TargetResourceGroup != "RG-OTHER" || len(asked) != 0 {
+		t.Errorf("unique group: got %q after asking %q", out.TargetResourceGroup, asked)
+	}
+}
+
+func TestElicitDeclined(t *testing.T) {
+	var listed int
+	decline := func(*mcp.ElicitRequest) *mcp.ElicitResult { return &mcp.ElicitResult{Action: "decline"} }
+	_, errText := connectElicit(t, fakeElicitor(&listed), "", decline, ValidateMoveInput{SourceSubscriptionID: elicitSourceSub, SourceResourceGroup: "rg-src", TargetSubscriptionID: elicitTargetSub})
+	if !strings.Contains(errText, "[invalid_input] target_resource_group is required") {
+		t.Errorf("error = %q, want an invalid_input error naming target_resource_group", errText)
+	}
+}
+
+func TestElicitFallsBack(t *testing.T) {
+	in := ValidateMoveInput{SourceSubscriptionID: elicitSourceSub, SourceResourceGroup: "rg-src"}
+
+	t.Run("client without elicitation", func(t *testing.T) {
+		var listed int
+		out, errText := connectElicit(t, fakeElicitor(&listed), "", nil, in)
+		if errText != "" || out.TargetSubscriptionID != "" || out.TargetResourceGroup != "" {
+			t.Errorf("complete = %+v, %q; want the input unchanged", out, errText)
+		}
+		if listed != 0 {
+			t.Errorf("listed Azure %d times for a client that cannot be asked", listed)
+		}
+	})
+
+	t.Run("discovery fails", func(t *testing.T) {
+		e := &elicitor{listSubscriptions: func(context.Context, azcore.TokenCredential) ([]discovery.Subscription, error) {
+			return nil, errors.New("forbidden")
+		}}
+		var asked []string
+		out, errText := connectElicit(t, e, "", pick(t, &asked, "x"), in)
+		if errText != "" || out.TargetSubscriptionID != "" || len(asked) != 0 {
+			t.Errorf("complete = %+v, %q after asking %q; want the input unchanged", out, errText, asked)
+		}
+	})
+}
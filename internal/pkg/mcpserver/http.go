@@ -0,0 +1,100 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/httpserve"
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// EndpointPath is where the streamable HTTP transport is served.
+	EndpointPath = "/mcp"
+	// HealthPath answers unauthenticated liveness probes.
+	HealthPath = "/healthz"
+
+	// MinTokenLength rejects bearer tokens short enough to guess.
+	MinTokenLength = 16
+)
+
+// ErrWeakToken is returned when the HTTP bearer token is shorter than
+// MinTokenLength.
+var ErrWeakToken = fmt.Errorf("mcpserver: bearer token must be at least %d characters", MinTokenLength)
+
+// HTTPOptions configures ServeHTTP.
+type HTTPOptions struct {
+	// Addr is the listen address, e.g. ":8080".
+	Addr string
+	// Token is the shared bearer token every request to EndpointPath must
+	// carry. It is required: the tools act with the server's Azure
+	// credential, so an open endpoint would lend it to anyone.
+	Token string
+	// Logger receives transport and lifecycle logs; nil means slog.Default.
+	Logger *slog.Logger
+	// Server tunes the tools, as for Run.
+	Server Options
+}
+
+// NewHTTPHandler returns the HTTP handler for the streamable transport at
+// EndpointPath, guarded by opts.Token, plus the HealthPath probe and the
+// Prometheus metrics at metrics.Path. It turns on ARM request metrics for
+// the process. opts.Addr is ignored.
+func NewHTTPHandler(version string, opts HTTPOptions) (http.Handler, error) {
+	token := opts.Token
+	if len(token) < MinTokenLength {
+		return nil, ErrWeakToken
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	server := newServer(version, opts.Server)
+	streamable := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, &mcp.StreamableHTTPOptions{
+		Logger: logger,
+	})
+	requireToken := auth.RequireBearerToken(tokenVerifier(token), &auth.RequireBearerTokenOptions{AllowMissingExpiration: true})
+
+	mux := http.NewServeMux()
+	mux.Handle(EndpointPath, requireToken(streamable))
+	mux.HandleFunc("GET "+HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("GET "+metrics.Path, metrics.Handler())
+	pipeline.EnableMetrics()
+	return mux, nil
+}
+
+// tokenVerifier accepts exactly token, compared in constant time. The token
+// is a shared secret, not a JWT, so it carries no expiry or scopes.
+func tokenVerifier(token string) auth.TokenVerifier {
+	return func(_ context.Context, presented string, _ *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			return nil, fmt.Errorf("%w: bearer token not recognised", auth.ErrInvalidToken)
+		}
+		return &auth.TokenInfo{}, nil
+	}
+}
+
+// ServeHTTP serves MCP over streamable HTTP on opts.Addr until ctx is
+// cancelled, then shuts down gracefully, letting in-flight requests finish
+// for up to httpserve.ShutdownTimeout. TLS is expected to be terminated in
+// front of the server (ingress, sidecar or load balancer).
+func ServeHTTP(ctx context.Context, version string, opts HTTPOptions) error {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	handler, err := NewHTTPHandler(version, opts)
+	if err != nil {
+		return err
+	}
+	return httpserve.Serve(ctx, "mcpserver", opts.Addr, handler, opts.Logger, "transport", "streamable HTTP", "path", EndpointPath)
+}
@@ -0,0 +1,126 @@
+package mcpserver
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const testToken = "0123456789abcdef-test-token"
+
+// bearerTransport adds the Authorization header to every request.
+type bearerTransport struct{ token string }
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newTestHTTPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	handler, err := NewHTTPHandler("test", HTTPOptions{Token: testToken, Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), Server: Options{ReportDir: t.TempDir()}})
+	if err != nil {
+		t.Fatalf("NewHTTPHandler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPRejectsMissingOrWrongToken(t *testing.T) {
+	srv := newTestHTTPServer(t)
+
+	for name, header := range map[string]string{
+		"no header":   "",
+		"wrong token": "Bearer not-the-token-at-all",
+		"wrong type":  "Basic " + testToken,
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, srv.URL+EndpointPath, nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHTTPHealthNeedsNoToken(t *testing.T) {
+	srv := newTestHTTPServer(t)
+
+	resp, err := http.Get(srv.URL + HealthPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestHTTPMetricsNeedNoToken(t *testing.T) {
+	srv := newTestHTTPServer(t)
+
+	resp, err := http.Get(srv.URL + metrics.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("status = %d, content type %q, want 200 and %q", resp.StatusCode, resp.Header.Get("Content-Type"), metrics.ContentType)
+	}
+	if !strings.Contains(string(body), "# TYPE armv_jobs_in_flight gauge") {
+		t.Errorf("metrics do not include the jobs gauge:\n%s", body)
+	}
+}
+
+// TestHTTPClientListsTools connects a real MCP client over streamable HTTP
+// with the token and checks the same tools are served as over stdio.
+func TestHTTPClientListsTools(t *testing.T) {
+	srv := newTestHTTPServer(t)
+	ctx := t.Context()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:             srv.URL + EndpointPath,
+		HTTPClient:           &http.Client{Transport: bearerTransport{token: testToken}},
+		DisableStandaloneSSE: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer cs.Close()
+
+	found := false
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Tools: %v", err)
+		}
+		found = found || tool.Name == "validate_move"
+	}
+	if !found {
+		t.Error("validate_move not advertised over HTTP")
+	}
+}
+
+func TestNewHTTPHandlerRejectsWeakToken(t *testing.T) {
+	if _, err := NewHTTPHandler("test", HTTPOptions{Token: "short"}); !errors.Is(err, ErrWeakToken) {
+		t.Errorf("NewHTTPHandler(short token) error = %v, want ErrWeakToken", err)
+	}
+}
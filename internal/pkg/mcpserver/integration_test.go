@@ -1,5 +1,3 @@
-//go:build ignore
-
 package mcpserver
 
 import (
@@ -16,13 +14,18 @@ import (
 // schema inference panics in AddTool and accidental tool removal from newServer.
 func TestIntegration_ToolsListAdvertisesAllTools(t *testing.T) {
 	ctx := t.Context()
-	cs := connectInMemory(t, ctx)
+	cs := connectInMemory(t, ctx, newTestServer(t), nil, nil)
 
 	want := map[string]bool{
-		"validate_move":        false,
-		"list_subscriptions":   false,
-		"list_resource_groups": false,
-		"list_resources":       false,
+		"validate_move":         false,
+		"list_subscriptions":    false,
+		"list_resource_groups":  false,
+		"list_resources":        false,
+		"resume_validation":     false,
+		"start_validation":      false,
+		"get_validation_status": false,
+		"get_validation_result": false,
+		"cancel_validation":     false,
 	}
 
 	for tool, err := range cs.Tools(ctx, nil) {
@@ -50,6 +53,33 @@ func TestIntegration_ToolsListAdvertisesAllTools(t *testing.T) {
 	}
 }
 
+// TestIntegration_ToolAnnotations checks every tool carries behaviour hints,
+// so clients can auto-approve the read-only ones.
+func TestIntegration_ToolAnnotations(t *testing.T) {
+	ctx := t.Context()
+	cs := connectInMemory(t, ctx, newTestServer(t), nil, nil)
+
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("error iterating tools: %v", err)
+		}
+		a := tool.Annotations
+		if a == nil {
+			t.Errorf("tool %q has no annotations", tool.Name)
+			continue
+		}
+		if want := tool.Name != "cancel_validation"; a.ReadOnlyHint != want {
+			t.Errorf("tool %q readOnlyHint = %v, want %v", tool.Name, a.ReadOnlyHint, want)
+		}
+		if !a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint) {
+			t.Errorf("tool %q must declare destructiveHint false", tool.Name)
+		}
+		if a.OpenWorldHint == nil {
+			t.Errorf("tool %q has no openWorldHint", tool.Name)
+		}
+	}
+}
+
 // TestIntegration_ValidateMoveRejectsBadInput confirms the SDK's schema validation
 // catches malformed input before our handler runs. "abc" is not a UUID, so this
 // must come back as a tool-result with IsError=true, not a transport-level error.
@@ -57,7 +87,7 @@ func TestIntegration_ToolsListAdvertisesAllTools(t *testing.T) {
 // IsError=true — either way the client sees a clear failure.)
 func TestIntegration_ValidateMoveRejectsBadInput(t *testing.T) {
 	ctx := t.Context()
-	cs := connectInMemory(t, ctx)
+	cs := connectInMemory(t, ctx, newTestServer(t), nil, nil)
 
 	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
 		Name: "validate_move",
@@ -76,13 +106,38 @@ func TestIntegration_ValidateMoveRejectsBadInput(t *testing.T) {
 	}
 }
 
+// TestIntegration_ValidateMoveWithoutTargetFallsBack checks the schema lets
+// the target be left out for elicitation, and that a client which cannot
+// elicit still gets the plain input error.
+func TestIntegration_ValidateMoveWithoutTargetFallsBack(t *testing.T) {
+	ctx := t.Context()
+	cs := connectInMemory(t, ctx, newTestServer(t), nil, nil)
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name: "validate_move",
+		Arguments: map[string]any{
+			"source_subscription_id": "11111111-1111-1111-1111-111111111111",
+			"source_resource_group":  "rg-src",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CallTool returned transport-level error, wanted an IsError result: %v", err)
+	}
+	if !res.IsError {
+		t.Fatalf("expected IsError=true without a target, got %+v", res.Content)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "[invalid_input] invalid target_subscription_id") {
+		t.Errorf("error = %q, want the invalid_input error for target_subscription_id", text)
+	}
+}
+
 // TestIntegration_ListResourcesRejectsMissingResourceGroup confirms the handler's
 // post-schema validator (validateListResourcesInput) fires when the SDK can't
 // catch the problem — resource_group is a plain string, so the schema accepts
 // an empty value; our helper must reject it.
 func TestIntegration_ListResourcesRejectsMissingResourceGroup(t *testing.T) {
 	ctx := t.Context()
-	cs := connectInMemory(t, ctx)
+	cs := connectInMemory(t, ctx, newTestServer(t), nil, nil)
 
 	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
 		Name: "list_resources",
@@ -112,20 +167,26 @@ func TestIntegration_ListResourcesRejectsMissingResourceGroup(t *testing.T) {
 	}
 }
 
-// connectInMemory stands up newServer() on one end of an in-memory transport
-// pair and returns a connected ClientSession.
-func connectInMemory(t *testing.T, ctx context.Context) *mcp.ClientSession {
+// newTestServer returns newServer() with its reports kept in a temp dir.
+func newTestServer(t *testing.T) *mcp.Server {
+	t.Helper()
+	return newServer("test-version", Options{ReportDir: t.TempDir()})
+}
+
+// connectInMemory stands up server on one end of an in-memory transport
+// pair and returns a ClientSession connected to it with the client options
+// and session options given (either may be nil).
+func connectInMemory(t *testing.T, ctx context.Context, server *mcp.Server, opts *mcp.ClientOptions, sessionOpts *mcp.ClientSessionOptions) *mcp.ClientSession {
 	t.Helper()
 
-	server := newServer("test-version")
 	t1, t2 := mcp.NewInMemoryTransports()
 
 	if _, err := server.Connect(ctx, t1, nil); err != nil {
 		t.Fatalf("server.Connect failed: %v", err)
 	}
 
-	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
-	cs, err := client.Connect(ctx, t2, nil)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, opts)
+	cs, err := client.Connect(ctx, t2, sessionOpts)
 	if err != nil {
 		t.Fatalf("client.Connect failed: %v", err)
 	}
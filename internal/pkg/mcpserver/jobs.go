@@ -0,0 +1,192 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/jobs"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultMaxJobs is how many start_validation jobs poll Azure at once.
+	DefaultMaxJobs = jobs.DefaultMaxRunning
+	// DefaultJobRetention is how long a finished job's result stays available.
+	DefaultJobRetention = jobs.DefaultRetention
+
+	// cancelWait is how long cancel_validation waits for the job to stop.
+	cancelWait = 5 * time.Second
+)
+
+// JobState is where a validation job is in its lifecycle.
+type JobState = jobs.State
+
+const (
+	JobQueued    = jobs.Queued
+	JobRunning   = jobs.Running
+	JobCompleted = jobs.Completed
+	JobFailed    = jobs.Failed
+	JobCancelled = jobs.Cancelled
+)
+
+// JobIDInput names the job for get_validation_status, get_validation_result
+// and cancel_validation.
+type JobIDInput struct {
+	JobID string `json:"job_id" jsonschema:"job_id returned by start_validation (required)"`
+}
+
+// JobStatus is the state of a validation job, as returned by start_validation,
+// get_validation_status and cancel_validation.
+type JobStatus struct {
+	JobID          string   `json:"job_id"                  jsonschema:"identifier to pass to the other job tools"`
+	State          JobState `json:"state"                   jsonschema:"queued, running, completed, failed or cancelled; completed means Azure answered, check get_validation_result for whether the move is valid"`
+	Message        string   `json:"message,omitempty"       jsonschema:"latest progress message, e.g. the polling phase"`
+	Error          string   `json:"error,omitempty"         jsonschema:"why the job failed or was cancelled, prefixed with the error category"`
+	ResumeID       string   `json:"resume_id,omitempty"     jsonschema:"resume_id for resume_validation if the job is lost, e.g. by a server restart"`
+	CreatedAt      string   `json:"created_at"              jsonschema:"RFC 3339 time the job was submitted"`
+	FinishedAt     string   `json:"finished_at,omitempty"   jsonschema:"RFC 3339 time the job finished"`
+	ElapsedSeconds float64  `json:"elapsed_seconds"         jsonschema:"seconds since the job was submitted, or its total run time once finished"`
+	ExpiresAt      string   `json:"expires_at,omitempty"    jsonschema:"RFC 3339 time after which a finished job and its result are discarded"`
+}
+
+// job is one submitted validation.
+type job = jobs.Job[struct{}, ValidateMoveOutput]
+
+// jobFunc runs one validation. notify records progress messages and
+// resumeID records where the operation's resume state was saved.
+type jobFunc = jobs.Func[ValidateMoveOutput]
+
+// jobManager runs validations in the background for the asynchronous job
+// tools, on the queue shared with the REST API (see the jobs package).
+// Jobs validate through runner, so they share its deduplication, cache and
+// concurrency limit with validate_move.
+type jobManager struct {
+	*jobs.Manager[struct{}, ValidateMoveOutput]
+	runner *validationRunner
+}
+
+func newJobManager(maxRunning int, retention time.Duration, runner *validationRunner) *jobManager {
+	return &jobManager{Manager: jobs.NewManager[struct{}, ValidateMoveOutput]("job-", maxRunning, retention), runner: runner}
+}
+
+// submit queues run as a new job and returns it. The job's context is
+// independent of the submitting request, so it outlives the tool call.
+func (m *jobManager) submit(run jobFunc) (*job, error) {
+	j, err := m.Submit(struct{}{}, run)
+	if err != nil {
+		return nil, validator.NewError(validator.KindInvalidInput, fmt.Errorf("%w; wait for some to finish or cancel them", err))
+	}
+	return j, nil
+}
+
+// get returns the job with id, or an input error naming the problem.
+func (m *jobManager) get(id string) (*job, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, validator.NewError(validator.KindInvalidInput, errors.New("job_id is required"))
+	}
+	j := m.Get(id)
+	if j == nil {
+		return nil, validator.NewError(validator.KindInvalidInput, fmt.Errorf("unknown job_id %q: it never existed or its result expired after %s", id, m.Retention()))
+	}
+	return j, nil
+}
+
+// status returns j as the job tools show it.
+func (m *jobManager) status(j *job) JobStatus {
+	s := j.Snapshot()
+	st := JobStatus{
+		JobID:          j.ID,
+		State:          s.State,
+		Message:        s.Message,
+		ResumeID:       s.ResumeID,
+		CreatedAt:      j.CreatedAt.UTC().Format(time.RFC3339),
+		ElapsedSeconds: m.Now().Sub(j.CreatedAt).Seconds(),
+	}
+	if s.Err != nil {
+		st.Error = fmt.Sprintf("[%s] %s", validator.KindOf(s.Err), s.Err)
+	}
+	if s.State.Finished() {
+		st.FinishedAt = s.FinishedAt.UTC().Format(time.RFC3339)
+		st.ElapsedSeconds = s.FinishedAt.Sub(j.CreatedAt).Seconds()
+		st.ExpiresAt = m.ExpiresAt(s).UTC().Format(time.RFC3339)
+	}
+	return st
+}
+
+// startValidationHandler returns the start_validation handler, which
+// authenticates with creds and, before the job is queued, asks the user
+// through elicit for missing or ambiguous arguments.
+func (m *jobManager) startValidationHandler(creds *credentials, elicit *elicitor) mcp.ToolHandlerFor[ValidateMoveInput, JobStatus] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in ValidateMoveInput) (*mcp.CallToolResult, JobStatus, error) {
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), JobStatus{}, nil
+		}
+		in, pending, err := elicit.complete(ctx, req, cred, in)
+		if err != nil {
+			return toolError(err), JobStatus{}, nil
+		}
+		if pending != nil {
+			return pending, JobStatus{}, nil
+		}
+		if err := validateInputs(in); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), JobStatus{}, nil
+		}
+
+		vin := validatorInput(in)
+		key := validationKey(vin, creds.fingerprint(in.CredentialInput))
+		j, err := m.submit(func(ctx context.Context, notify validator.ProgressFn, resumeID func(string)) (ValidateMoveOutput, error) {
+			res, cached, err := m.runner.validate(ctx, key, vin, cred, time.Duration(in.MaxAgeSeconds)*time.Second, notify, resumeID)
+			if err != nil {
+				return ValidateMoveOutput{}, err
+			}
+			return res.output(in.IncludeRawResponse, cached), nil
+		})
+		if err != nil {
+			return toolError(err), JobStatus{}, nil
+		}
+		return nil, m.status(j), nil
+	}
+}
+
+func (m *jobManager) getValidationStatusHandler(_ context.Context, _ *mcp.CallToolRequest, in JobIDInput) (*mcp.CallToolResult, JobStatus, error) {
+	j, err := m.get(in.JobID)
+	if err != nil {
+		return toolError(err), JobStatus{}, nil
+	}
+	return nil, m.status(j), nil
+}
+
+func (m *jobManager) getValidationResultHandler(_ context.Context, _ *mcp.CallToolRequest, in JobIDInput) (*mcp.CallToolResult, ValidateMoveOutput, error) {
+	j, err := m.get(in.JobID)
+	if err != nil {
+		return toolError(err), ValidateMoveOutput{}, nil
+	}
+	s := j.Snapshot()
+	if !s.State.Finished() {
+		return toolError(validator.NewError(validator.KindInvalidInput, fmt.Errorf("job %s is still %s; poll get_validation_status until it finishes", j.ID, s.State))), ValidateMoveOutput{}, nil
+	}
+	if s.Err != nil {
+		return toolError(s.Err), ValidateMoveOutput{}, nil
+	}
+	return nil, s.Result, nil
+}
+
+func (m *jobManager) cancelValidationHandler(_ context.Context, _ *mcp.CallToolRequest, in JobIDInput) (*mcp.CallToolResult, JobStatus, error) {
+	j, err := m.get(in.JobID)
+	if err != nil {
+		return toolError(err), JobStatus{}, nil
+	}
+	j.Cancel()
+	// Azure calls return promptly once their context is cancelled; wait a
+	// moment so the status returned already says cancelled.
+	select {
+	case <-j.Done():
+	case <-time.After(cancelWait):
+	}
+	return nil, m.status(j), nil
+}
@@ -0,0 +1,114 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// The job lifecycle (queueing, expiry, cancellation) is tested in the jobs
+// package; these tests cover how the job tools present it.
+
+func TestJobToolsReportCompletedJob(t *testing.T) {
+	m := newJobManager(1, time.Minute, nil)
+	j, err := m.submit(func(_ context.Context, notify validator.ProgressFn, resumeID func(string)) (ValidateMoveOutput, error) {
+		notify("Polling Azure validate-move")
+		resumeID("resume-x.json")
+		return ValidateMoveOutput{Success: true, HTTPStatusCode: 204}, nil
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-j.Done()
+
+	_, st, _ := m.getValidationStatusHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if st.JobID != j.ID || st.State != JobCompleted || st.Message == "" || st.ResumeID != "resume-x.json" || st.Error != "" {
+		t.Errorf("status = %+v", st)
+	}
+	if st.CreatedAt == "" || st.FinishedAt == "" || st.ExpiresAt == "" {
+		t.Errorf("status times = %+v", st)
+	}
+	res, out, _ := m.getValidationResultHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if res != nil || !out.Success || out.HTTPStatusCode != 204 {
+		t.Errorf("result = %+v, %+v", res, out)
+	}
+}
+
+func TestJobToolsReportFailedJob(t *testing.T) {
+	m := newJobManager(1, time.Minute, nil)
+	j, _ := m.submit(func(context.Context, validator.ProgressFn, func(string)) (ValidateMoveOutput, error) {
+		return ValidateMoveOutput{}, validator.NewError(validator.KindResourceGroupNotFound, errors.New("rg-missing not found"))
+	})
+	<-j.Done()
+
+	_, st, _ := m.getValidationStatusHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if st.State != JobFailed || !strings.HasPrefix(st.Error, "[resource_group_not_found]") {
+		t.Errorf("status = %+v", st)
+	}
+	res, _, _ := m.getValidationResultHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if res == nil || !res.IsError {
+		t.Fatal("result of a failed job is not a tool error")
+	}
+}
+
+func TestJobResultOfUnfinishedJobIsToolError(t *testing.T) {
+	m := newJobManager(1, time.Minute, nil)
+	release := make(chan struct{})
+	j, _ := m.submit(func(context.Context, validator.ProgressFn, func(string)) (ValidateMoveOutput, error) {
+		<-release
+		return ValidateMoveOutput{Success: true}, nil
+	})
+	defer close(release)
+
+	_, st, _ := m.getValidationStatusHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if st.State.Finished() || st.FinishedAt != "" || st.ExpiresAt != "" {
+		t.Errorf("status of an unfinished job = %+v", st)
+	}
+	res, _, _ := m.getValidationResultHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+	if res == nil || !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "poll get_validation_status") {
+		t.Errorf("result of an unfinished job = %+v", res)
+	}
+}
+
+func TestCancelValidation(t *testing.T) {
+	m := newJobManager(1, time.Minute, nil)
+	started := make(chan struct{})
+	running, _ := m.submit(func(ctx context.Context, _ validator.ProgressFn, _ func(string)) (ValidateMoveOutput, error) {
+		close(started)
+		<-ctx.Done()
+		return ValidateMoveOutput{}, validator.PollError(ctx.Err())
+	})
+	<-started
+	queued, _ := m.submit(func(context.Context, validator.ProgressFn, func(string)) (ValidateMoveOutput, error) {
+		t.Error("cancelled queued job ran")
+		return ValidateMoveOutput{}, nil
+	})
+
+	// The tool waits for the job to end, so the status it returns already
+	// says cancelled.
+	for _, j := range []*job{queued, running} {
+		_, st, _ := m.cancelValidationHandler(t.Context(), nil, JobIDInput{JobID: j.ID})
+		if st.State != JobCancelled || !strings.HasPrefix(st.Error, "[interrupted]") || st.FinishedAt == "" {
+			t.Errorf("status after cancel = %+v", st)
+		}
+	}
+}
+
+func TestJobToolsRejectUnknownID(t *testing.T) {
+	m := newJobManager(0, 0, nil)
+	for _, id := range []string{"", "job-doesnotexist"} {
+		status, _, _ := m.getValidationStatusHandler(t.Context(), nil, JobIDInput{JobID: id})
+		result, _, _ := m.getValidationResultHandler(t.Context(), nil, JobIDInput{JobID: id})
+		cancel, _, _ := m.cancelValidationHandler(t.Context(), nil, JobIDInput{JobID: id})
+		for tool, res := range map[string]*mcp.CallToolResult{"get_validation_status": status, "get_validation_result": result, "cancel_validation": cancel} {
+			if res == nil || !res.IsError || !strings.Contains(res.Content[0].(*mcp.TextContent).Text, "[invalid_input]") {
+				t.Errorf("%s with job_id %q: result = %+v", tool, id, res)
+			}
+		}
+	}
+}
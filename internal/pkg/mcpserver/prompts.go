@@ -0,0 +1,245 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerPrompts adds the move-planning prompt templates. Each one spells
+// out which tools to call with which arguments, so every user of a shared
+// server gets the same workflow. Prompts that take report references embed
+// those reports from reports, saving the client a round trip.
+func registerPrompts(server *mcp.Server, reports *reportStore) {
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "assess_move_readiness",
+		Title:       "Assess move readiness of a resource group",
+		Description: "Inventory a resource group, validate a move to a target and summarise what blocks it.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "subscription_id", Description: "Subscription UUID of the resource group to assess", Required: true},
+			{Name: "resource_group", Description: "Resource group to assess", Required: true},
+			{Name: "target_subscription_id", Description: "Target subscription UUID; defaults to the source subscription"},
+			{Name: "target_resource_group", Description: "Target resource group; if omitted, ask the user to pick one"},
+		},
+	}, assessMoveReadinessPrompt)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "explain_failed_validation",
+		Title:       "Explain a failed validation",
+		Description: "Explain why a validation failed, resource by resource, and what to change before retrying.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "report", Description: "report_uri (armv://reports/…) or report id of the failed run; omit to use job_id"},
+			{Name: "job_id", Description: "job_id of a finished start_validation job, when no report is given"},
+		},
+	}, explainFailedValidationPrompt(reports))
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "plan_migration_wave",
+		Title:       "Plan a migration wave",
+		Description: "Validate several resource groups and group them into ordered move waves with the fixes each needs.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "source_subscription_id", Description: "Subscription UUID the resource groups move from", Required: true},
+			{Name: "target_subscription_id", Description: "Subscription UUID the resource groups move to", Required: true},
+			{Name: "resource_groups", Description: "Comma-separated source resource groups in scope", Required: true},
+			{Name: "target_resource_group", Description: "Single target resource group for all of them; default is a group with the same name in the target subscription"},
+		},
+	}, planMigrationWavePrompt)
+
+	server.AddPrompt(&mcp.Prompt{
+		Name:        "compare_validation_runs",
+		Title:       "Compare two validation runs",
+		Description: "Compare two validation reports: what was fixed, what is new and what still blocks the move.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "baseline", Description: "report_uri or report id of the earlier run", Required: true},
+			{Name: "current", Description: "report_uri or report id of the later run", Required: true},
+		},
+	}, compareValidationRunsPrompt(reports))
+}
+
+// promptArgs reads a prompt's arguments, trimming whitespace.
+type promptArgs map[string]string
+
+func (a promptArgs) get(name string) string { return strings.TrimSpace(a[name]) }
+
+// require returns the named argument or an invalid-params error.
+func (a promptArgs) require(name string) (string, error) {
+	if v := a.get(name); v != "" {
+		return v, nil
+	}
+	return "", invalidPromptArgument("%s is required", name)
+}
+
+// subscription returns the named argument if it is a valid subscription UUID.
+func (a promptArgs) subscription(name string, required bool) (string, error) {
+	v := a.get(name)
+	if v == "" && !required {
+		return "", nil
+	}
+	if !utils.CheckValidSubscriptionID(v) {
+		return "", invalidPromptArgument("invalid %s %q: must be a UUID", name, v)
+	}
+	return v, nil
+}
+
+func invalidPromptArgument(format string, args ...any) error {
+	return &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// reportURI accepts a report_uri or a bare report id, with or without the
+// .json suffix, and returns the Markdown report's URI.
+func reportURI(ref string) string {
+	ref = strings.TrimSuffix(ref, ".json")
+	if strings.HasPrefix(ref, reportURIPrefix) {
+		return ref
+	}
+	return reportURIPrefix + ref
+}
+
+func promptResult(description string, messages ...mcp.Content) *mcp.GetPromptResult {
+	res := &mcp.GetPromptResult{Description: description}
+	for _, c := range messages {
+		res.Messages = append(res.Messages, &mcp.PromptMessage{Role: "user", Content: c})
+	}
+	return res
+}
+
+func assessMoveReadinessPrompt(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := promptArgs(req.Params.Arguments)
+	sub, err := args.subscription("subscription_id", true)
+	if err != nil {
+		return nil, err
+	}
+	rg, err := args.require("resource_group")
+	if err != nil {
+		return nil, err
+	}
+	targetSub, err := args.subscription("target_subscription_id", false)
+	if err != nil {
+		return nil, err
+	}
+	if targetSub == "" {
+		targetSub = sub
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Assess whether resource group %q in subscription %s is ready to move.\n\n", rg, sub)
+	fmt.Fprintf(&b, "1. Call list_resources with subscription_id=%q and resource_group=%q. Summarise the inventory by resource type.\n", sub, rg)
+	if target := args.get("target_resource_group"); target != "" {
+		fmt.Fprintf(&b, "2. The target is resource group %q in subscription %s.\n", target, targetSub)
+	} else {
+		fmt.Fprintf(&b, "2. Call list_resource_groups with subscription_id=%q and ask me which one is the target. Do not guess.\n", targetSub)
+	}
+	fmt.Fprintf(&b, "3. Call start_validation with source_subscription_id=%q, source_resource_group=%q, target_subscription_id=%q and the target resource group. "+
+		"Poll get_validation_status every 10 seconds until the state is completed, failed or cancelled, then call get_validation_result.\n", sub, rg, targetSub)
+	b.WriteString("4. Report:\n" +
+		"   - a one-line verdict: ready to move, or blocked;\n" +
+		"   - a table of blocking resources (name, type, error code) from the result's errors, grouped by resource type;\n" +
+		"   - the result's remediation hints, applied to the specific resources;\n" +
+		"   - resources from step 1 that validated cleanly.\n" +
+		"Quote error codes exactly. Mention the report_uri so the run can be compared later. " +
+		"Validation is read-only; never suggest that anything has been moved.\n")
+
+	return promptResult("Assess move readiness of "+rg, &mcp.TextContent{Text: b.String()}), nil
+}
+
+func explainFailedValidationPrompt(reports *reportStore) mcp.PromptHandler {
+	return func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := promptArgs(req.Params.Arguments)
+		instructions := "Explain why this resource move validation failed, for an engineer who has to fix it.\n\n" +
+			"- Start with the top-level code and what it means in one sentence.\n" +
+			"- Then, for each failing resource, give its name and type, the error code, what the message means in plain words, and the concrete change needed. Use the remediation hints where present.\n" +
+			"- Group resources that fail for the same reason.\n" +
+			"- Finish with an ordered checklist of changes, and say that re-running validate_move (or start_validation) after them will confirm the fix.\n" +
+			"Do not invent error codes or resources that are not in the result."
+
+		if ref := args.get("report"); ref != "" {
+			contents, err := reports.contents(reportURI(ref) + ".json")
+			if err != nil {
+				return nil, invalidPromptArgument("unknown report %q: pass a report_uri returned by validate_move, resume_validation or get_validation_result", ref)
+			}
+			return promptResult("Explain failed validation "+ref,
+				&mcp.TextContent{Text: instructions},
+				&mcp.EmbeddedResource{Resource: contents}), nil
+		}
+		if jobID := args.get("job_id"); jobID != "" {
+			return promptResult("Explain failed validation job "+jobID, &mcp.TextContent{
+				Text: fmt.Sprintf("Call get_validation_result with job_id=%q. If the job is still running, poll get_validation_status every 10 seconds first.\n\n%s", jobID, instructions),
+			}), nil
+		}
+		return nil, invalidPromptArgument("pass either report or job_id")
+	}
+}
+
+func planMigrationWavePrompt(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := promptArgs(req.Params.Arguments)
+	sourceSub, err := args.subscription("source_subscription_id", true)
+	if err != nil {
+		return nil, err
+	}
+	targetSub, err := args.subscription("target_subscription_id", true)
+	if err != nil {
+		return nil, err
+	}
+	list, err := args.require("resource_groups")
+	if err != nil {
+		return nil, err
+	}
+	var groups []string
+	for g := range strings.SplitSeq(list, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, invalidPromptArgument("resource_groups lists no resource groups")
+	}
+
+	target := "the resource group with the same name in the target subscription"
+	if t := args.get("target_resource_group"); t != "" {
+		target = fmt.Sprintf("%q", t)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plan the migration of these resource groups from subscription %s to subscription %s, each into %s:\n", sourceSub, targetSub, target)
+	for _, g := range groups {
+		fmt.Fprintf(&b, "- %s\n", g)
+	}
+	fmt.Fprintf(&b, "\n1. Call list_resource_groups with subscription_id=%q and check the target groups exist; list any that must be created first.\n", targetSub)
+	fmt.Fprintf(&b, "2. For each source group, call start_validation with source_subscription_id=%q, target_subscription_id=%q, the source group and its target. "+
+		"Start them all, then poll get_validation_status for each job every 10 seconds and call get_validation_result once it finishes. Queued jobs are normal: the server limits how many run at once.\n", sourceSub, targetSub)
+	b.WriteString("3. Group the resource groups into waves:\n" +
+		"   - Wave 1: groups that validated cleanly.\n" +
+		"   - Later waves: groups that need fixes, ordered by effort. Groups whose errors name each other's resources (e.g. MissingMoveDependentResources) go in the same wave.\n" +
+		"   - Not movable: groups with resources whose type cannot move (e.g. ResourceMoveNotSupported); say what must be recreated instead.\n" +
+		"4. Output a table: wave, resource group, resource count, blocking errors, fixes needed, report_uri. " +
+		"Then list the fixes per wave as a checklist. Re-validate a wave with start_validation right before moving it, since results go stale.\n")
+
+	return promptResult(fmt.Sprintf("Plan a migration wave for %d resource group(s)", len(groups)), &mcp.TextContent{Text: b.String()}), nil
+}
+
+func compareValidationRunsPrompt(reports *reportStore) mcp.PromptHandler {
+	return func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := promptArgs(req.Params.Arguments)
+		messages := []mcp.Content{&mcp.TextContent{Text: "Compare the two validation reports below: the first is the baseline, the second the current run.\n\n" +
+			"- State whether the source and target are the same in both; if not, say so and compare only what is comparable.\n" +
+			"- List errors that were resolved, errors that are new, and errors present in both, matching on resource ID and error code.\n" +
+			"- Note changes in the number of resources validated.\n" +
+			"- End with a verdict: better, worse or unchanged, and the next fix to make."}}
+		for _, name := range []string{"baseline", "current"} {
+			ref, err := args.require(name)
+			if err != nil {
+				return nil, err
+			}
+			contents, err := reports.contents(reportURI(ref) + ".json")
+			if err != nil {
+				return nil, invalidPromptArgument("unknown %s report %q: list resources to see the armv://reports/ URIs available", name, ref)
+			}
+			messages = append(messages, &mcp.EmbeddedResource{Resource: contents})
+		}
+		return promptResult("Compare validation runs", messages...), nil
+	}
+}
@@ -0,0 +1,122 @@
+package mcpserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPromptsAreAdvertised(t *testing.T) {
+	cs := connectInMemory(t, t.Context(), newTestServer(t), nil, nil)
+	res, err := cs.ListPrompts(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, p := range res.Prompts {
+		got[p.Name] = true
+		if p.Description == "" {
+			t.Errorf("prompt %q has no description", p.Name)
+		}
+	}
+	for _, name := range []string{"assess_move_readiness", "explain_failed_validation", "plan_migration_wave", "compare_validation_runs"} {
+		if !got[name] {
+			t.Errorf("prompt %q not advertised", name)
+		}
+	}
+}
+
+func TestPromptsWireInToolCalls(t *testing.T) {
+	cs := connectInMemory(t, t.Context(), newTestServer(t), nil, nil)
+	tests := []struct {
+		name string
+		args map[string]string
+		want []string
+	}{
+		{
+			name: "assess_move_readiness",
+			args: map[string]string{"subscription_id": "11111111-1111-1111-1111-111111111111", "resource_group": "rg-app"},
+			want: []string{`list_resources with subscription_id="11111111-1111-1111-1111-111111111111" and resource_group="rg-app"`, "list_resource_groups", "start_validation", "get_validation_result"},
+		},
+		{
+			name: "assess_move_readiness",
+			args: map[string]string{"subscription_id": "11111111-1111-1111-1111-111111111111", "resource_group": "rg-app", "target_resource_group": "rg-new"},
+			want: []string{`The target is resource group "rg-new" in subscription 11111111-1111-1111-1111-111111111111`},
+		},
+		{
+			name: "plan_migration_wave",
+			args: map[string]string{"source_subscription_id": "11111111-1111-1111-1111-111111111111", "target_subscription_id": "22222222-2222-2222-2222-222222222222", "resource_groups": "rg-a, rg-b,,"},
+			want: []string{"- rg-a\n- rg-b\n", "start_validation", "Wave 1"},
+		},
+		{
+			name: "explain_failed_validation",
+			args: map[string]string{"job_id": "job-abc"},
+			want: []string{`get_validation_result with job_id="job-abc"`},
+		},
+	}
+	for _, tt := range tests {
+		res, err := cs.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: tt.name, Arguments: tt.args})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		text := res.Messages[0].Content.(*mcp.TextContent).Text
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: prompt does not contain %q:\n%s", tt.name, want, text)
+			}
+		}
+	}
+}
+
+func TestPromptsRejectBadArguments(t *testing.T) {
+	cs := connectInMemory(t, t.Context(), newTestServer(t), nil, nil)
+	tests := []struct {
+		name string
+		args map[string]string
+	}{
+		{"assess_move_readiness", map[string]string{"resource_group": "rg"}},
+		{"assess_move_readiness", map[string]string{"subscription_id": "nope", "resource_group": "rg"}},
+		{"assess_move_readiness", map[string]string{"subscription_id": "11111111-1111-1111-1111-111111111111"}},
+		{"plan_migration_wave", map[string]string{"source_subscription_id": "11111111-1111-1111-1111-111111111111", "target_subscription_id": "22222222-2222-2222-2222-222222222222", "resource_groups": " , "}},
+		{"explain_failed_validation", nil},
+		{"explain_failed_validation", map[string]string{"report": "armv://reports/unknown"}},
+		{"compare_validation_runs", map[string]string{"baseline": "unknown", "current": "unknown2"}},
+	}
+	for _, tt := range tests {
+		if _, err := cs.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: tt.name, Arguments: tt.args}); err == nil {
+			t.Errorf("%s %v: no error", tt.name, tt.args)
+		}
+	}
+}
+
+func TestReportPromptsEmbedReports(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	reports := newReportStore(server, t.TempDir())
+	registerPrompts(server, reports)
+	baseline := reports.output(conflictResult, false)
+	current := reports.output(conflictResult, false)
+
+	cs := connectInMemory(t, t.Context(), server, nil, nil)
+
+	res, err := cs.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: "explain_failed_validation", Arguments: map[string]string{"report": baseline.ReportURI}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Messages) != 2 {
+		t.Fatalf("explain_failed_validation returned %d messages, want instructions + report", len(res.Messages))
+	}
+	if r, ok := res.Messages[1].Content.(*mcp.EmbeddedResource); !ok || r.Resource.URI != baseline.ReportURI+".json" || !strings.Contains(r.Resource.Text, "ResourceMoveNotSupported") {
+		t.Errorf("embedded report = %+v", res.Messages[1].Content)
+	}
+
+	id := strings.TrimPrefix(current.ReportURI, reportURIPrefix)
+	res, err = cs.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: "compare_validation_runs", Arguments: map[string]string{"baseline": baseline.ReportURI + ".json", "current": id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Messages) != 3 {
+		t.Fatalf("compare_validation_runs returned %d messages, want instructions + 2 reports", len(res.Messages))
+	}
+}
@@ -0,0 +1,216 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// reportURIPrefix prefixes every report resource: armv://reports/{id} is
+	// the Markdown report and armv://reports/{id}.json the JSON one.
+	reportURIPrefix = "armv://reports/"
+
+	// MaxStoredReports is how many reports are kept; the oldest are deleted
+	// as new runs complete.
+	MaxStoredReports = 50
+)
+
+// DefaultReportDir returns where the MCP server keeps its validation reports,
+// so they survive a restart.
+func DefaultReportDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "armv", "mcp-reports"), nil
+}
+
+// storedReport is one completed validation. It is saved to disk and served
+// as the JSON resource in the run record format, so `armv report render`
+// re-renders it like a CLI run; Result adds the tool output, with its
+// remediation hints, for the prompts that embed the report.
+type storedReport struct {
+	ID string `json:"-"` // the file name, without .json
+	poller.RunRecord
+	Result ValidateMoveOutput `json:"result"`
+}
+
+// reportStore publishes completed validations as MCP resources. Adding a
+// report registers its resources with the server, which notifies clients
+// that the resource list changed.
+type reportStore struct {
+	server *mcp.Server
+	dir    string // "" keeps reports in memory only
+
+	mu      sync.Mutex
+	reports map[string]*storedReport
+	order   []string // IDs, oldest first
+}
+
+// newReportStore loads the reports already in dir and registers them with
+// server. Unreadable files are skipped: a report is a convenience, not state
+// the server depends on.
+func newReportStore(server *mcp.Server, dir string) *reportStore {
+	s := &reportStore{server: server, dir: dir, reports: make(map[string]*storedReport)}
+	// The template also makes the server advertise the resources capability
+	// before the first report exists, so clients subscribe to list changes.
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: reportURIPrefix + "{id}",
+		Name:        "report",
+		Title:       "Validation report",
+		Description: "Report of a completed validate_move, resume_validation or start_validation run, by the id in its report_uri. Markdown by default; append .json to the id for JSON.",
+		MIMEType:    "text/markdown",
+	}, s.read)
+	if dir == "" {
+		return s
+	}
+	names, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	slices.Sort(names) // IDs start with the UTC time, so this is oldest first
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var r storedReport
+		if json.Unmarshal(data, &r) != nil || r.StatusCode == 0 {
+			continue
+		}
+		r.ID = strings.TrimSuffix(filepath.Base(name), ".json")
+		s.insertLocked(&r)
+	}
+	s.pruneLocked()
+	return s
+}
+
+// output converts result into the tool output and records it as a report,
+// setting ReportURI. A nil store only converts.
+func (s *reportStore) output(result *validator.Result, includeRaw bool) ValidateMoveOutput {
+	out := newValidateMoveOutput(result, includeRaw)
+	out.ReportURI = s.add(result)
+	return out
+}
+
+// add records result as a report and returns its URI, or "" for a nil
+// store.
+func (s *reportStore) add(result *validator.Result) string {
+	if s == nil {
+		return ""
+	}
+
+	now := time.Now().UTC()
+	r := &storedReport{
+		ID:        now.Format("20060102T150405Z") + "-" + strings.ToLower(rand.Text()[:6]),
+		RunRecord: result.RunRecord(now),
+		Result:    newValidateMoveOutput(result, false),
+	}
+	r.Result.ReportURI = reportURIPrefix + r.ID
+	r.Result.Diagnostics = "" // kept once, in Body
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir != "" {
+		if data, err := json.MarshalIndent(r, "", "  "); err == nil {
+			_ = utils.WriteOutputFile(s.dir, r.ID+".json", string(data)+"\n")
+		}
+	}
+	s.insertLocked(r)
+	s.pruneLocked()
+	return r.Result.ReportURI
+}
+
+func (s *reportStore) insertLocked(r *storedReport) {
+	s.reports[r.ID] = r
+	s.order = append(s.order, r.ID)
+
+	outcome := "SUCCESS"
+	if !r.Result.Success {
+		outcome = fmt.Sprintf("FAILED, %d %s", r.Result.Counts.Errors, pluralise("error", r.Result.Counts.Errors))
+	}
+	title := fmt.Sprintf("Move validation %s → %s (%s)", r.Context.SourceResourceGroup, r.Context.TargetResourceGroup, outcome)
+	description := fmt.Sprintf("Validate-move report generated %s for %d resource(s) from subscription %s to %s.",
+		r.GeneratedAt.Format(time.RFC3339), r.Context.ResourceCount, r.Context.SourceSubscriptionID, r.Context.TargetSubscriptionID)
+
+	s.server.AddResource(&mcp.Resource{
+		URI:         reportURIPrefix + r.ID,
+		Name:        "report-" + r.ID,
+		Title:       title,
+		Description: description,
+		MIMEType:    "text/markdown",
+	}, s.read)
+	s.server.AddResource(&mcp.Resource{
+		URI:         reportURIPrefix + r.ID + ".json",
+		Name:        "report-" + r.ID + ".json",
+		Title:       title + " [JSON]",
+		Description: description,
+		MIMEType:    "application/json",
+	}, s.read)
+}
+
+// pruneLocked forgets and deletes the oldest reports beyond MaxStoredReports.
+func (s *reportStore) pruneLocked() {
+	for len(s.order) > MaxStoredReports {
+		id := s.order[0]
+		s.order = s.order[1:]
+		delete(s.reports, id)
+		s.server.RemoveResources(reportURIPrefix+id, reportURIPrefix+id+".json")
+		if s.dir != "" {
+			_ = os.Remove(filepath.Join(s.dir, id+".json"))
+		}
+	}
+}
+
+// read serves armv://reports/{id} as Markdown and armv://reports/{id}.json
+// as JSON.
+func (s *reportStore) read(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	contents, err := s.contents(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
+
+// contents returns the report resource at uri, or a resource-not-found error.
+func (s *reportStore) contents(uri string) (*mcp.ResourceContents, error) {
+	id, isJSON := strings.CutSuffix(strings.TrimPrefix(uri, reportURIPrefix), ".json")
+
+	s.mu.Lock()
+	r, ok := s.reports[id]
+	s.mu.Unlock()
+	if !ok || !strings.HasPrefix(uri, reportURIPrefix) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	if isJSON {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("mcpserver: encode report %s: %w", id, err)
+		}
+		return &mcp.ResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)}, nil
+	}
+	return &mcp.ResourceContents{URI: uri, MIMEType: "text/markdown", Text: renderReport(r)}, nil
+}
+
+// renderReport renders r with the same Markdown renderer as the CLI report.
+func renderReport(r *storedReport) string {
+	return poller.RenderMarkdown(r.Report())
+}
+
+func pluralise(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,131 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var conflictResult = &validator.Result{
+	SourceSubscriptionID: "11111111-1111-1111-1111-111111111111",
+	SourceResourceGroup:  "rg-src",
+	TargetSubscriptionID: "22222222-2222-2222-2222-222222222222",
+	TargetResourceGroup:  "rg-dst",
+	ResourceIDs:          []string{"a", "b"},
+	HTTPStatusCode:       409,
+	HTTPStatus:           "409 Conflict",
+	ResponseBody: []byte(`{"error":{"code":"ResourceMoveProviderValidationFailed","message":"failed","details":[
+		{"code":"ResourceMoveNotSupported","target":"/subscriptions/s/resourceGroups/rg-src/providers/Microsoft.Foo/bars/b","message":"no"}]}}`),
+}
+
+// connectReports connects a client to a server holding only a report store.
+func connectReports(t *testing.T, dir string, opts *mcp.ClientOptions) (*reportStore, *mcp.ClientSession) {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	store := newReportStore(server, dir)
+	return store, connectInMemory(t, t.Context(), server, opts, nil)
+}
+
+func TestReportsAreServedAsResources(t *testing.T) {
+	dir := t.TempDir()
+	changed := make(chan struct{}, 1)
+	store, cs := connectReports(t, dir, &mcp.ClientOptions{
+		ResourceListChangedHandler: func(_ context.Context, _ *mcp.ResourceListChangedRequest) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	out := store.output(conflictResult, false)
+	if !strings.HasPrefix(out.ReportURI, reportURIPrefix) {
+		t.Fatalf("ReportURI = %q", out.ReportURI)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Error("no resources/list_changed notification after a report was added")
+	}
+
+	res, err := cs.ListResources(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uris := map[string]bool{}
+	for _, r := range res.Resources {
+		uris[r.URI] = true
+	}
+	if !uris[out.ReportURI] || !uris[out.ReportURI+".json"] {
+		t.Errorf("resources/list = %v, want %s and its .json", uris, out.ReportURI)
+	}
+
+	md, err := cs.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: out.ReportURI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := md.Contents[0].Text; md.Contents[0].MIMEType != "text/markdown" || !strings.Contains(text, "# Azure Resource Move Validation Report") || !strings.Contains(text, "ResourceMoveNotSupported") {
+		t.Errorf("Markdown report = %q", text)
+	}
+
+	js, err := cs.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: out.ReportURI + ".json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got storedReport
+	if err := json.Unmarshal([]byte(js.Contents[0].Text), &got); err != nil {
+		t.Fatalf("JSON report: %v", err)
+	}
+	if got.Context.SourceResourceGroup != "rg-src" || got.StatusCode != conflictResult.HTTPStatusCode || got.Body == "" || got.Result.Counts.Errors != 1 {
+		t.Errorf("JSON report = %+v", got)
+	}
+
+	if _, err := cs.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: reportURIPrefix + "nope"}); err == nil {
+		t.Error("reading an unknown report succeeded")
+	}
+}
+
+func TestReportsSurviveRestartAndArePruned(t *testing.T) {
+	dir := t.TempDir()
+	store := newReportStore(mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), dir)
+	first := store.output(conflictResult, false)
+
+	// The saved report is a run record, so `armv report render` rebuilds the
+	// same report the server serves.
+	id := strings.TrimPrefix(first.ReportURI, reportURIPrefix)
+	rec, err := poller.LoadRunRecord(filepath.Join(dir, id+".json"))
+	if err != nil {
+		t.Fatalf("saved report is not a run record: %v", err)
+	}
+
+	reloaded, cs := connectReports(t, dir, nil)
+	md, err := cs.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: first.ReportURI})
+	if err != nil {
+		t.Fatalf("report from before the restart: %v", err)
+	}
+	if want := poller.RenderMarkdown(rec.Report()); md.Contents[0].Text != want {
+		t.Errorf("served report differs from the re-rendered run record:\n%s\nwant:\n%s", md.Contents[0].Text, want)
+	}
+
+	for range MaxStoredReports {
+		reloaded.output(conflictResult, false)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != MaxStoredReports {
+		t.Errorf("%d report files kept, want %d", len(files), MaxStoredReports)
+	}
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); !os.IsNotExist(err) {
+		t.Errorf("oldest report file not pruned: %v", err)
+	}
+	if _, err := cs.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: first.ReportURI}); err == nil {
+		t.Error("oldest report is still served")
+	}
+}
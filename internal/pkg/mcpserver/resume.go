@@ -0,0 +1,99 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResumeValidationInput is the MCP input for resume_validation. The resume
+// state itself lives on the server (under the user cache directory), so the
+// client only names which saved operation to reattach to.
+type ResumeValidationInput struct {
+	ResumeID string `json:"resume_id,omitempty" jsonschema:"resume_id returned by an earlier validate_move call; omit to resume the most recent saved operation"`
+
+	CredentialInput
+
+	PollIntervalSeconds int `json:"poll_interval_seconds,omitempty" jsonschema:"optional seconds between validate-move polls when Azure sends no Retry-After (default 2, minimum 1)"`
+	PollTimeoutSeconds  int `json:"poll_timeout_seconds,omitempty"  jsonschema:"optional seconds to keep polling before giving up (default 1800)"`
+
+	IncludeRawResponse bool `json:"include_raw_response,omitempty" jsonschema:"optional; also return the raw Azure response body in diagnostics (it is always returned when it cannot be parsed)"`
+}
+
+// resumeValidationHandler returns the resume_validation handler, which
+// authenticates with creds and records each completed run in reports.
+func resumeValidationHandler(creds *credentials, reports *reportStore) mcp.ToolHandlerFor[ResumeValidationInput, ValidateMoveOutput] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in ResumeValidationInput) (*mcp.CallToolResult, ValidateMoveOutput, error) {
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ValidateMoveOutput{}, nil
+		}
+		if in.PollIntervalSeconds < 0 || in.PollTimeoutSeconds < 0 {
+			return toolError(validator.NewError(validator.KindInvalidInput, fmt.Errorf("poll_interval_seconds and poll_timeout_seconds must not be negative"))), ValidateMoveOutput{}, nil
+		}
+
+		resumeFile, err := resolveResumeFile(in.ResumeID)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ValidateMoveOutput{}, nil
+		}
+		st, err := validator.LoadResumeState(resumeFile)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ValidateMoveOutput{}, nil
+		}
+
+		op, err := validator.Resume(ctx, st, cred, poller.PollOptions{
+			Interval: time.Duration(in.PollIntervalSeconds) * time.Second,
+			Timeout:  time.Duration(in.PollTimeoutSeconds) * time.Second,
+		}, progressNotifier(ctx, req))
+		if err != nil {
+			return toolError(err), ValidateMoveOutput{}, nil
+		}
+
+		return waitForResult(ctx, op, resumeFile, in.IncludeRawResponse, reports)
+	}
+}
+
+// resolveResumeFile maps a resume_id (a bare file name in the resume
+// directory) to a path, defaulting to the newest saved state. Path separators
+// are rejected so a client cannot point the server at arbitrary files.
+func resolveResumeFile(resumeID string) (string, error) {
+	dir, err := validator.DefaultResumeDir()
+	if err != nil {
+		return "", err
+	}
+	if resumeID == "" {
+		return validator.LatestResumeFile(dir)
+	}
+	if strings.ContainsAny(resumeID, `/\`) || resumeID != filepath.Base(resumeID) || !strings.HasPrefix(resumeID, validator.ResumeFilePrefix) {
+		return "", fmt.Errorf("invalid resume_id %q", resumeID)
+	}
+	return filepath.Join(dir, resumeID), nil
+}
+
+// saveResumeState persists op's resume token to the server's resume directory
+// and returns the file path, or "" if it could not be saved. Failure is not
+// fatal: the validation is already running, it just cannot be resumed.
+func saveResumeState(op *validator.Operation, notify validator.ProgressFn) string {
+	st, err := op.ResumeState()
+	if err != nil {
+		return ""
+	}
+	dir, err := validator.DefaultResumeDir()
+	if err != nil {
+		return ""
+	}
+	path, err := validator.SaveResumeState(dir, st)
+	if err != nil {
+		return ""
+	}
+	if notify != nil {
+		notify(fmt.Sprintf("Saved resume state as %s", filepath.Base(path)))
+	}
+	return path
+}
@@ -0,0 +1,283 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+const (
+	// DefaultMaxValidations is how many validations the server runs against
+	// Azure at once, across validate_move and start_validation.
+	DefaultMaxValidations = 8
+	// MaxResultAge is the largest max_age_seconds a call may ask for, and so
+	// how long a completed validation is kept for reuse.
+	MaxResultAge = 15 * time.Minute
+)
+
+// runFunc starts one validation and polls it to completion, reporting the
+// resume ID of the Azure operation once it is known.
+type runFunc func(ctx context.Context, in validator.Input, cred azcore.TokenCredential, notify validator.ProgressFn, resumeID func(string)) (*validator.Result, error)
+
+// validationRunner runs validations for the MCP tools. Identical concurrent
+// requests (same validator.Input and credential, see validationKey) share
+// one Azure long-running operation, a completed validation can be reused
+// for a while when the caller allows it, and at most cap(slots) operations
+// run at once so a busy server does not get the subscription throttled.
+type validationRunner struct {
+	reports *reportStore
+	slots   chan struct{}
+	now     func() time.Time
+	run     runFunc
+
+	mu       sync.Mutex
+	inflight map[string]*flight
+	cache    map[string]*completedValidation
+}
+
+// completedValidation is a finished validation and the report recorded for it.
+type completedValidation struct {
+	result    *validator.Result
+	reportURI string
+	at        time.Time
+}
+
+// output shapes c for one caller.
+func (c *completedValidation) output(includeRaw, cached bool) ValidateMoveOutput {
+	out := newValidateMoveOutput(c.result, includeRaw)
+	out.ReportURI = c.reportURI
+	out.Cached = cached
+	return out
+}
+
+// flight is one running validation and the callers waiting on it. waiters
+// is guarded by validationRunner.mu, the fields after mu by flight.mu; res
+// and err are written once, before done is closed.
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	mu       sync.Mutex
+	subs     map[*subscriber]struct{}
+	resumeID string
+
+	res *completedValidation
+	err error
+}
+
+// subscriber is one waiter's progress and resume ID callbacks.
+type subscriber struct {
+	notify   validator.ProgressFn
+	resumeID func(string)
+}
+
+func newValidationRunner(maxRunning int, reports *reportStore) *validationRunner {
+	if maxRunning <= 0 {
+		maxRunning = DefaultMaxValidations
+	}
+	return &validationRunner{
+		reports:  reports,
+		slots:    make(chan struct{}, maxRunning),
+		now:      time.Now,
+		run:      runValidation,
+		inflight: make(map[string]*flight),
+		cache:    make(map[string]*completedValidation),
+	}
+}
+
+// runValidation is the default runFunc: start the validation, save its
+// resume state and poll it to completion.
+func runValidation(ctx context.Context, in validator.Input, cred azcore.TokenCredential, notify validator.ProgressFn, resumeID func(string)) (*validator.Result, error) {
+	op, err := validator.Start(ctx, in, cred, notify)
+	if err != nil {
+		return nil, err
+	}
+	resumeFile := saveResumeState(op, notify)
+	if resumeFile != "" {
+		resumeID(filepath.Base(resumeFile))
+	}
+	return waitForOperation(ctx, op, resumeFile)
+}
+
+// validationKey identifies what a validation asks Azure, so identical
+// requests can share a run: the source and target (case-insensitively, as
+// ARM compares them), the resources, and credential, the fingerprint of the
+// credential it runs as. Poll settings are not part of it; a shared run
+// polls with the settings of the call that started it.
+func validationKey(in validator.Input, credential string) string {
+	ids := make([]string, len(in.ResourceIDs))
+	for i, id := range in.ResourceIDs {
+		ids[i] = strings.ToLower(id)
+	}
+	slices.Sort(ids)
+	return strings.Join([]string{
+		credential,
+		strings.ToLower(in.SourceSubscriptionID),
+		strings.ToLower(in.SourceResourceGroup),
+		strings.ToLower(in.TargetSubscriptionID),
+		strings.ToLower(in.TargetResourceGroup),
+		strings.Join(ids, ","),
+	}, "\x00")
+}
+
+// validate returns the outcome of the validation key identifies. A result
+// completed within maxAge is returned as is (cached is true); otherwise the
+// call joins an identical validation already running, or starts one. The
+// run outlives any one caller and is cancelled only once every caller has
+// gone.
+func (r *validationRunner) validate(ctx context.Context, key string, in validator.Input, cred azcore.TokenCredential, maxAge time.Duration, notify validator.ProgressFn, resumeID func(string)) (res *completedValidation, cached bool, err error) {
+	r.mu.Lock()
+	r.pruneLocked()
+	if c, ok := r.cache[key]; ok && maxAge > 0 && r.now().Sub(c.at) <= maxAge {
+		r.mu.Unlock()
+		return c, true, nil
+	}
+	f, joined := r.inflight[key]
+	var fctx context.Context
+	if !joined {
+		var cancel context.CancelFunc
+		fctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel, subs: make(map[*subscriber]struct{})}
+		r.inflight[key] = f
+	}
+	f.waiters++
+	sub := f.subscribe(notify, resumeID)
+	r.mu.Unlock()
+	if joined {
+		if notify != nil {
+			notify("Joined an identical validation already in progress")
+		}
+	} else {
+		go r.fly(fctx, key, f, in, cred)
+	}
+
+	select {
+	case <-f.done:
+		f.unsubscribe(sub)
+		return f.res, false, f.err
+	case <-ctx.Done():
+		f.unsubscribe(sub)
+		err := validator.PollError(ctx.Err())
+		r.mu.Lock()
+		if f.waiters--; f.waiters == 0 {
+			f.cancel()
+			if r.inflight[key] == f {
+				delete(r.inflight, key)
+			}
+			if id := f.knownResumeID(); id != "" {
+				err = fmt.Errorf("%w (call resume_validation with resume_id %q to reattach)", err, id)
+			}
+		}
+		r.mu.Unlock()
+		return nil, false, err
+	}
+}
+
+// fly runs f once a slot is free, records the report of a completed run
+// and keeps it for reuse.
+func (r *validationRunner) fly(ctx context.Context, key string, f *flight, in validator.Input, cred azcore.TokenCredential) {
+	defer f.cancel()
+	var res *completedValidation
+	err := r.acquire(ctx, f.notify)
+	if err == nil {
+		var result *validator.Result
+		result, err = r.run(ctx, in, cred, f.notify, f.setResumeID)
+		<-r.slots
+		if err == nil {
+			res = &completedValidation{result: result, reportURI: r.reports.add(result), at: r.now()}
+		}
+	}
+
+	r.mu.Lock()
+	if r.inflight[key] == f {
+		delete(r.inflight, key)
+	}
+	if err == nil {
+		r.cache[key] = res
+	}
+	r.mu.Unlock()
+
+	f.res, f.err = res, err
+	close(f.done)
+}
+
+// acquire takes a slot, telling the waiters when they have to queue for one.
+func (r *validationRunner) acquire(ctx context.Context, notify validator.ProgressFn) error {
+	select {
+	case r.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	notify(fmt.Sprintf("Waiting for a free validation slot (at most %d run at once)", cap(r.slots)))
+	select {
+	case r.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return validator.PollError(ctx.Err())
+	}
+}
+
+// pruneLocked drops cached results too old for any caller to accept.
+func (r *validationRunner) pruneLocked() {
+	now := r.now()
+	for key, c := range r.cache {
+		if now.Sub(c.at) > MaxResultAge {
+			delete(r.cache, key)
+		}
+	}
+}
+
+// subscribe adds a waiter's callbacks, passing on the resume ID if the run
+// already has one.
+func (f *flight) subscribe(notify validator.ProgressFn, resumeID func(string)) *subscriber {
+	s := &subscriber{notify: notify, resumeID: resumeID}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs[s] = struct{}{}
+	if f.resumeID != "" && resumeID != nil {
+		resumeID(f.resumeID)
+	}
+	return s
+}
+
+func (f *flight) unsubscribe(s *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subs, s)
+}
+
+// notify passes a progress message on to every waiter.
+func (f *flight) notify(message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for s := range f.subs {
+		if s.notify != nil {
+			s.notify(message)
+		}
+	}
+}
+
+func (f *flight) setResumeID(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resumeID = id
+	for s := range f.subs {
+		if s.resumeID != nil {
+			s.resumeID(id)
+		}
+	}
+}
+
+func (f *flight) knownResumeID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resumeID
+}
@@ -0,0 +1,273 @@
+package mcpserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+var runnerInput = validator.Input{
+	SourceSubscriptionID: "11111111-1111-1111-1111-111111111111",
+	SourceResourceGroup:  "rg-src",
+	TargetSubscriptionID: "22222222-2222-2222-2222-222222222222",
+	TargetResourceGroup:  "rg-dst",
+}
+
+// blockingRun returns a runFunc that counts its calls and blocks until
+// release is closed or its context is cancelled.
+func blockingRun(calls *atomic.Int32, release <-chan struct{}) runFunc {
+	return func(ctx context.Context, in validator.Input, _ azcore.TokenCredential, notify validator.ProgressFn, resumeID func(string)) (*validator.Result, error) {
+		calls.Add(1)
+		resumeID("resume-x.json")
+		notify("Polling Azure validate-move")
+		select {
+		case <-release:
+			return &validator.Result{Success: true, HTTPStatusCode: 204, SourceResourceGroup: in.SourceResourceGroup}, nil
+		case <-ctx.Done():
+			return nil, validator.PollError(ctx.Err())
+		}
+	}
+}
+
+func TestValidationKeyNormalises(t *testing.T) {
+	a := runnerInput
+	a.ResourceIDs = []string{"/subscriptions/s/B", "/subscriptions/s/a"}
+	b := runnerInput
+	b.SourceResourceGroup, b.TargetResourceGroup = "RG-SRC", "Rg-Dst"
+	b.ResourceIDs = []string{"/subscriptions/s/A", "/subscriptions/s/b"}
+	b.PollInterval, b.PollTimeout = time.Second, time.Minute
+
+	if validationKey(a, "server") != validationKey(b, "server") {
+		t.Error("inputs differing only in case, order and poll settings have different keys")
+	}
+	if validationKey(a, "server") == validationKey(a, "profile:prod") {
+		t.Error("different credentials share a key")
+	}
+	c := a
+	c.TargetResourceGroup = "rg-other"
+	if validationKey(a, "server") == validationKey(c, "server") {
+		t.Error("different targets share a key")
+	}
+}
+
+func TestCredentialFingerprint(t *testing.T) {
+	c := &credentials{allowInline: true}
+	tokenA := c.fingerprint(CredentialInput{BearerToken: "token-a"})
+	tokenB := c.fingerprint(CredentialInput{BearerToken: "token-b"})
+	if tokenA == tokenB {
+		t.Error("different bearer tokens have the same fingerprint")
+	}
+	if tokenA != c.fingerprint(CredentialInput{BearerToken: "token-a"}) {
+		t.Error("fingerprint is not stable")
+	}
+	if got := c.fingerprint(CredentialInput{CredentialProfile: " prod "}); got != "profile:prod" {
+		t.Errorf("profile fingerprint = %q", got)
+	}
+	if got := c.fingerprint(CredentialInput{}); got != "server" {
+		t.Errorf("server fingerprint = %q", got)
+	}
+}
+
+func TestRunnerDeduplicatesConcurrentCalls(t *testing.T) {
+	r := newValidationRunner(1, nil)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r.run = blockingRun(&calls, release)
+	key := validationKey(runnerInput, "server")
+
+	const callers = 5
+	var wg sync.WaitGroup
+	var joined, resumed atomic.Int32
+	results := make([]*completedValidation, callers)
+	for i := range callers {
+		wg.Go(func() {
+			notify := func(message string) {
+				if message == "Joined an identical validation already in progress" {
+					joined.Add(1)
+				}
+			}
+			res, cached, err := r.validate(t.Context(), key, runnerInput, nil, 0, notify, func(string) { resumed.Add(1) })
+			if err != nil || cached {
+				t.Errorf("caller %d: cached = %v, err = %v", i, cached, err)
+			}
+			results[i] = res
+		})
+	}
+	waitFor(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		f := r.inflight[key]
+		return f != nil && f.waiters == callers
+	})
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("run called %d times, want 1", n)
+	}
+	if n := joined.Load(); n != callers-1 {
+		t.Errorf("%d callers were told they joined, want %d", n, callers-1)
+	}
+	if n := resumed.Load(); n != callers {
+		t.Errorf("%d callers got the resume ID, want %d", n, callers)
+	}
+	for i, res := range results {
+		if res != results[0] {
+			t.Errorf("caller %d got a different result", i)
+		}
+	}
+}
+
+func TestRunnerCachesWithinMaxAge(t *testing.T) {
+	r := newValidationRunner(1, nil)
+	now := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	var calls atomic.Int32
+	release := make(chan struct{})
+	close(release)
+	r.run = blockingRun(&calls, release)
+	key := validationKey(runnerInput, "server")
+
+	validate := func(maxAge time.Duration) bool {
+		t.Helper()
+		_, cached, err := r.validate(t.Context(), key, runnerInput, nil, maxAge, nil, nil)
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		return cached
+	}
+
+	if validate(time.Minute) {
+		t.Error("first call was served from the cache")
+	}
+	now = now.Add(30 * time.Second)
+	if !validate(time.Minute) {
+		t.Error("call within max_age was not served from the cache")
+	}
+	if validate(0) {
+		t.Error("call without max_age was served from the cache")
+	}
+	now = now.Add(2 * time.Minute)
+	if validate(time.Minute) {
+		t.Error("result older than max_age was served from the cache")
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("run called %d times, want 3", n)
+	}
+
+	if _, cached, _ := r.validate(t.Context(), validationKey(runnerInput, "profile:prod"), runnerInput, nil, time.Hour, nil, nil); cached {
+		t.Error("another credential was served the cached result")
+	}
+}
+
+func TestRunnerLimitsConcurrency(t *testing.T) {
+	r := newValidationRunner(2, nil)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r.run = blockingRun(&calls, release)
+
+	var wg sync.WaitGroup
+	var waiting atomic.Int32
+	for _, target := range []string{"rg-a", "rg-b", "rg-c"} {
+		in := runnerInput
+		in.TargetResourceGroup = target
+		wg.Go(func() {
+			notify := func(message string) {
+				if message != "Polling Azure validate-move" {
+					waiting.Add(1)
+				}
+			}
+			if _, _, err := r.validate(t.Context(), validationKey(in, "server"), in, nil, 0, notify, nil); err != nil {
+				t.Errorf("validate %s: %v", target, err)
+			}
+		})
+	}
+	waitFor(t, func() bool { return calls.Load() == 2 && waiting.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("%d validations running, want the limit of 2", n)
+	}
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 3 {
+		t.Errorf("run called %d times, want 3", n)
+	}
+}
+
+func TestRunnerCancelsOnlyWhenLastCallerLeaves(t *testing.T) {
+	r := newValidationRunner(1, nil)
+	var calls atomic.Int32
+	r.run = blockingRun(&calls, make(chan struct{}))
+	key := validationKey(runnerInput, "server")
+
+	ctx1, cancel1 := context.WithCancel(t.Context())
+	ctx2, cancel2 := context.WithCancel(t.Context())
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{ctx1, ctx2} {
+		go func() {
+			_, _, err := r.validate(ctx, key, runnerInput, nil, 0, nil, nil)
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		f := r.inflight[key]
+		return f != nil && f.waiters == 2 && calls.Load() == 1
+	})
+
+	r.mu.Lock()
+	f := r.inflight[key]
+	r.mu.Unlock()
+
+	cancel1()
+	if err := <-errs; validator.KindOf(err) != validator.KindInterrupted {
+		t.Errorf("first caller error = %v, want interrupted", err)
+	}
+	select {
+	case <-f.done:
+		t.Fatal("run was cancelled while a caller still waits on it")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel2()
+	err := <-errs
+	if validator.KindOf(err) != validator.KindInterrupted {
+		t.Errorf("last caller error = %v, want interrupted", err)
+	}
+	if want := `resume_id "resume-x.json"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("last caller error = %v, want it to name %s", err, want)
+	}
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run was not cancelled after the last caller left")
+	}
+	if f.err == nil {
+		t.Error("cancelled run reported success")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cache[key]; ok {
+		t.Error("cancelled run was cached")
+	}
+}
+
+// waitFor polls cond until it holds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	t.Fatal("condition not reached")
+}
@@ -1,17 +1,20 @@
-//go:build ignore
-
 // Package mcpserver exposes ARMV as a Model Context Protocol server so LLM agents
 // (Claude Desktop, Claude Code, etc.) can invoke resource-move validation as a tool.
 //
-// The server speaks MCP over stdio; the host process must not write anything
-// else to stdout or the JSON-RPC framing will break.
+// The server speaks MCP over stdio (Run), where the host process must not
+// write anything else to stdout or the JSON-RPC framing will break, or over
+// streamable HTTP behind a shared bearer token (ServeHTTP).
 package mcpserver
 
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync/atomic"
+	"time"
 
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
 	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 	"github.com/AaronSaikovski/armv/pkg/utils"
@@ -22,86 +25,265 @@ import (
 // serverName is advertised to MCP clients. serverVersion is injected from main at startup.
 const serverName = "armv"
 
-// ValidateMoveInput is the MCP tool input contract. Service principal fields are
-// optional; when all three are supplied they take precedence over the ambient
-// DefaultAzureCredential chain (env vars / managed identity / az CLI).
+// ValidateMoveInput is the MCP tool input contract. The embedded
+// CredentialInput selects the Azure credential; by default the server's own.
+// The target fields are optional in the schema so a client that supports
+// elicitation can leave them for the user to pick; see elicitor.
 type ValidateMoveInput struct {
 	SourceSubscriptionID string `json:"source_subscription_id" jsonschema:"source Azure subscription UUID (required)"`
 	SourceResourceGroup  string `json:"source_resource_group"  jsonschema:"source resource group name (required)"`
-	TargetSubscriptionID string `json:"target_subscription_id" jsonschema:"target Azure subscription UUID (required)"`
-	TargetResourceGroup  string `json:"target_resource_group"  jsonschema:"target resource group name (required)"`
+	TargetSubscriptionID string `json:"target_subscription_id,omitempty" jsonschema:"target Azure subscription UUID (required; when omitted, a client that supports elicitation asks the user to pick one)"`
+	TargetResourceGroup  string `json:"target_resource_group,omitempty"  jsonschema:"target resource group name (required; when omitted, a client that supports elicitation asks the user to pick one)"`
+
+	CredentialInput
 
-	TenantID     string `json:"tenant_id,omitempty"     jsonschema:"optional service principal tenant UUID; supply with client_id and client_secret to bypass DefaultAzureCredential"`
-	ClientID     string `json:"client_id,omitempty"     jsonschema:"optional service principal client (application) UUID"`
-	ClientSecret string `json:"client_secret,omitempty" jsonschema:"optional service principal client secret"`
+	PollIntervalSeconds int `json:"poll_interval_seconds,omitempty" jsonschema:"optional seconds between validate-move polls when Azure sends no Retry-After (default 2, minimum 1)"`
+	PollTimeoutSeconds  int `json:"poll_timeout_seconds,omitempty"  jsonschema:"optional seconds to keep polling before giving up (default 1800)"`
 
-	BearerToken string `json:"bearer_token,omitempty" jsonschema:"optional Azure AD bearer token for https://management.azure.com (obtain via 'az account get-access-token' or similar); when set, takes precedence over all other auth fields and no credentials are stored on the server"`
+	IncludeRawResponse bool `json:"include_raw_response,omitempty" jsonschema:"optional; also return the raw Azure response body in diagnostics (it is always returned when it cannot be parsed)"`
+
+	MaxAgeSeconds int `json:"max_age_seconds,omitempty" jsonschema:"optional; accept the result of an identical validation (same groups, resources and credential) that completed at most this many seconds ago instead of asking Azure again (0 to 900, default 0: always validate afresh)"`
 }
 
-// ValidateMoveOutput is the structured result returned to the MCP client.
+// ValidateMoveOutput is the structured result returned to the MCP client. A
+// failed validation is broken down with poller.BuildValidationReport, so the
+// client gets typed per-resource errors instead of having to parse Azure's
+// JSON itself.
 type ValidateMoveOutput struct {
-	Success               bool     `json:"success"                           jsonschema:"true when the Azure validate-move API returned 204 No Content"`
-	ResourceIDs           []string `json:"resource_ids"                      jsonschema:"fully qualified IDs of every resource enumerated in the source resource group"`
-	TargetResourceGroupID string   `json:"target_resource_group_id"          jsonschema:"fully qualified ID of the target resource group"`
-	HTTPStatusCode        int      `json:"http_status_code"                  jsonschema:"HTTP status code of the final validate-move response (204 = ok, 409 = conflict)"`
-	HTTPStatus            string   `json:"http_status"                       jsonschema:"HTTP status string of the final validate-move response"`
-	Diagnostics           string   `json:"diagnostics,omitempty"             jsonschema:"raw response body, typically the 409 error payload when validation fails"`
+	Success               bool            `json:"success"                  jsonschema:"true when the Azure validate-move API returned 204 No Content"`
+	ResourceIDs           []string        `json:"resource_ids"             jsonschema:"fully qualified IDs of every resource enumerated in the source resource group"`
+	TargetResourceGroupID string          `json:"target_resource_group_id" jsonschema:"fully qualified ID of the target resource group"`
+	HTTPStatusCode        int             `json:"http_status_code"         jsonschema:"HTTP status code of the final validate-move response (204 = ok, 409 = conflict)"`
+	HTTPStatus            string          `json:"http_status"              jsonschema:"HTTP status string of the final validate-move response"`
+	Code                  string          `json:"code,omitempty"           jsonschema:"top-level Azure error code when validation fails, e.g. ResourceMoveProviderValidationFailed"`
+	Message               string          `json:"message,omitempty"        jsonschema:"top-level Azure error message when validation fails"`
+	Errors                []MoveError     `json:"errors,omitempty"         jsonschema:"one entry per resource Azure reported as blocking the move"`
+	Counts                MoveErrorCounts `json:"counts"                   jsonschema:"aggregate counts of validated and failing resources"`
+	Remediation           []string        `json:"remediation,omitempty"    jsonschema:"suggested fixes for the error codes returned, most relevant first"`
+	Diagnostics           string          `json:"diagnostics,omitempty"    jsonschema:"raw Azure response body; present when include_raw_response was set or the body could not be parsed into errors"`
+	ReportURI             string          `json:"report_uri,omitempty" jsonschema:"MCP resource holding the Markdown report of this run; append .json for the JSON form"`
+	Cached                bool            `json:"cached,omitempty"         jsonschema:"true when this is the result of an earlier identical validation, reused because of max_age_seconds"`
+	PollCount             int             `json:"poll_count"               jsonschema:"number of times the long-running operation was polled"`
+	PollDurationSeconds   float64         `json:"poll_duration_seconds"    jsonschema:"wall-clock seconds spent polling the long-running operation"`
+}
+
+// MoveError is one resource that blocks the move.
+type MoveError struct {
+	ResourceID   string `json:"resource_id"             jsonschema:"fully qualified ARM ID of the failing resource, as reported by Azure"`
+	ResourceType string `json:"resource_type"           jsonschema:"provider namespace and type, e.g. Microsoft.Web/sites"`
+	ResourceName string `json:"resource_name"           jsonschema:"last segment of the resource ID"`
+	Code         string `json:"code"                    jsonschema:"Azure error code for this resource"`
+	Message      string `json:"message"                 jsonschema:"Azure error message for this resource"`
+	Remediation  string `json:"remediation,omitempty"   jsonschema:"suggested fix for this error code, when known"`
+}
+
+// MoveErrorCounts summarises a validation result.
+type MoveErrorCounts struct {
+	ResourcesValidated int            `json:"resources_validated"     jsonschema:"number of resources sent to validate-move"`
+	Errors             int            `json:"errors"                  jsonschema:"number of per-resource errors"`
+	ByCode             map[string]int `json:"by_code,omitempty"       jsonschema:"error count per Azure error code"`
+	ByResourceType     map[string]int `json:"by_resource_type,omitempty" jsonschema:"error count per resource type"`
+}
+
+// Options tunes the server's tools. The zero value uses the defaults.
+type Options struct {
+	// MaxJobs bounds how many start_validation jobs poll Azure at once;
+	// further jobs queue. Zero means DefaultMaxJobs.
+	MaxJobs int
+	// MaxValidations bounds how many validations (from validate_move and
+	// start_validation together) run against Azure at once, to keep the
+	// server clear of ARM throttling; further ones wait for a slot. Zero
+	// means DefaultMaxValidations.
+	MaxValidations int
+	// JobRetention is how long a finished job's result can be fetched.
+	// Zero means DefaultJobRetention.
+	JobRetention time.Duration
+	// ReportDir is where completed validation reports are kept and served
+	// from as armv://reports/{id} resources. Empty means DefaultReportDir.
+	ReportDir string
+
+	// Credential is the server's Azure credential, used by tool calls that
+	// name no credential profile. Nil means DefaultAzureCredential.
+	Credential azcore.TokenCredential
+	// CredentialProfiles are further credentials a tool call may select by
+	// name with credential_profile.
+	CredentialProfiles map[string]azcore.TokenCredential
+	// AllowInlineCredentials lets tool calls pass tenant_id, client_id,
+	// client_secret and bearer_token. It is off by default: whatever a
+	// client sends passes through the LLM conversation and client logs.
+	AllowInlineCredentials bool
 }
 
 // Run starts the MCP server on stdio and blocks until ctx is cancelled or the
 // client disconnects. version is surfaced to MCP clients via the Implementation struct.
-func Run(ctx context.Context, version string) error {
-	server := newServer(version)
+func Run(ctx context.Context, version string, opts Options) error {
+	server := newServer(version, opts)
 	return server.Run(ctx, &mcp.StdioTransport{})
 }
 
 // newServer constructs the MCP server with all tools registered. Split from Run
 // so tests can exercise the handlers via in-memory transports.
-func newServer(version string) *mcp.Server {
+func newServer(version string, opts Options) *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: version}, nil)
+	reportDir := opts.ReportDir
+	if reportDir == "" {
+		// Without a cache directory reports are still served, until restart.
+		reportDir, _ = DefaultReportDir()
+	}
+	server.AddReceivingMiddleware(logToolCalls)
+	creds := newCredentials(opts)
+	reports := newReportStore(server, reportDir)
+	runner := newValidationRunner(opts.MaxValidations, reports)
+	elicit := newElicitor()
+	jobs := newJobManager(opts.MaxJobs, opts.JobRetention, runner)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "validate_move",
-		Description: "Validate whether all resources in an Azure source resource group can be moved to a target resource group (optionally in a different subscription) without performing the move. Wraps the Azure 'validate move resources' API.",
-	}, validateMoveHandler)
+		Annotations: readOnlyAnnotations(true, true),
+		InputSchema: inputSchema[ValidateMoveInput](creds),
+		Description: "Validate whether all resources in an Azure source resource group can be moved to a target resource group (optionally in a different subscription) without performing the move. Wraps the Azure 'validate move resources' API. An identical call already in progress is joined rather than repeated; set max_age_seconds to accept a recent identical result without asking Azure again.",
+	}, validateMoveHandler(creds, runner, elicit))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_subscriptions",
+		Annotations: readOnlyAnnotations(true, true),
+		InputSchema: inputSchema[ListSubscriptionsInput](creds),
 		Description: "List every Azure subscription the supplied credential can see. Use this as the first step in a discovery flow before calling validate_move, so you can offer the user a picklist instead of asking them to recall subscription UUIDs.",
-	}, listSubscriptionsHandler)
+	}, listSubscriptionsHandler(creds))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_resource_groups",
-		Description: "List every resource group in a given subscription. Typically called after list_subscriptions and before validate_move, once the user has picked a subscription.",
-	}, listResourceGroupsHandler)
+		Annotations: readOnlyAnnotations(true, true),
+		InputSchema: inputSchema[ListResourceGroupsInput](creds),
+		Description: "List the resource groups in a given subscription. Typically called after list_subscriptions and before validate_move, once the user has picked a subscription. In large subscriptions, filter (name_contains, location, tag), ask for a summary, select fields or page with page_size and cursor to keep the result small.",
+	}, listResourceGroupsHandler(creds))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_resources",
-		Description: "List every Azure resource in a given resource group (name, type, location, ARM ID). Useful for inspecting what's in an RG before running validate_move, or for pinpointing which resource type is likely to block a move.",
-	}, listResourcesHandler)
+		Annotations: readOnlyAnnotations(true, true),
+		InputSchema: inputSchema[ListResourcesInput](creds),
+		Description: "List the Azure resources in a given resource group (name, type, location, ARM ID). Useful for inspecting what's in an RG before running validate_move, or for pinpointing which resource type is likely to block a move. For large groups, start with summary=true for counts per type, then filter (name_contains, type, location, tag), select fields or page with page_size and cursor.",
+	}, listResourcesHandler(creds))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "resume_validation",
+		Annotations: readOnlyAnnotations(true, true),
+		InputSchema: inputSchema[ResumeValidationInput](creds),
+		Description: "Reattach to a validate_move operation that was interrupted (client disconnect, timeout, server restart) and return its result. Omit resume_id to resume the most recent saved operation.",
+	}, resumeValidationHandler(creds, reports))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "start_validation",
+		Annotations: readOnlyAnnotations(false, true),
+		InputSchema: inputSchema[ValidateMoveInput](creds),
+		Description: "Start the same check as validate_move in the background and return a job_id immediately. Prefer this over validate_move when the client may time out: validate-move can take up to 30 minutes. Follow with get_validation_status, then get_validation_result.",
+	}, jobs.startValidationHandler(creds, elicit))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_validation_status",
+		Annotations: readOnlyAnnotations(true, false),
+		Description: "Return the state (queued, running, completed, failed, cancelled) and latest progress message of a start_validation job.",
+	}, jobs.getValidationStatusHandler)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_validation_result",
+		Annotations: readOnlyAnnotations(true, false),
+		Description: "Return the result of a finished start_validation job, in the same shape as validate_move. Fails while the job is still queued or running.",
+	}, jobs.getValidationResultHandler)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "cancel_validation",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: new(false), IdempotentHint: true, OpenWorldHint: new(false)},
+		Description: "Cancel a queued or running start_validation job. Validate-move is read-only, so nothing needs cleaning up in Azure. The Azure operation keeps running while an identical validate_move call or job still waits on it.",
+	}, jobs.cancelValidationHandler)
+
+	registerPrompts(server, reports)
 
 	return server
 }
 
-func validateMoveHandler(ctx context.Context, req *mcp.CallToolRequest, in ValidateMoveInput) (*mcp.CallToolResult, ValidateMoveOutput, error) {
-	cred, err := selectCredential(in.TenantID, in.ClientID, in.ClientSecret, in.BearerToken)
-	if err != nil {
-		return toolError(err), ValidateMoveOutput{}, nil
-	}
+// readOnlyAnnotations marks a tool that changes nothing, so clients may run
+// it without asking. openWorld is set for tools that call Azure. Even
+// start_validation is read-only: its job lives only in this server.
+func readOnlyAnnotations(idempotent, openWorld bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: idempotent, OpenWorldHint: new(openWorld)}
+}
 
-	if err := validateInputs(in); err != nil {
-		return toolError(err), ValidateMoveOutput{}, nil
+// validateMoveHandler returns the validate_move handler, which authenticates
+// with creds, asks the user through elicit for missing or ambiguous
+// arguments and runs the validation through runner.
+func validateMoveHandler(creds *credentials, runner *validationRunner, elicit *elicitor) mcp.ToolHandlerFor[ValidateMoveInput, ValidateMoveOutput] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in ValidateMoveInput) (*mcp.CallToolResult, ValidateMoveOutput, error) {
+		cred, err := creds.resolve(in.CredentialInput)
+		if err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ValidateMoveOutput{}, nil
+		}
+
+		in, pending, err := elicit.complete(ctx, req, cred, in)
+		if err != nil {
+			return toolError(err), ValidateMoveOutput{}, nil
+		}
+		if pending != nil {
+			return pending, ValidateMoveOutput{}, nil
+		}
+
+		if err := validateInputs(in); err != nil {
+			return toolError(validator.NewError(validator.KindInvalidInput, err)), ValidateMoveOutput{}, nil
+		}
+
+		vin := validatorInput(in)
+		res, cached, err := runner.validate(ctx, validationKey(vin, creds.fingerprint(in.CredentialInput)), vin, cred,
+			time.Duration(in.MaxAgeSeconds)*time.Second, progressNotifier(ctx, req), nil)
+		if err != nil {
+			return toolError(err), ValidateMoveOutput{}, nil
+		}
+		return nil, res.output(in.IncludeRawResponse, cached), nil
 	}
+}
 
-	result, err := validator.Validate(ctx, validator.Input{
+func validatorInput(in ValidateMoveInput) validator.Input {
+	return validator.Input{
 		SourceSubscriptionID: in.SourceSubscriptionID,
 		SourceResourceGroup:  in.SourceResourceGroup,
 		TargetSubscriptionID: in.TargetSubscriptionID,
 		TargetResourceGroup:  in.TargetResourceGroup,
-	}, cred, progressNotifier(ctx, req))
+		PollInterval:         time.Duration(in.PollIntervalSeconds) * time.Second,
+		PollTimeout:          time.Duration(in.PollTimeoutSeconds) * time.Second,
+	}
+}
+
+// waitForResult polls op to completion, records the report and shapes the
+// result for the client.
+func waitForResult(ctx context.Context, op *validator.Operation, resumeFile string, includeRaw bool, reports *reportStore) (*mcp.CallToolResult, ValidateMoveOutput, error) {
+	result, err := waitForOperation(ctx, op, resumeFile)
 	if err != nil {
 		return toolError(err), ValidateMoveOutput{}, nil
 	}
+	return nil, reports.output(result, includeRaw), nil
+}
+
+// waitForOperation polls op to completion. The resume file is removed once
+// the operation finishes and kept if polling fails, so resume_validation can
+// pick it up.
+func waitForOperation(ctx context.Context, op *validator.Operation, resumeFile string) (*validator.Result, error) {
+	result, err := op.Wait(ctx)
+	if err != nil {
+		if resumeFile != "" {
+			err = fmt.Errorf("%w (call resume_validation with resume_id %q to reattach)", err, filepath.Base(resumeFile))
+		}
+		return nil, err
+	}
+	if resumeFile != "" {
+		_ = os.Remove(resumeFile)
+	}
+	return result, nil
+}
+
+// newValidateMoveOutput converts a validation result into the tool output,
+// parsing a failure body into typed errors, counts and remediation hints.
+func newValidateMoveOutput(result *validator.Result, includeRaw bool) ValidateMoveOutput {
+	summary := poller.BuildValidationSummary(result.RunRecord(time.Now()))
+	report := summary.Report
 
 	out := ValidateMoveOutput{
 		Success:               result.Success,
@@ -109,21 +291,45 @@ func validateMoveHandler(ctx context.Context, req *mcp.CallToolRequest, in Valid
 		TargetResourceGroupID: result.TargetResourceGroupID,
 		HTTPStatusCode:        result.HTTPStatusCode,
 		HTTPStatus:            result.HTTPStatus,
+		Code:                  report.TopLevel.Code,
+		Message:               report.TopLevel.Message,
+		Counts: MoveErrorCounts{
+			ResourcesValidated: len(result.ResourceIDs),
+			Errors:             len(summary.Errors),
+			ByCode:             summary.ByCode,
+			ByResourceType:     summary.ByResourceType,
+		},
+		Remediation:         poller.Remediations(report),
+		PollCount:           result.PollCount,
+		PollDurationSeconds: result.PollDuration.Seconds(),
+	}
+	for _, e := range summary.Errors {
+		out.Errors = append(out.Errors, MoveError{
+			ResourceID:   e.ResourceID,
+			ResourceType: e.ResourceType,
+			ResourceName: e.ResourceName,
+			Code:         e.Code,
+			Message:      e.Message,
+			Remediation:  e.Remediation,
+		})
 	}
-	if !result.Success && len(result.ResponseBody) > 0 {
+
+	// Without a parsed code the raw body is the only diagnosis there is.
+	unparsed := !result.Success && report.TopLevel.Code == ""
+	if len(result.ResponseBody) > 0 && (includeRaw || unparsed) {
 		out.Diagnostics = string(result.ResponseBody)
 	}
-	return nil, out, nil
+	return out
 }
 
-// selectCredential resolves the credential to use for this call, in priority order:
+// selectCredential resolves inline credentials, in priority order:
 //  1. bearer_token (client-supplied access token; nothing cached on the server)
 //  2. tenant_id + client_id + client_secret (service principal)
 //  3. DefaultAzureCredential (az login, managed identity, env vars, etc.)
 //
 // Mixing a bearer token with SP fields is rejected as ambiguous; partial SP input
 // is rejected to surface configuration mistakes instead of silently falling back.
-// Shared across all tools so every handler accepts the same auth fields.
+// Only reached when the server allows inline credentials; see credentials.resolve.
 func selectCredential(tenantID, clientID, clientSecret, bearerToken string) (azcore.TokenCredential, error) {
 	spCount := 0
 	if tenantID != "" {
@@ -172,13 +378,22 @@ func validateInputs(in ValidateMoveInput) error {
 	if in.TargetResourceGroup == "" {
 		return fmt.Errorf("target_resource_group is required")
 	}
+	if in.PollIntervalSeconds < 0 || in.PollTimeoutSeconds < 0 {
+		return fmt.Errorf("poll_interval_seconds and poll_timeout_seconds must not be negative")
+	}
+	if maxAge := time.Duration(in.MaxAgeSeconds) * time.Second; maxAge < 0 || maxAge > MaxResultAge {
+		return fmt.Errorf("max_age_seconds must be between 0 and %d, got %d", int(MaxResultAge.Seconds()), in.MaxAgeSeconds)
+	}
 	return nil
 }
 
+// toolError wraps err as an IsError tool result. The text is prefixed with the
+// validator.Kind code (e.g. "[resource_group_not_found]") so the client sees
+// the same outcome categories as the CLI's exit codes.
 func toolError(err error) *mcp.CallToolResult {
 	return &mcp.CallToolResult{
 		IsError: true,
-		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s] %s", validator.KindOf(err), err)}},
 	}
 }
 
@@ -1,13 +1,13 @@
-//go:build ignore
-
 package mcpserver
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -17,18 +17,18 @@ func TestSelectCredential(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		in       ValidateMoveInput
+		in       CredentialInput
 		wantType string // "default", "sp", "bearer", or "" when an error is expected
 		wantErr  string // substring the error must contain
 	}{
 		{
 			name:     "no auth fields -> DefaultAzureCredential (picks up az login)",
-			in:       ValidateMoveInput{},
+			in:       CredentialInput{},
 			wantType: "default",
 		},
 		{
 			name: "all three SP fields -> ClientSecretCredential",
-			in: ValidateMoveInput{
+			in: CredentialInput{
 				TenantID:     validUUID,
 				ClientID:     validUUID,
 				ClientSecret: "secret-value",
@@ -37,12 +37,12 @@ func TestSelectCredential(t *testing.T) {
 		},
 		{
 			name:     "bearer_token only -> StaticTokenCredential",
-			in:       ValidateMoveInput{BearerToken: "eyJhbGciOi.fake.token"},
+			in:       CredentialInput{BearerToken: "eyJhbGciOi.fake.token"},
 			wantType: "bearer",
 		},
 		{
 			name: "bearer_token + any SP field -> error",
-			in: ValidateMoveInput{
+			in: CredentialInput{
 				BearerToken: "eyJhbGciOi.fake.token",
 				TenantID:    validUUID,
 			},
@@ -50,17 +50,17 @@ func TestSelectCredential(t *testing.T) {
 		},
 		{
 			name:    "only tenant_id set -> error",
-			in:      ValidateMoveInput{TenantID: validUUID},
+			in:      CredentialInput{TenantID: validUUID},
 			wantErr: "require all three",
 		},
 		{
 			name:    "tenant_id + client_id, missing secret -> error",
-			in:      ValidateMoveInput{TenantID: validUUID, ClientID: validUUID},
+			in:      CredentialInput{TenantID: validUUID, ClientID: validUUID},
 			wantErr: "require all three",
 		},
 		{
 			name: "invalid tenant_id -> error",
-			in: ValidateMoveInput{
+			in: CredentialInput{
 				TenantID:     "not-a-uuid",
 				ClientID:     validUUID,
 				ClientSecret: "secret",
@@ -69,7 +69,7 @@ func TestSelectCredential(t *testing.T) {
 		},
 		{
 			name: "invalid client_id -> error",
-			in: ValidateMoveInput{
+			in: CredentialInput{
 				TenantID:     validUUID,
 				ClientID:     "not-a-uuid",
 				ClientSecret: "secret",
@@ -197,7 +197,7 @@ func TestNewServerRegistersValidateMove(t *testing.T) {
 	// Smoke test: constructing the server must not panic, and it should at least
 	// return a non-nil *mcp.Server. AddTool panics on schema errors, so reaching
 	// here proves the ValidateMoveInput/Output schemas are inference-compatible.
-	s := newServer("test-version")
+	s := newServer("test-version", Options{ReportDir: t.TempDir()})
 	if s == nil {
 		t.Fatal("newServer returned nil")
 	}
@@ -231,3 +231,109 @@ func TestProgressNotifier_WithToken(t *testing.T) {
 		t.Fatal("expected non-nil ProgressFn when progress token was supplied, got nil")
 	}
 }
+
+func TestResolveResumeFileRejectsPaths(t *testing.T) {
+	for _, id := range []string{"../resume-x.json", "/etc/passwd", `..\resume-x.json`, "other.json"} {
+		if _, err := resolveResumeFile(id); err == nil {
+			t.Errorf("resolveResumeFile(%q) returned no error", id)
+		}
+	}
+	got, err := resolveResumeFile("resume-2026-04-20-10-45-12-rg.json")
+	if err != nil {
+		t.Fatalf("resolveResumeFile() error = %v", err)
+	}
+	if filepath.Base(got) != "resume-2026-04-20-10-45-12-rg.json" {
+		t.Errorf("resolveResumeFile() = %q", got)
+	}
+}
+
+// TestResumeValidationToolRejectsUnknownState calls resume_validation over
+// a client session: a resume_id that is not a saved operation is an input
+// error, and so is resuming when nothing was saved. It points the user cache
+// directory at a temp dir, so it cannot run in parallel.
+func TestResumeValidationToolRejectsUnknownState(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cs := connectInMemory(t, t.Context(), newTestServer(t), nil, nil)
+
+	for name, tt := range map[string]struct {
+		args    map[string]any
+		wantErr string
+	}{
+		"path as resume_id": {args: map[string]any{"resume_id": "../resume-x.json"}, wantErr: "invalid resume_id"},
+		"unknown resume_id": {args: map[string]any{"resume_id": "resume-2026-04-20-10-45-12-rg.json"}, wantErr: "resume-2026-04-20-10-45-12-rg.json"},
+		"nothing saved":     {args: map[string]any{}, wantErr: "resume"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "resume_validation", Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("CallTool: %v", err)
+			}
+			if !res.IsError {
+				t.Fatalf("resume_validation succeeded: %+v", res)
+			}
+			text := res.Content[0].(*mcp.TextContent).Text
+			if !strings.HasPrefix(text, "[invalid_input]") || !strings.Contains(text, tt.wantErr) {
+				t.Errorf("error = %q, want an invalid_input error mentioning %q", text, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewValidateMoveOutputStructuresConflict(t *testing.T) {
+	body := []byte(`{"error":{"code":"ResourceMoveProviderValidationFailed","message":"Resource move validation failed.","details":[
+		{"code":"MissingMoveDependentResources","target":"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/app","message":"needs its plan"},
+		{"code":"ResourceMoveNotSupported","target":"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/api","message":"not supported"}]}}`)
+	result := &validator.Result{
+		ResourceIDs:    []string{"a", "b", "c"},
+		HTTPStatusCode: 409,
+		HTTPStatus:     "409 Conflict",
+		ResponseBody:   body,
+	}
+
+	out := newValidateMoveOutput(result, false)
+	if out.Success || out.Code != "ResourceMoveProviderValidationFailed" || out.Message == "" {
+		t.Fatalf("top level = %v %q %q", out.Success, out.Code, out.Message)
+	}
+	if len(out.Errors) != 2 {
+		t.Fatalf("len(Errors) = %d, want 2", len(out.Errors))
+	}
+	first := out.Errors[0]
+	if first.ResourceType != "Microsoft.Web/sites" || first.ResourceName != "app" || first.Code != "MissingMoveDependentResources" || first.Remediation == "" {
+		t.Errorf("Errors[0] = %+v", first)
+	}
+	if c := out.Counts; c.ResourcesValidated != 3 || c.Errors != 2 || c.ByResourceType["Microsoft.Web/sites"] != 2 || c.ByCode["ResourceMoveNotSupported"] != 1 {
+		t.Errorf("Counts = %+v", c)
+	}
+	if len(out.Remediation) != 2 {
+		t.Errorf("Remediation = %q, want 2 hints", out.Remediation)
+	}
+	if out.Diagnostics != "" {
+		t.Error("Diagnostics set without include_raw_response")
+	}
+
+	if out := newValidateMoveOutput(result, true); out.Diagnostics != string(body) {
+		t.Errorf("Diagnostics = %q, want the raw body", out.Diagnostics)
+	}
+}
+
+func TestNewValidateMoveOutputKeepsUnparsedBody(t *testing.T) {
+	result := &validator.Result{HTTPStatusCode: 500, HTTPStatus: "500 Internal Server Error", ResponseBody: []byte("upstream failure")}
+	out := newValidateMoveOutput(result, false)
+	if out.Diagnostics != "upstream failure" {
+		t.Errorf("Diagnostics = %q, want the raw body when it cannot be parsed", out.Diagnostics)
+	}
+	if out.Errors != nil || out.Counts.Errors != 0 {
+		t.Errorf("unexpected errors: %+v", out.Errors)
+	}
+}
+
+func TestNewValidateMoveOutputSuccess(t *testing.T) {
+	result := &validator.Result{Success: true, ResourceIDs: []string{"a"}, HTTPStatusCode: 204, HTTPStatus: "204 No Content"}
+	out := newValidateMoveOutput(result, true)
+	if !out.Success || out.Code != "" || out.Errors != nil || out.Remediation != nil || out.Diagnostics != "" {
+		t.Errorf("unexpected output for success: %+v", out)
+	}
+	if out.Counts.ResourcesValidated != 1 {
+		t.Errorf("ResourcesValidated = %d, want 1", out.Counts.ResourcesValidated)
+	}
+}
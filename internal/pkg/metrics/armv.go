@@ -0,0 +1,77 @@
+package metrics
+
+import "time"
+
+// Phase labels for ValidationPhaseDuration. They mirror the workflow
+// phases the CLI reports (poller.Phase).
+const (
+	PhaseLoginCheck     = "login_check"
+	PhaseResourceGroups = "resource_groups"
+	PhaseEnumerating    = "enumerating"
+	PhaseSubmitting     = "submitting"
+	PhasePolling        = "polling"
+)
+
+// Throttle sources for Throttled.
+const (
+	// ThrottleARM counts 429 responses to any ARM request, including each
+	// attempt the SDK retries.
+	ThrottleARM = "arm_request"
+	// ThrottlePoll counts validate-move polls the poller had to back off
+	// from after the SDK gave up retrying.
+	ThrottlePoll = "poll"
+)
+
+var (
+	// Validations counts finished validations by outcome: "valid" or
+	// "invalid" when Azure gave a verdict, otherwise the error kind
+	// (invalid_input, auth_failed, poll_timeout, interrupted, ...).
+	Validations = NewCounterVec("armv_validations_total",
+		"Validations finished, by outcome.", "outcome")
+
+	// ValidationPhaseDuration times each phase of a validation.
+	ValidationPhaseDuration = NewHistogramVec("armv_validation_phase_duration_seconds",
+		"Time spent in each validation phase.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800}, "phase")
+
+	// Polls counts validate-move poll requests.
+	Polls = NewCounterVec("armv_validate_move_polls_total",
+		"Validate-move poll requests sent to Azure.")
+
+	// ARMRequestDuration times every ARM request attempt by HTTP method and
+	// response status code ("error" when no response arrived).
+	ARMRequestDuration = NewHistogramVec("armv_arm_request_duration_seconds",
+		"Latency of ARM requests, by method and status code.",
+		[]float64{0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "method", "code")
+
+	// Throttled counts throttling by Azure, by where it was seen.
+	Throttled = NewCounterVec("armv_throttling_events_total",
+		"Throttling responses from Azure, by source (arm_request or poll).", "source")
+
+	// JobsInFlight is the number of background validation jobs queued or
+	// running in the REST API or MCP server.
+	JobsInFlight = NewGaugeVec("armv_jobs_in_flight",
+		"Validation jobs not yet finished, by state (queued or running).", "state")
+)
+
+// Default holds every ARMV metric; Handler serves it.
+var Default = new(Registry)
+
+func init() {
+	Default.Register(Validations, ValidationPhaseDuration, Polls, ARMRequestDuration, Throttled, JobsInFlight)
+}
+
+// TimePhase starts timing phase and returns the function that records it.
+// Only the first call of the returned function records, so it can be both
+// called when the phase ends and deferred to cover early returns.
+func TimePhase(phase string) (stop func()) {
+	start := time.Now()
+	var done bool
+	return func() {
+		if done {
+			return
+		}
+		done = true
+		ValidationPhaseDuration.With(phase).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,342 @@
+// Package metrics collects ARMV's operational metrics and serves them in the
+// Prometheus text exposition format, for the long-running server modes
+// (`armv serve` and `armv mcp serve --listen`). It implements just the
+// counters, gauges and histograms ARMV needs, so no client library is
+// pulled in; the metrics themselves are declared in armv.go.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// Path is where the servers expose the metrics.
+	Path = "/metrics"
+
+	// ContentType is the Prometheus text exposition format, version 0.0.4.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// labelSep joins label values into a map key; it cannot occur in UTF-8.
+const labelSep = "\xff"
+
+// value is a float64 updated atomically.
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (v *value) load() float64 { return math.Float64frombits(v.bits.Load()) }
+
+// Counter is a value that only goes up.
+type Counter struct{ v value }
+
+// Inc adds one.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add adds delta, which must not be negative.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.v.add(delta)
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 { return c.v.load() }
+
+// Gauge is a value that goes up and down.
+type Gauge struct{ v value }
+
+// Inc adds one.
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec subtracts one.
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return g.v.load() }
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	upper []float64 // bucket upper bounds, ascending; +Inf is implicit
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// vec is a metric family: one child per combination of label values.
+type vec[T any] struct {
+	name, help, kind string
+	labels           []string
+	newChild         func() *T
+
+	mu       sync.RWMutex
+	children map[string]*T
+}
+
+func newVec[T any](name, help, kind string, labels []string, newChild func() *T) *vec[T] {
+	v := &vec[T]{name: name, help: help, kind: kind, labels: labels, newChild: newChild, children: make(map[string]*T)}
+	if len(labels) == 0 {
+		// A family without labels always has its single child, so it is
+		// exposed (at zero) before anything is recorded.
+		v.children[""] = newChild()
+	}
+	return v
+}
+
+// With returns the child for values, one per label in declaration order,
+// creating it on first use.
+func (v *vec[T]) With(values ...string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, labelSep)
+	v.mu.RLock()
+	c, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return c
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok = v.children[key]; !ok {
+		c = v.newChild()
+		v.children[key] = c
+	}
+	return c
+}
+
+// each calls fn for every child, ordered by label values.
+func (v *vec[T]) each(fn func(values []string, child *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	children := make(map[string]*T, len(v.children))
+	for k, c := range v.children {
+		children[k] = c
+	}
+	v.mu.RUnlock()
+
+	slices.Sort(keys)
+	for _, k := range keys {
+		var values []string
+		if len(v.labels) > 0 {
+			values = strings.Split(k, labelSep)
+		}
+		fn(values, children[k])
+	}
+}
+
+func (v *vec[T]) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.kind)
+}
+
+// CounterVec is a family of counters partitioned by labels.
+type CounterVec struct{ *vec[Counter] }
+
+// NewCounterVec returns a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, "counter", labels, func() *Counter { return new(Counter) })}
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.header(w)
+	c.each(func(values []string, child *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, values), formatValue(child.v.load()))
+	})
+}
+
+// GaugeVec is a family of gauges partitioned by labels.
+type GaugeVec struct{ *vec[Gauge] }
+
+// NewGaugeVec returns a gauge family with the given label names.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, "gauge", labels, func() *Gauge { return new(Gauge) })}
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.header(w)
+	g.each(func(values []string, child *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(g.labels, values), formatValue(child.v.load()))
+	})
+}
+
+// HistogramVec is a family of histograms partitioned by labels, sharing
+// one set of bucket upper bounds.
+type HistogramVec struct{ *vec[Histogram] }
+
+// NewHistogramVec returns a histogram family with the given ascending
+// bucket upper bounds and label names.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !slices.IsSorted(buckets) {
+		panic("metrics: " + name + " buckets must be ascending")
+	}
+	upper := slices.Clone(buckets)
+	return &HistogramVec{newVec(name, help, "histogram", labels, func() *Histogram {
+		return &Histogram{upper: upper, counts: make([]uint64, len(upper)+1)}
+	})}
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.header(w)
+	leLabels := append(slices.Clone(h.labels), "le")
+	h.each(func(values []string, child *Histogram) {
+		child.mu.Lock()
+		counts := slices.Clone(child.counts)
+		sum, count := child.sum, child.count
+		child.mu.Unlock()
+
+		var cumulative uint64
+		for i, n := range counts {
+			cumulative += n
+			le := math.Inf(1)
+			if i < len(child.upper) {
+				le = child.upper[i]
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(leLabels, append(slices.Clone(values), formatValue(le))), cumulative)
+		}
+		labels := formatLabels(h.labels, values)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatValue(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, count)
+	})
+}
+
+// collector is a metric family a Registry can expose.
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry is an ordered set of metric families.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// Register adds families to r; they are exposed in registration order.
+func (r *Registry) Register(families ...collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, families...)
+}
+
+// WriteTo writes every family to w in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, c := range collectors {
+		c.write(cw)
+	}
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// Handler serves r to scrapers.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = r.WriteTo(w)
+	})
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler { return Default.Handler() }
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
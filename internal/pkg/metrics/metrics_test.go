@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWritesExpositionFormat(t *testing.T) {
+	t.Parallel()
+
+	counter := NewCounterVec("test_requests_total", "Requests.\nSecond line.", "code", "path")
+	counter.With("200", `/a"b`).Add(2)
+	counter.With("200", "/").Inc()
+	gauge := NewGaugeVec("test_in_flight", "In flight.")
+	gauge.With().Inc()
+	gauge.With().Inc()
+	gauge.With().Dec()
+	hist := NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.5, 1}, "op")
+	hist.With("get").Observe(0.5)
+	hist.With("get").Observe(0.75)
+	hist.With("get").Observe(3)
+
+	r := new(Registry)
+	r.Register(counter, gauge, hist)
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP test_requests_total Requests.\nSecond line.
+# TYPE test_requests_total counter
+test_requests_total{code="200",path="/"} 1
+test_requests_total{code="200",path="/a\"b"} 2
+# HELP test_in_flight In flight.
+# TYPE test_in_flight gauge
+test_in_flight 1
+# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="get",le="0.5"} 1
+test_duration_seconds_bucket{op="get",le="1"} 2
+test_duration_seconds_bucket{op="get",le="+Inf"} 3
+test_duration_seconds_sum{op="get"} 4.25
+test_duration_seconds_count{op="get"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteTo() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnlabelledFamilyIsExposedAtZero(t *testing.T) {
+	t.Parallel()
+
+	r := new(Registry)
+	r.Register(NewCounterVec("test_total", "Total."), NewCounterVec("test_by_kind_total", "By kind.", "kind"))
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "\ntest_total 0\n") {
+		t.Errorf("unlabelled counter not exposed at zero:\n%s", b.String())
+	}
+	if strings.Contains(b.String(), "test_by_kind_total{") {
+		t.Errorf("labelled counter exposed before use:\n%s", b.String())
+	}
+}
+
+func TestWithPanicsOnWrongLabelCount(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("With() with a missing label value did not panic")
+		}
+	}()
+	NewCounterVec("test_total", "Total.", "a", "b").With("only-a")
+}
+
+func TestTimePhaseRecordsOnce(t *testing.T) {
+	t.Parallel()
+
+	phase := "test_phase_" + t.Name()
+	stop := TimePhase(phase)
+	stop()
+	stop()
+	if got := ValidationPhaseDuration.With(phase).Count(); got != 1 {
+		t.Errorf("observations = %d, want 1", got)
+	}
+}
+
+func TestHandlerServesDefaultRegistry(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, name := range []string{
+		"armv_validations_total",
+		"armv_validation_phase_duration_seconds",
+		"armv_validate_move_polls_total",
+		"armv_arm_request_duration_seconds",
+		"armv_throttling_events_total",
+		"armv_jobs_in_flight",
+	} {
+		if !strings.Contains(rec.Body.String(), "# TYPE "+name+" ") {
+			t.Errorf("metric %s not exposed", name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+var metricsOnce sync.Once
+
+// EnableMetrics registers a policy that records the latency and status code
+// of every ARM request, and each 429 as a throttling event, in the metrics
+// package. The server modes call it before exposing /metrics. Calling it
+// more than once has no further effect.
+func EnableMetrics() {
+	metricsOnce.Do(func() {
+		AddPerRetryPolicy(metricsPolicy{})
+	})
+}
+
+// metricsPolicy runs per retry, so every attempt is timed and a throttled
+// attempt the SDK retries is still counted.
+type metricsPolicy struct{}
+
+func (metricsPolicy) Do(req *policy.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := req.Next()
+	elapsed := time.Since(start)
+
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			metrics.Throttled.With(metrics.ThrottleARM).Inc()
+		}
+	}
+	metrics.ARMRequestDuration.With(req.Raw().Method, code).Observe(elapsed.Seconds())
+	return resp, err
+}
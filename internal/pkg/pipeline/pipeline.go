@@ -0,0 +1,108 @@
+// Package pipeline holds the Azure SDK client options shared by every ARM
+// client ARMV creates. Cross-cutting HTTP behaviour (correlation IDs, the
+// read-only guard, request tracing) is added here once as azcore pipeline
+// policies, instead of in each package that constructs a client.
+package pipeline
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// HeaderCorrelationRequestID is the ARM header that ties together every
+// request made for one operation. ARMV sets it to a per-process ID so all
+// calls from one run can be found in Azure activity logs.
+const HeaderCorrelationRequestID = "x-ms-correlation-request-id"
+
+var (
+	mu               sync.RWMutex
+	perCallPolicies  []policy.Policy
+	perRetryPolicies []policy.Policy
+	cloudConfig      cloud.Configuration
+	transport        policy.Transporter
+
+	correlationOnce sync.Once
+	correlationID   string
+)
+
+// ClientOptions returns options for an ARM client factory carrying every
+// registered policy. Call it each time a client is built so policies added
+// after start-up (e.g. by --trace-http) are picked up.
+func ClientOptions() *arm.ClientOptions {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return &arm.ClientOptions{ClientOptions: policy.ClientOptions{
+		Cloud:            cloudConfig,
+		Transport:        transport,
+		PerCallPolicies:  append([]policy.Policy{correlationPolicy{}, readOnlyPolicy{}}, perCallPolicies...),
+		PerRetryPolicies: append([]policy.Policy(nil), perRetryPolicies...),
+	}}
+}
+
+// SetCloud points every ARM client at c instead of the Azure public cloud.
+// The zero Configuration restores the default. Tests use it, with
+// SetTransport, to run the real clients against a local fake ARM server.
+func SetCloud(c cloud.Configuration) {
+	mu.Lock()
+	defer mu.Unlock()
+	cloudConfig = c
+}
+
+// SetTransport sends every ARM request through t instead of the SDK's
+// default HTTP client; nil restores the default.
+func SetTransport(t policy.Transporter) {
+	mu.Lock()
+	defer mu.Unlock()
+	transport = t
+}
+
+// AddPerCallPolicy registers p to run once per client call, before retries.
+func AddPerCallPolicy(p policy.Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	perCallPolicies = append(perCallPolicies, p)
+}
+
+// AddPerRetryPolicy registers p to run on every attempt, after the
+// authentication policy, so it sees the request exactly as sent.
+func AddPerRetryPolicy(p policy.Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	perRetryPolicies = append(perRetryPolicies, p)
+}
+
+// CorrelationID returns the x-ms-correlation-request-id sent with every ARM
+// request from this process.
+func CorrelationID() string {
+	correlationOnce.Do(func() {
+		correlationID = newUUID()
+	})
+	return correlationID
+}
+
+// correlationPolicy stamps CorrelationID on requests that do not already
+// carry a correlation ID.
+type correlationPolicy struct{}
+
+func (correlationPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if req.Raw().Header.Get(HeaderCorrelationRequestID) == "" {
+		req.Raw().Header.Set(HeaderCorrelationRequestID, CorrelationID())
+	}
+	return req.Next()
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// ErrMutatingRequest is returned, wrapped, for an ARM request the read-only
+// guard blocked before it was sent.
+var ErrMutatingRequest = errors.New("pipeline: mutating ARM request blocked: armv is read-only")
+
+// validateMovePath is the one POST ARMV sends: the validate-move action on
+// a resource group. Everything else it does is GET (including polling).
+var validateMovePath = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/validateMoveResources$`)
+
+var mutationsAllowed atomic.Bool
+
+// AllowMutatingRequests turns the read-only guard off for the rest of the
+// process. Nothing in ARMV calls it today; a command that really changes
+// Azure (such as a future --execute) must opt in explicitly.
+func AllowMutatingRequests() {
+	mutationsAllowed.Store(true)
+}
+
+// readOnlyPolicy fails every request except GET, HEAD and the validate-move
+// POST, so no code path can change Azure by accident. It runs per call,
+// before retries, so a blocked request is never sent.
+type readOnlyPolicy struct{}
+
+func (readOnlyPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if mutationsAllowed.Load() || readOnlyRequest(raw) {
+		return req.Next()
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrMutatingRequest, raw.Method, RedactURL(raw.URL))
+}
+
+func readOnlyRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return validateMovePath.MatchString(r.URL.Path)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces every secret ARMV removes from logs and bundles.
+const Redacted = "REDACTED"
+
+// maxTraceBody caps how much of a request or response body is logged.
+const maxTraceBody = 8 << 10
+
+var (
+	// sensitiveHeaders never have their values logged.
+	sensitiveHeaders = map[string]bool{
+		"Authorization":                  true,
+		"Proxy-Authorization":            true,
+		"Cookie":                         true,
+		"Set-Cookie":                     true,
+		"X-Ms-Authorization-Auxiliary":   true,
+		"Ocp-Apim-Subscription-Key":      true,
+		"X-Ms-Client-Principal-Secret":   true,
+		"X-Ms-Encryption-Key":            true,
+		"X-Ms-Copy-Source-Authorization": true,
+	}
+
+	// sensitiveQueryParams covers SAS signatures and OAuth parameters.
+	sensitiveQueryParams = map[string]bool{
+		"sig": true, "signature": true, "code": true, "token": true, "key": true,
+		"access_token": true, "refresh_token": true, "client_secret": true, "password": true,
+	}
+
+	// sensitiveJSONKey matches object keys whose string values are secrets.
+	sensitiveJSONKey = regexp.MustCompile(`(?i)(password|secret|token|key|credential|connectionstring|sas)`)
+
+	// bearerPattern and jwtPattern catch tokens embedded in free text.
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	secretPairs   = regexp.MustCompile(`(?i)((?:password|secret|token|sig|key)=)[^&\s"]+`)
+)
+
+// RedactHeaders returns a copy of h with secret header values replaced and
+// multi-valued headers joined, suitable as a log attribute.
+func RedactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		canonical := http.CanonicalHeaderKey(name)
+		if sensitiveHeaders[canonical] {
+			out[canonical] = Redacted
+			continue
+		}
+		out[canonical] = RedactString(strings.Join(values, ", "))
+	}
+	return out
+}
+
+// RedactURL returns u as a string with secret query parameter values removed.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	q := u.Query()
+	changed := false
+	for name := range q {
+		if sensitiveQueryParams[strings.ToLower(name)] {
+			q.Set(name, Redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	clone := *u
+	clone.RawQuery = q.Encode()
+	return clone.String()
+}
+
+// RedactBody returns body as text with secrets removed, truncated to
+// maxTraceBody. JSON bodies have the values of secret-looking keys replaced;
+// anything else has bearer tokens, JWTs and key=value secrets masked.
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var doc any
+	text := ""
+	if json.Unmarshal(body, &doc) == nil {
+		if redacted, err := json.Marshal(redactJSON(doc)); err == nil {
+			text = string(redacted)
+		}
+	}
+	if text == "" {
+		text = RedactString(string(body))
+	}
+
+	if len(text) > maxTraceBody {
+		return text[:maxTraceBody] + "…(truncated)"
+	}
+	return text
+}
+
+// RedactString masks bearer tokens, JWTs and key=value secrets in free text.
+func RedactString(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Redacted)
+	s = jwtPattern.ReplaceAllString(s, Redacted)
+	return secretPairs.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+func redactJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if _, isString := child.(string); isString && sensitiveJSONKey.MatchString(k) {
+				t[k] = Redacted
+				continue
+			}
+			t[k] = redactJSON(child)
+		}
+		return t
+	case []any:
+		for i, child := range t {
+			t[i] = redactJSON(child)
+		}
+		return t
+	case string:
+		return RedactString(t)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	headerClientRequestID = "x-ms-client-request-id"
+	headerRequestID       = "x-ms-request-id"
+)
+
+var traceOnce sync.Once
+
+// EnableHTTPTrace registers a policy that logs every ARM request and
+// response to logger at info level, with secrets redacted. Calling it more
+// than once has no further effect.
+func EnableHTTPTrace(logger *slog.Logger) {
+	traceOnce.Do(func() {
+		AddPerRetryPolicy(NewTracePolicy(logger))
+	})
+}
+
+var (
+	recordOnce sync.Once
+	recorder   traceRecorder
+)
+
+// RecordHTTPTrace writes every ARM request and response to w as redacted
+// JSON lines, independent of the log level, until stop is called. The CLI
+// uses it to keep a per-run trace for `armv support-bundle`. One recording
+// is active at a time: a later call takes over from an earlier one. stop
+// waits for requests being traced to finish, so w can be closed after it.
+func RecordHTTPTrace(w io.Writer) (stop func()) {
+	recordOnce.Do(func() {
+		AddPerRetryPolicy(&recorder)
+	})
+	policy := NewTracePolicy(slog.New(slog.NewJSONHandler(w, nil)))
+	recorder.mu.Lock()
+	recorder.policy = policy
+	recorder.mu.Unlock()
+	return func() {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		if recorder.policy == policy {
+			recorder.policy = nil
+		}
+	}
+}
+
+// traceRecorder is the one policy behind RecordHTTPTrace. Registered
+// policies live for the process, so rather than adding one per recording it
+// traces through the current recording's policy, if any.
+type traceRecorder struct {
+	mu     sync.RWMutex
+	policy policy.Policy
+}
+
+func (r *traceRecorder) Do(req *policy.Request) (*http.Response, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.policy == nil {
+		return req.Next()
+	}
+	return r.policy.Do(req)
+}
+
+// NewTracePolicy returns the HTTP trace policy on its own, for callers that
+// build their own pipeline.
+func NewTracePolicy(logger *slog.Logger) policy.Policy {
+	return &tracePolicy{logger: logger}
+}
+
+type tracePolicy struct {
+	logger *slog.Logger
+}
+
+func (t *tracePolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	ctx := raw.Context()
+
+	var reqBody []byte
+	if body := req.Body(); body != nil {
+		reqBody, _ = io.ReadAll(body)
+		_ = req.RewindBody()
+	}
+
+	t.logger.LogAttrs(ctx, slog.LevelInfo, "arm request",
+		slog.String("method", raw.Method),
+		slog.String("url", RedactURL(raw.URL)),
+		slog.String("client_request_id", raw.Header.Get(headerClientRequestID)),
+		slog.String("correlation_id", raw.Header.Get(HeaderCorrelationRequestID)),
+		slog.Any("headers", RedactHeaders(raw.Header)),
+		slog.String("body", RedactBody(reqBody)),
+	)
+
+	start := time.Now()
+	resp, err := req.Next()
+	elapsed := time.Since(start)
+	if err != nil {
+		t.logger.LogAttrs(ctx, slog.LevelInfo, "arm request failed",
+			slog.String("method", raw.Method),
+			slog.String("url", RedactURL(raw.URL)),
+			slog.String("client_request_id", raw.Header.Get(headerClientRequestID)),
+			slog.Duration("duration", elapsed),
+			slog.String("error", RedactString(err.Error())),
+		)
+		return resp, err
+	}
+
+	var respBody []byte
+	if resp.Body != nil {
+		respBody, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+
+	correlationID := resp.Header.Get(HeaderCorrelationRequestID)
+	if correlationID == "" {
+		correlationID = raw.Header.Get(HeaderCorrelationRequestID)
+	}
+	t.logger.LogAttrs(ctx, slog.LevelInfo, "arm response",
+		slog.String("method", raw.Method),
+		slog.String("url", RedactURL(raw.URL)),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", elapsed),
+		slog.String("client_request_id", raw.Header.Get(headerClientRequestID)),
+		slog.String("request_id", resp.Header.Get(headerRequestID)),
+		slog.String("correlation_id", correlationID),
+		slog.Any("headers", RedactHeaders(resp.Header)),
+		slog.String("body", RedactBody(respBody)),
+	)
+	return resp, nil
+}
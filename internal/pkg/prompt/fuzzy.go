@@ -0,0 +1,73 @@
+package prompt
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rank returns the indices of the options matching query, best match first.
+// An option matches if the query's characters appear in it in order, ignoring
+// case and spaces ("prdweu" matches "rg-prod-westeurope"). Contiguous
+// substrings, matches at word starts and shorter options rank higher; equal
+// scores keep their original order. An empty query matches everything.
+func Rank(options []string, query string) []int {
+	query = strings.ToLower(strings.ReplaceAll(query, " ", ""))
+
+	type scored struct{ idx, score int }
+	var matches []scored
+	for i, o := range options {
+		if s, ok := Score(o, query); ok {
+			matches = append(matches, scored{i, s})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+
+	out := make([]int, len(matches))
+	for i, m := range matches {
+		out[i] = m.idx
+	}
+	return out
+}
+
+// Score reports whether candidate fuzzy-matches the lower-case query, and how
+// well; higher is better.
+func Score(candidate, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	lower := strings.ToLower(candidate)
+	if i := strings.Index(lower, query); i >= 0 {
+		score := 1000 - utf8.RuneCountInString(lower)
+		if i == 0 || isSeparator(lower[i-1]) {
+			score += 500
+		}
+		return score, true
+	}
+
+	score, run, qi, prev := 0, 0, 0, byte('-')
+	for ci := 0; ci < len(lower) && qi < len(query); ci++ {
+		c := lower[ci]
+		if c != query[qi] {
+			run = 0
+			prev = c
+			continue
+		}
+		run++
+		score += run * 2
+		if isSeparator(prev) {
+			score += 5
+		}
+		qi++
+		prev = c
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score - utf8.RuneCountInString(lower)/8, true
+}
+
+func isSeparator(c byte) bool {
+	return strings.IndexByte(" -_./()", c) >= 0
+}
@@ -0,0 +1,221 @@
+// Package prompt implements the line-based questions behind `armv
+// interactive`: picking one item from a long list by fuzzy search,
+// deselecting items by number, yes/no confirmation and free text. It reads
+// whole lines, so it works the same on a terminal, over SSH and with input
+// piped in by tests, and needs no raw terminal mode.
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PageSize is how many matches Choose shows at once.
+const PageSize = 10
+
+// ErrClosed is returned when input ends before a question is answered.
+var ErrClosed = errors.New("prompt: input closed")
+
+// Prompter asks questions on out and reads the answers from in.
+type Prompter struct {
+	out   io.Writer
+	lines chan line
+}
+
+type line struct {
+	text string
+	err  error
+}
+
+// New returns a Prompter reading from in and writing to out. Lines are read
+// in the background so a question can be abandoned when its context is
+// cancelled (e.g. by Ctrl-C) instead of blocking on the read.
+func New(in io.Reader, out io.Writer) *Prompter {
+	p := &Prompter{out: out, lines: make(chan line)}
+	go func() {
+		r := bufio.NewReader(in)
+		for {
+			text, err := r.ReadString('\n')
+			if err != nil && (text == "" || !errors.Is(err, io.EOF)) {
+				p.lines <- line{err: err}
+				close(p.lines)
+				return
+			}
+			p.lines <- line{text: strings.TrimRight(text, "\r\n")}
+		}
+	}()
+	return p
+}
+
+// ask prints question and returns the next line of input, trimmed.
+func (p *Prompter) ask(ctx context.Context, question string) (string, error) {
+	fmt.Fprint(p.out, question)
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(p.out)
+		return "", ctx.Err()
+	case l, ok := <-p.lines:
+		if !ok || errors.Is(l.err, io.EOF) {
+			fmt.Fprintln(p.out)
+			return "", ErrClosed
+		}
+		if l.err != nil {
+			return "", fmt.Errorf("prompt: read input: %w", l.err)
+		}
+		return strings.TrimSpace(l.text), nil
+	}
+}
+
+// Choose asks the user to pick one of options and returns its index. The
+// user types text to narrow the list by fuzzy match, then the number of an
+// entry; Enter accepts the only remaining match.
+func (p *Prompter) Choose(ctx context.Context, label string, options []string) (int, error) {
+	if len(options) == 0 {
+		return 0, fmt.Errorf("prompt: nothing to choose for %s", label)
+	}
+
+	query := ""
+	for {
+		matches := Rank(options, query)
+		if len(matches) == 0 {
+			fmt.Fprintf(p.out, "No %s matches %q.\n", label, query)
+			query = ""
+			continue
+		}
+		shown := matches[:min(len(matches), PageSize)]
+
+		fmt.Fprintf(p.out, "\n%s", label)
+		if query != "" {
+			fmt.Fprintf(p.out, " matching %q", query)
+		}
+		fmt.Fprintf(p.out, " (%d of %d):\n", len(matches), len(options))
+		for i, idx := range shown {
+			fmt.Fprintf(p.out, "  %2d) %s\n", i+1, options[idx])
+		}
+		if more := len(matches) - len(shown); more > 0 {
+			fmt.Fprintf(p.out, "  … %d more; type to narrow the list\n", more)
+		}
+
+		hint := "number or search text"
+		if len(matches) == 1 {
+			hint = "Enter to accept, or search text"
+		}
+		answer, err := p.ask(ctx, fmt.Sprintf("Choose %s [%s]: ", label, hint))
+		if err != nil {
+			return 0, err
+		}
+		if answer == "" {
+			if len(matches) == 1 {
+				return matches[0], nil
+			}
+			continue
+		}
+		// A number picks from the list shown; anything else, including a
+		// number out of range, is a new search.
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(shown) {
+			return shown[n-1], nil
+		}
+		query = answer
+	}
+}
+
+// Deselect lists options, numbered from 1, and asks which to leave out. It
+// returns the indices kept, in order. At least one option must be kept.
+func (p *Prompter) Deselect(ctx context.Context, label string, options []string) ([]int, error) {
+	fmt.Fprintf(p.out, "\n%s (%d):\n", label, len(options))
+	for i, o := range options {
+		fmt.Fprintf(p.out, "  %3d) %s\n", i+1, o)
+	}
+
+	for {
+		answer, err := p.ask(ctx, "Numbers to exclude (e.g. 2,5-7), or Enter to keep all: ")
+		if err != nil {
+			return nil, err
+		}
+		excluded, err := ParseSelection(answer, len(options))
+		if err != nil {
+			fmt.Fprintln(p.out, err)
+			continue
+		}
+		keep := make([]int, 0, len(options))
+		for i := range options {
+			if !slices.Contains(excluded, i) {
+				keep = append(keep, i)
+			}
+		}
+		if len(keep) == 0 {
+			fmt.Fprintln(p.out, "At least one must be kept.")
+			continue
+		}
+		return keep, nil
+	}
+}
+
+// Confirm asks a yes/no question; Enter gives def.
+func (p *Prompter) Confirm(ctx context.Context, question string, def bool) (bool, error) {
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+	for {
+		answer, err := p.ask(ctx, fmt.Sprintf("%s %s: ", question, choices))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n.")
+	}
+}
+
+// Text asks for a line of free text; Enter gives def.
+func (p *Prompter) Text(ctx context.Context, question, def string) (string, error) {
+	if def != "" {
+		question = fmt.Sprintf("%s [%s]", question, def)
+	}
+	answer, err := p.ask(ctx, question+": ")
+	if err != nil || answer == "" {
+		return def, err
+	}
+	return answer, nil
+}
+
+// ParseSelection parses a list of 1-based numbers and ranges such as
+// "2, 5-7" into sorted, de-duplicated 0-based indices below n. An empty
+// string selects nothing.
+func ParseSelection(s string, n int) ([]int, error) {
+	var out []int
+	for part := range strings.FieldsFuncSeq(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number or range", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("%q is not a number or range", part)
+			}
+		}
+		if first < 1 || last > n || first > last {
+			return nil, fmt.Errorf("%q is out of range 1-%d", part, n)
+		}
+		for i := first; i <= last; i++ {
+			out = append(out, i-1)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
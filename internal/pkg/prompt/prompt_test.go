@@ -0,0 +1,111 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	options := []string{"rg-prod-westeurope", "rg-dev", "prod", "rg-preprod-dw", "shared"}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "", want: []int{0, 1, 2, 3, 4}},
+		{query: "prod", want: []int{2, 0, 3}},
+		{query: "PRD WEU", want: []int{0}},
+		{query: "zzz", want: []int{}},
+	}
+	for _, tt := range tests {
+		if got := Rank(options, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("Rank(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseSelection("5-7, 2,6", 8)
+	if err != nil || !slices.Equal(got, []int{1, 4, 5, 6}) {
+		t.Errorf("ParseSelection() = (%v, %v)", got, err)
+	}
+	if got, err := ParseSelection("", 3); err != nil || len(got) != 0 {
+		t.Errorf("ParseSelection(\"\") = (%v, %v)", got, err)
+	}
+	for _, bad := range []string{"0", "4", "3-2", "x", "1-"} {
+		if _, err := ParseSelection(bad, 3); err == nil {
+			t.Errorf("ParseSelection(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestChoose(t *testing.T) {
+	t.Parallel()
+
+	options := []string{"alpha", "beta", "gamma", "delta"}
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "number from the full list", input: "2\n", want: 1},
+		{name: "search then number", input: "ta\n2\n", want: 3},
+		{name: "single match accepted with Enter", input: "gam\n\n", want: 2},
+		{name: "out-of-range number is a search", input: "9\nalp\n1\n", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := New(strings.NewReader(tt.input), io.Discard)
+			got, err := p.Choose(context.Background(), "item", options)
+			if err != nil || got != tt.want {
+				t.Errorf("Choose() = (%d, %v), want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeselectAndConfirm(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	p := New(strings.NewReader("1-3\nnine\n2\nmaybe\n\nmy-profile\n"), &out)
+	ctx := context.Background()
+
+	keep, err := p.Deselect(ctx, "Resources", []string{"a", "b", "c"})
+	if err != nil || !slices.Equal(keep, []int{0, 2}) {
+		t.Errorf("Deselect() = (%v, %v), want [0 2]", keep, err)
+	}
+	if !strings.Contains(out.String(), "At least one must be kept.") {
+		t.Errorf("excluding everything was not rejected:\n%s", out.String())
+	}
+	if ok, err := p.Confirm(ctx, "Run?", true); err != nil || !ok {
+		t.Errorf("Confirm() = (%v, %v), want default true", ok, err)
+	}
+	if name, err := p.Text(ctx, "Profile", ""); err != nil || name != "my-profile" {
+		t.Errorf("Text() = (%q, %v)", name, err)
+	}
+	if _, err := p.Text(ctx, "Again", ""); !errors.Is(err, ErrClosed) {
+		t.Errorf("Text() at EOF error = %v, want ErrClosed", err)
+	}
+}
+
+func TestAskHonoursCancellation(t *testing.T) {
+	t.Parallel()
+
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := New(r, io.Discard).Confirm(ctx, "Run?", true); !errors.Is(err, context.Canceled) {
+		t.Errorf("Confirm() error = %v, want context.Canceled", err)
+	}
+}
@@ -5,14 +5,16 @@ package resourcegroups
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 // GetResourceGroupClient creates a new ResourceGroupsClient for the given credential and subscription.
 func GetResourceGroupClient(cred azcore.TokenCredential, subscriptionID string) (*armresources.ResourceGroupsClient, error) {
-	resourcesClientFactory, err := armresources.NewClientFactory(subscriptionID, cred, nil)
+	resourcesClientFactory, err := armresources.NewClientFactory(subscriptionID, cred, pipeline.ClientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("resourcegroups: new client factory: %w", err)
 	}
@@ -25,6 +27,7 @@ func GetResourceGroupId(ctx context.Context, resourceGroupClient *armresources.R
 	if err != nil {
 		return nil, fmt.Errorf("resourcegroups: get %q: %w", resourceGroupName, err)
 	}
+	slog.DebugContext(ctx, "resourcegroups: resolved resource group", "resource_group", resourceGroupName, "id", resourceGroupResp.ResourceGroup.ID)
 	return resourceGroupResp.ResourceGroup.ID, nil
 }
 
@@ -34,6 +37,7 @@ func CheckResourceGroupExists(ctx context.Context, resourceGroupClient *armresou
 	if err != nil {
 		return false, fmt.Errorf("resourcegroups: check existence of %q: %w", resourceGroupName, err)
 	}
+	slog.DebugContext(ctx, "resourcegroups: checked existence", "resource_group", resourceGroupName, "exists", boolResp.Success)
 	return boolResp.Success, nil
 }
 
@@ -51,5 +55,6 @@ func ListResourceGroup(ctx context.Context, resourceGroupClient *armresources.Re
 		}
 		groups = append(groups, page.ResourceGroupListResult.Value...)
 	}
+	slog.DebugContext(ctx, "resourcegroups: listed resource groups", "count", len(groups))
 	return groups, nil
 }
@@ -5,14 +5,16 @@ package resources
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
 // GetResourcesClient returns an armresources.Client for the given subscription.
 func GetResourcesClient(cred azcore.TokenCredential, subscriptionID string) (*armresources.Client, error) {
-	resourcesClientFactory, err := armresources.NewClientFactory(subscriptionID, cred, nil)
+	resourcesClientFactory, err := armresources.NewClientFactory(subscriptionID, cred, pipeline.ClientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("resources: new client factory: %w", err)
 	}
@@ -32,6 +34,7 @@ func GetResources(ctx context.Context, resourcesClient *armresources.Client, res
 		resourceItems = append(resourceItems, pageResp.ResourceListResult.Value...)
 	}
 
+	slog.DebugContext(ctx, "resources: listed resources", "resource_group", resourceGroupName, "count", len(resourceItems))
 	return resourceItems, nil
 }
 
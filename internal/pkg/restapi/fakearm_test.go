@@ -0,0 +1,214 @@
+package restapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+const (
+	fakeSourceSub = "11111111-1111-1111-1111-111111111111"
+	fakeTargetSub = "22222222-2222-2222-2222-222222222222"
+
+	// fakeConflictGroup is a target group Azure refuses moves into.
+	fakeConflictGroup = "rg-conflict"
+)
+
+// fakeARM is a local stand-in for Azure Resource Manager, serving just what
+// validation and discovery call: subscription reads, resource group checks
+// and listings, resource listings and the validateMoveResources operation.
+// The real ARM clients reach it through pipeline.SetCloud and SetTransport.
+type fakeARM struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	hold     bool // keep operations running until released
+	requests []string
+}
+
+// resources of the fake source group, rg-src.
+var fakeResources = []map[string]string{
+	{"name": "vm1", "type": "Microsoft.Compute/virtualMachines", "location": "australiaeast"},
+	{"name": "st1", "type": "Microsoft.Storage/storageAccounts", "location": "australiaeast"},
+}
+
+// fakeGroups lists the resource groups of each fake subscription.
+var fakeGroups = map[string][]string{
+	fakeSourceSub: {"rg-src"},
+	fakeTargetSub: {"rg-dst", fakeConflictGroup},
+}
+
+// newFakeARM starts the fake and points every ARM client at it for the
+// rest of the test, so tests using it cannot run in parallel.
+func newFakeARM(t *testing.T) *fakeARM {
+	t.Helper()
+	f := &fakeARM{}
+	f.srv = httptest.NewTLSServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.srv.Close)
+
+	pipeline.SetCloud(cloud.Configuration{
+		ActiveDirectoryAuthorityHost: "https://login.invalid/",
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {Audience: "https://management.core.windows.net/", Endpoint: f.srv.URL},
+		},
+	})
+	pipeline.SetTransport(f.srv.Client())
+	t.Cleanup(func() {
+		pipeline.SetCloud(cloud.Configuration{})
+		pipeline.SetTransport(nil)
+	})
+	return f
+}
+
+// setHold makes validateMoveResources operations stay in progress (true)
+// or finish on their next poll (false).
+func (f *fakeARM) setHold(hold bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hold = hold
+}
+
+// called reports whether a request whose "METHOD path" contains want was made.
+func (f *fakeARM) called(want string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.requests {
+		if strings.Contains(r, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeARM) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	hold := f.hold
+	f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// ARM paths are case-insensitive.
+	seg := strings.Split(strings.Trim(strings.ToLower(r.URL.Path), "/"), "/")
+	switch {
+	case len(seg) == 1 && seg[0] == "subscriptions" && r.Method == http.MethodGet:
+		writeARM(w, http.StatusOK, map[string]any{"value": []any{fakeSubscription(fakeSourceSub, "Source"), fakeSubscription(fakeTargetSub, "Target")}})
+
+	case len(seg) == 2 && seg[0] == "subscriptions" && r.Method == http.MethodGet:
+		if _, ok := fakeGroups[seg[1]]; !ok {
+			writeARM(w, http.StatusNotFound, armError("SubscriptionNotFound", "no subscription "+seg[1]))
+			return
+		}
+		writeARM(w, http.StatusOK, fakeSubscription(seg[1], "Fake"))
+
+	case len(seg) == 3 && seg[2] == "resourcegroups" && r.Method == http.MethodGet:
+		var groups []any
+		for _, name := range fakeGroups[seg[1]] {
+			groups = append(groups, fakeGroup(seg[1], name))
+		}
+		writeARM(w, http.StatusOK, map[string]any{"value": groups})
+
+	case len(seg) == 4 && seg[2] == "resourcegroups":
+		if !hasGroup(seg[1], seg[3]) {
+			writeARM(w, http.StatusNotFound, armError("ResourceGroupNotFound", "no resource group "+seg[3]))
+			return
+		}
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeARM(w, http.StatusOK, fakeGroup(seg[1], seg[3]))
+
+	case len(seg) == 5 && seg[4] == "resources" && r.Method == http.MethodGet:
+		var items []any
+		if seg[3] == "rg-src" {
+			for _, res := range fakeResources {
+				items = append(items, map[string]any{"id": fakeResourceID(res), "name": res["name"], "type": res["type"], "location": res["location"]})
+			}
+		}
+		writeARM(w, http.StatusOK, map[string]any{"value": items})
+
+	case len(seg) == 5 && seg[4] == "validatemoveresources" && r.Method == http.MethodPost:
+		var body struct {
+			TargetResourceGroup string `json:"targetResourceGroup"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		outcome := "ok"
+		if strings.HasSuffix(strings.ToLower(body.TargetResourceGroup), "/"+fakeConflictGroup) {
+			outcome = "conflict"
+		}
+		w.Header().Set("Location", f.srv.URL+"/operations/"+outcome)
+		w.WriteHeader(http.StatusAccepted)
+
+	case len(seg) == 2 && seg[0] == "operations":
+		switch {
+		case hold:
+			w.Header().Set("Location", f.srv.URL+r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		case seg[1] == "conflict":
+			body := armError("ResourceMoveProviderValidationFailed", "Resource move validation failed.")
+			body["error"].(map[string]any)["details"] = []any{map[string]any{
+				"code":    "ResourceMoveNotSupported",
+				"target":  fakeResourceID(fakeResources[0]),
+				"message": "Virtual machines cannot move.",
+			}}
+			writeARM(w, http.StatusConflict, body)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+	default:
+		writeARM(w, http.StatusNotFound, armError("NotFound", "fake ARM does not serve "+r.Method+" "+r.URL.Path))
+	}
+}
+
+// hasGroup reports whether a group lookup in sub succeeds. The validator
+// looks up the target group with the source subscription's client, so a
+// lookup finds a group in any fake subscription.
+func hasGroup(sub, name string) bool {
+	if _, ok := fakeGroups[sub]; !ok {
+		return false
+	}
+	for _, groups := range fakeGroups {
+		for _, g := range groups {
+			if strings.EqualFold(g, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fakeSubscription(id, name string) map[string]any {
+	return map[string]any{"id": "/subscriptions/" + id, "subscriptionId": id, "displayName": name, "state": "Enabled"}
+}
+
+func fakeGroup(sub, name string) map[string]any {
+	return map[string]any{"id": fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", sub, name), "name": name, "location": "australiaeast"}
+}
+
+func fakeResourceID(res map[string]string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/rg-src/providers/%s/%s", fakeSourceSub, res["type"], res["name"])
+}
+
+func armError(code, message string) map[string]any {
+	return map[string]any{"error": map[string]any{"code": code, "message": message}}
+}
+
+func writeARM(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,201 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/jobs"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+const (
+	// DefaultMaxJobs is how many validation jobs run against Azure at once.
+	DefaultMaxJobs = jobs.DefaultMaxRunning
+	// DefaultJobRetention is how long a finished job and its report stay
+	// available.
+	DefaultJobRetention = jobs.DefaultRetention
+)
+
+// JobState is where a validation job is in its lifecycle.
+type JobState = jobs.State
+
+const (
+	JobQueued    = jobs.Queued
+	JobRunning   = jobs.Running
+	JobCompleted = jobs.Completed
+	JobFailed    = jobs.Failed
+	JobCancelled = jobs.Cancelled
+)
+
+// ValidationRequest is the body of POST /v1/validations.
+type ValidationRequest struct {
+	SourceSubscriptionID string   `json:"source_subscription_id"`
+	SourceResourceGroup  string   `json:"source_resource_group"`
+	TargetSubscriptionID string   `json:"target_subscription_id"`
+	TargetResourceGroup  string   `json:"target_resource_group"`
+	ResourceIDs          []string `json:"resource_ids,omitempty"`
+	PollIntervalSeconds  int      `json:"poll_interval_seconds,omitempty"`
+	PollTimeoutSeconds   int      `json:"poll_timeout_seconds,omitempty"`
+}
+
+// input checks r and converts it for the validator. Azure is not called,
+// so a malformed request is rejected before it is queued.
+func (r ValidationRequest) input() (validator.Input, error) {
+	if !utils.CheckValidSubscriptionID(r.SourceSubscriptionID) {
+		return validator.Input{}, fmt.Errorf("invalid source_subscription_id %q: must be a UUID", r.SourceSubscriptionID)
+	}
+	if !utils.CheckValidSubscriptionID(r.TargetSubscriptionID) {
+		return validator.Input{}, fmt.Errorf("invalid target_subscription_id %q: must be a UUID", r.TargetSubscriptionID)
+	}
+	if strings.TrimSpace(r.SourceResourceGroup) == "" {
+		return validator.Input{}, errors.New("source_resource_group is required")
+	}
+	if strings.TrimSpace(r.TargetResourceGroup) == "" {
+		return validator.Input{}, errors.New("target_resource_group is required")
+	}
+	if r.PollIntervalSeconds < 0 || r.PollTimeoutSeconds < 0 {
+		return validator.Input{}, errors.New("poll_interval_seconds and poll_timeout_seconds must not be negative")
+	}
+	in := validator.Input{
+		SourceSubscriptionID: r.SourceSubscriptionID,
+		SourceResourceGroup:  r.SourceResourceGroup,
+		TargetSubscriptionID: r.TargetSubscriptionID,
+		TargetResourceGroup:  r.TargetResourceGroup,
+		ResourceIDs:          r.ResourceIDs,
+		PollInterval:         time.Duration(r.PollIntervalSeconds) * time.Second,
+		PollTimeout:          time.Duration(r.PollTimeoutSeconds) * time.Second,
+	}
+	if err := (poller.PollOptions{Interval: in.PollInterval, Timeout: in.PollTimeout}).Validate(); err != nil {
+		return validator.Input{}, err
+	}
+	return in, nil
+}
+
+// Validation is a validation job, as returned by the /v1/validations
+// endpoints.
+type Validation struct {
+	ID             string            `json:"id"`
+	State          JobState          `json:"state"`
+	Message        string            `json:"message,omitempty"`
+	Error          *ErrorDetail      `json:"error,omitempty"`
+	Request        ValidationRequest `json:"request"`
+	CreatedAt      string            `json:"created_at"`
+	FinishedAt     string            `json:"finished_at,omitempty"`
+	ElapsedSeconds float64           `json:"elapsed_seconds"`
+	ExpiresAt      string            `json:"expires_at,omitempty"`
+	Result         *ValidationResult `json:"result,omitempty"`
+}
+
+// ValidationResult is Azure's verdict on a completed job. Success is false
+// when Azure reported resources that cannot move; the report endpoint
+// renders the same verdict in full.
+type ValidationResult struct {
+	Success             bool        `json:"success"`
+	HTTPStatusCode      int         `json:"http_status_code"`
+	Code                string      `json:"code,omitempty"`
+	Message             string      `json:"message,omitempty"`
+	ResourcesValidated  int         `json:"resources_validated"`
+	Errors              []MoveError `json:"errors,omitempty"`
+	PollCount           int         `json:"poll_count"`
+	PollDurationSeconds float64     `json:"poll_duration_seconds"`
+}
+
+// MoveError is one resource Azure says cannot move.
+type MoveError struct {
+	ResourceID   string `json:"resource_id,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceName string `json:"resource_name,omitempty"`
+	Code         string `json:"code,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Remediation  string `json:"remediation,omitempty"`
+}
+
+// job is one submitted validation.
+type job = jobs.Job[ValidationRequest, *validator.Result]
+
+// jobManager runs the validations in the background, on the queue shared
+// with the MCP server's job tools (see the jobs package).
+type jobManager = jobs.Manager[ValidationRequest, *validator.Result]
+
+// submit queues a validation of in, made for req, as a new job and returns
+// it. The job's context is independent of the submitting request, so it
+// outlives it.
+func (s *Server) submit(req ValidationRequest, in validator.Input) (*job, error) {
+	return s.jobs.Submit(req, func(ctx context.Context, notify validator.ProgressFn, _ func(string)) (*validator.Result, error) {
+		return validator.Validate(ctx, in, s.cred, notify)
+	})
+}
+
+// view returns the job as the API shows it.
+func (s *Server) view(j *job) Validation {
+	st := j.Snapshot()
+	v := Validation{
+		ID:             j.ID,
+		State:          st.State,
+		Message:        st.Message,
+		Request:        j.Request,
+		CreatedAt:      j.CreatedAt.UTC().Format(time.RFC3339),
+		ElapsedSeconds: s.jobs.Now().Sub(j.CreatedAt).Seconds(),
+	}
+	if st.Err != nil {
+		v.Error = &ErrorDetail{Code: validator.KindOf(st.Err).String(), Message: st.Err.Error()}
+	}
+	if st.State.Finished() {
+		v.FinishedAt = st.FinishedAt.UTC().Format(time.RFC3339)
+		v.ElapsedSeconds = st.FinishedAt.Sub(j.CreatedAt).Seconds()
+		v.ExpiresAt = s.jobs.ExpiresAt(st).UTC().Format(time.RFC3339)
+	}
+	if st.Result != nil {
+		v.Result = newValidationResult(st.Result, st.FinishedAt)
+	}
+	return v
+}
+
+// runRecord returns the completed job's run record, from which every report
+// format is rendered, or false while the job has no result.
+func (s *Server) runRecord(j *job) (poller.RunRecord, bool) {
+	st := j.Snapshot()
+	if st.Result == nil {
+		return poller.RunRecord{}, false
+	}
+	r := st.Result
+	rec := r.RunRecord(st.FinishedAt)
+	rec.Diagnostics = &poller.RunDiagnostics{
+		ToolVersion:           s.version,
+		CorrelationID:         pipeline.CorrelationID(),
+		StartedAt:             st.StartedAt.UTC(),
+		TargetResourceGroupID: r.TargetResourceGroupID,
+		ResourceIDs:           r.ResourceIDs,
+	}
+	return rec, true
+}
+
+func newValidationResult(r *validator.Result, finishedAt time.Time) *ValidationResult {
+	summary := poller.BuildValidationSummary(r.RunRecord(finishedAt))
+	out := &ValidationResult{
+		Success:             r.Success,
+		HTTPStatusCode:      r.HTTPStatusCode,
+		Code:                summary.Report.TopLevel.Code,
+		Message:             summary.Report.TopLevel.Message,
+		ResourcesValidated:  len(r.ResourceIDs),
+		PollCount:           r.PollCount,
+		PollDurationSeconds: r.PollDuration.Seconds(),
+	}
+	for _, e := range summary.Errors {
+		out.Errors = append(out.Errors, MoveError{
+			ResourceID:   e.ResourceID,
+			ResourceType: e.ResourceType,
+			ResourceName: e.ResourceName,
+			Code:         e.Code,
+			Message:      e.Message,
+			Remediation:  e.Remediation,
+		})
+	}
+	return out
+}
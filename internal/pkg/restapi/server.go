@@ -0,0 +1,523 @@
+// Package restapi serves ARMV as a versioned REST API, for services that
+// validate moves without shelling out to the CLI: submit a validation job,
+// poll its status, fetch its report in any format, and list what the
+// server's credential can see. Validations run through the validator
+// package and listings through the discovery package, exactly as for the
+// CLI and the MCP server.
+package restapi
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/discovery"
+	"github.com/AaronSaikovski/armv/internal/pkg/httpserve"
+	"github.com/AaronSaikovski/armv/internal/pkg/jobs"
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+	"github.com/AaronSaikovski/armv/pkg/utils"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+const (
+	// HealthPath answers liveness probes: the process is up.
+	HealthPath = "/healthz"
+	// ReadyPath answers readiness probes: the server takes new requests.
+	// It fails for the drain period before shutdown, so the load balancer
+	// stops sending traffic while the server still accepts it.
+	ReadyPath = "/readyz"
+	// OpenAPIPath serves the OpenAPI document describing the API.
+	OpenAPIPath = "/openapi.json"
+
+	// DefaultDrainPeriod is how long readiness fails before the server stops
+	// accepting requests.
+	DefaultDrainPeriod = 5 * time.Second
+
+	// MinTokenLength rejects bearer tokens short enough to guess.
+	MinTokenLength = 16
+
+	// maxBodyBytes bounds a request body; a validation request is small.
+	maxBodyBytes = 1 << 20
+)
+
+// ErrWeakToken is returned when the bearer token is shorter than
+// MinTokenLength.
+var ErrWeakToken = fmt.Errorf("restapi: bearer token must be at least %d characters", MinTokenLength)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Options configures a Server.
+type Options struct {
+	// Addr is the listen address for Serve, e.g. ":8080".
+	Addr string
+	// Token is the shared bearer token every /v1 request must carry. It is
+	// required: the API acts with the server's Azure credential, so an open
+	// endpoint would lend it to anyone.
+	Token string
+	// Credential is the Azure credential validations and listings use.
+	Credential azcore.TokenCredential
+	// MaxJobs bounds the validations running at once; zero means
+	// DefaultMaxJobs.
+	MaxJobs int
+	// JobRetention is how long a finished job stays available; zero means
+	// DefaultJobRetention.
+	JobRetention time.Duration
+	// DrainPeriod is how long Serve keeps accepting requests, with
+	// readiness failing, once asked to stop; zero means DefaultDrainPeriod.
+	DrainPeriod time.Duration
+	// Logger receives request and lifecycle logs; nil means slog.Default.
+	Logger *slog.Logger
+}
+
+// ErrorDetail is the error of a failed request or job. Code is a validator
+// error kind (invalid_input, auth_failed, ...) or, for problems with the
+// request itself, unauthorized, not_found, conflict or too_many_requests.
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server is the REST API: an http.Handler plus the jobs it runs.
+type Server struct {
+	version string
+	token   string
+	cred    azcore.TokenCredential
+	logger  *slog.Logger
+	jobs    *jobManager
+	mux     *http.ServeMux
+	ready   atomic.Bool
+}
+
+// route is one endpoint. Every route is documented in openapi.json; a test
+// keeps the two in step.
+type route struct {
+	pattern string // method and path, as for http.ServeMux
+	public  bool   // served without the bearer token
+	handler http.HandlerFunc
+}
+
+// NewServer returns the API for opts. It is ready to serve; Serve marks it
+// not ready once shutdown begins. It turns on ARM request metrics for the
+// process, as the server exposes them at metrics.Path. opts.Addr is ignored.
+func NewServer(version string, opts Options) (*Server, error) {
+	if len(opts.Token) < MinTokenLength {
+		return nil, ErrWeakToken
+	}
+	if opts.Credential == nil {
+		return nil, errors.New("restapi: an Azure credential is required")
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Server{
+		version: version,
+		token:   opts.Token,
+		cred:    opts.Credential,
+		logger:  logger,
+		jobs:    jobs.NewManager[ValidationRequest, *validator.Result]("val-", opts.MaxJobs, opts.JobRetention),
+		mux:     http.NewServeMux(),
+	}
+	s.ready.Store(true)
+	pipeline.EnableMetrics()
+	for _, r := range s.routes() {
+		h := http.Handler(r.handler)
+		if !r.public {
+			h = s.requireToken(h)
+		}
+		s.mux.Handle(r.pattern, h)
+	}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Errorf("no endpoint %s %s; see %s", r.Method, r.URL.Path, OpenAPIPath))
+	})
+	return s, nil
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{pattern: "GET " + HealthPath, public: true, handler: s.health},
+		{pattern: "GET " + ReadyPath, public: true, handler: s.readiness},
+		{pattern: "GET " + OpenAPIPath, public: true, handler: s.openAPI},
+		{pattern: "GET " + metrics.Path, public: true, handler: metrics.Handler().ServeHTTP},
+		{pattern: "POST /v1/validations", handler: s.createValidation},
+		{pattern: "GET /v1/validations", handler: s.listValidations},
+		{pattern: "GET /v1/validations/{id}", handler: s.getValidation},
+		{pattern: "DELETE /v1/validations/{id}", handler: s.cancelValidation},
+		{pattern: "GET /v1/validations/{id}/report", handler: s.getReport},
+		{pattern: "GET /v1/subscriptions", handler: s.listSubscriptions},
+		{pattern: "GET /v1/subscriptions/{subscriptionId}/resourceGroups", handler: s.listResourceGroups},
+		{pattern: "GET /v1/subscriptions/{subscriptionId}/resourceGroups/{resourceGroup}/resources", handler: s.listResources},
+	}
+}
+
+// ServeHTTP logs each request and hands it to its route.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	s.logger.DebugContext(r.Context(), "restapi: request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+}
+
+// Close cancels the jobs still queued or running and waits up to
+// httpserve.ShutdownTimeout for them to end.
+func (s *Server) Close() {
+	s.ready.Store(false)
+	if n := s.jobs.Stop(httpserve.ShutdownTimeout); n > 0 {
+		s.logger.Warn("restapi: cancelled unfinished validation jobs", "jobs", n)
+	}
+}
+
+// Serve serves the API on opts.Addr until ctx is cancelled, then shuts down
+// gracefully: readiness fails for opts.DrainPeriod while requests are still
+// accepted, in-flight requests then get up to httpserve.ShutdownTimeout to
+// finish, and the jobs still running are cancelled. TLS is expected to be
+// terminated in front of the server (ingress, sidecar or load balancer).
+func Serve(ctx context.Context, version string, opts Options) error {
+	s, err := NewServer(version, opts)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	drain := opts.DrainPeriod
+	if drain <= 0 {
+		drain = DefaultDrainPeriod
+	}
+	// The listener outlives ctx by the drain period, so probes see the 503
+	// and the load balancer stops routing here before connections are
+	// refused.
+	serveCtx, shutdown := context.WithCancel(context.WithoutCancel(ctx))
+	defer shutdown()
+	stop := context.AfterFunc(ctx, func() {
+		s.ready.Store(false)
+		s.logger.Info("restapi: not ready, draining", "drain_period", drain)
+		time.AfterFunc(drain, shutdown)
+	})
+	defer stop()
+	return httpserve.Serve(serveCtx, "restapi", opts.Addr, s, s.logger, "openapi", OpenAPIPath)
+}
+
+// requireToken rejects requests without the server's bearer token,
+// compared in constant time.
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="armv"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", errors.New("missing or unrecognised bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// --- probes and documentation ---------------------------------------------
+
+func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) readiness(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintln(w, "shutting down")
+		return
+	}
+	_, _ = fmt.Fprintln(w, "ready")
+}
+
+func (s *Server) openAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
+
+// --- validations ----------------------------------------------------------
+
+func (s *Server) createValidation(w http.ResponseWriter, r *http.Request) {
+	var req ValidationRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	in, err := req.input()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), err)
+		return
+	}
+	j, err := s.submit(req, in)
+	if errors.Is(err, jobs.ErrQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		writeError(w, http.StatusTooManyRequests, "too_many_requests", fmt.Errorf("%w; retry once some have finished", err))
+		return
+	}
+	if err != nil {
+		writeKindError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/validations/"+j.ID)
+	writeJSON(w, http.StatusAccepted, s.view(j))
+}
+
+func (s *Server) listValidations(w http.ResponseWriter, _ *http.Request) {
+	items := []Validation{}
+	for _, j := range s.jobs.List() {
+		items = append(items, s.view(j))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "count": len(items)})
+}
+
+func (s *Server) getValidation(w http.ResponseWriter, r *http.Request) {
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.view(j))
+}
+
+// cancelValidation cancels the job and waits briefly for it to stop, so the
+// state returned already says cancelled. Cancelling a finished job changes
+// nothing.
+func (s *Server) cancelValidation(w http.ResponseWriter, r *http.Request) {
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+	j.Cancel()
+	select {
+	case <-j.Done():
+	case <-time.After(5 * time.Second):
+	case <-r.Context().Done():
+	}
+	writeJSON(w, http.StatusOK, s.view(j))
+}
+
+// reportFormats maps the report endpoint's format parameter to the content
+// type it is served as. json is the run record `armv report render` reads;
+// the rest are the CLI's report formats.
+var reportFormats = map[string]string{
+	"json":                        "application/json",
+	string(poller.FormatMarkdown): "text/markdown; charset=utf-8",
+	string(poller.FormatHTML):     "text/html; charset=utf-8",
+	string(poller.FormatSARIF):    "application/sarif+json",
+	string(poller.FormatJUnit):    "application/xml",
+}
+
+func (s *Server) getReport(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "json"
+	}
+	if format == "markdown" {
+		format = string(poller.FormatMarkdown)
+	}
+	contentType, ok := reportFormats[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), fmt.Errorf("unsupported report format %q: must be one of json, md, html, sarif, junit", format))
+		return
+	}
+
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+	rec, ok := s.runRecord(j)
+	if !ok {
+		v := s.view(j)
+		msg := fmt.Errorf("validation %s is %s and has no report", v.ID, v.State)
+		if v.Error != nil {
+			msg = fmt.Errorf("%w: %s", msg, v.Error.Message)
+		}
+		writeError(w, http.StatusConflict, "conflict", msg)
+		return
+	}
+
+	var body []byte
+	if format == "json" {
+		var err error
+		if body, err = json.MarshalIndent(rec, "", "  "); err != nil {
+			writeKindError(w, fmt.Errorf("encode report: %w", err))
+			return
+		}
+	} else {
+		text, err := poller.Render(rec.Report(), poller.ReportFormat(format))
+		if err != nil {
+			writeKindError(w, fmt.Errorf("render %s report: %w", format, err))
+			return
+		}
+		body = []byte(text)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", j.ID+reportExtension(format)))
+	_, _ = w.Write(body)
+}
+
+func reportExtension(format string) string {
+	if format == "json" {
+		return ".json"
+	}
+	return poller.ReportFormat(format).Extension()
+}
+
+// job returns the job named by the request path, or writes a 404 and
+// returns nil.
+func (s *Server) job(w http.ResponseWriter, r *http.Request) *job {
+	id := r.PathValue("id")
+	j := s.jobs.Get(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Errorf("no validation %q: it never existed or expired %s after finishing", id, s.jobs.Retention()))
+	}
+	return j
+}
+
+// --- discovery ------------------------------------------------------------
+
+// listPage is the body of the discovery endpoints.
+type listPage[T any] struct {
+	Items      []T    `json:"items"`
+	Count      int    `json:"count"`
+	TotalCount int    `json:"total_count,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := discovery.Filter{Text: q.Get("name_contains")}
+	list(w, r, filter, "subscriptions", func(ctx context.Context) ([]discovery.Subscription, error) {
+		return discovery.ListSubscriptions(ctx, s.cred)
+	})
+}
+
+func (s *Server) listResourceGroups(w http.ResponseWriter, r *http.Request) {
+	sub := r.PathValue("subscriptionId")
+	if !utils.CheckValidSubscriptionID(sub) {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), fmt.Errorf("invalid subscription ID %q: must be a UUID", sub))
+		return
+	}
+	q := r.URL.Query()
+	filter := discovery.Filter{Text: q.Get("name_contains"), Location: q.Get("location"), Tag: q.Get("tag")}
+	list(w, r, filter, "resource_groups|"+strings.ToLower(sub), func(ctx context.Context) ([]discovery.ResourceGroup, error) {
+		return discovery.ListResourceGroups(ctx, s.cred, sub)
+	})
+}
+
+func (s *Server) listResources(w http.ResponseWriter, r *http.Request) {
+	sub, rg := r.PathValue("subscriptionId"), r.PathValue("resourceGroup")
+	if !utils.CheckValidSubscriptionID(sub) {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), fmt.Errorf("invalid subscription ID %q: must be a UUID", sub))
+		return
+	}
+	q := r.URL.Query()
+	filter := discovery.Filter{Text: q.Get("name_contains"), Type: q.Get("type"), Location: q.Get("location"), Tag: q.Get("tag")}
+	list(w, r, filter, "resources|"+strings.ToLower(sub)+"|"+strings.ToLower(rg), func(ctx context.Context) ([]discovery.Resource, error) {
+		return discovery.ListResources(ctx, s.cred, sub, rg)
+	})
+}
+
+// list serves one discovery listing: fetch it, apply filter and, when the
+// request has page_size or cursor, sort it by name and cut the page. scope
+// names the listing a cursor belongs to.
+func list[T discovery.Record](w http.ResponseWriter, r *http.Request, filter discovery.Filter, scope string, fetch func(context.Context) ([]T, error)) {
+	q := r.URL.Query()
+	size, cursor := 0, q.Get("cursor")
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > discovery.MaxPageSize {
+			writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), fmt.Errorf("page_size must be between 1 and %d, got %q", discovery.MaxPageSize, v))
+			return
+		}
+		size = n
+	}
+	// Check the filter before calling Azure.
+	if _, err := discovery.Apply([]T{}, filter); err != nil {
+		writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), err)
+		return
+	}
+
+	items, err := fetch(r.Context())
+	if err != nil {
+		writeKindError(w, validator.NewError(validator.KindInternal, err))
+		return
+	}
+	items, _ = discovery.Apply(items, filter)
+
+	page := listPage[T]{}
+	if size != 0 || cursor != "" {
+		if items, page.TotalCount, page.NextCursor, err = discovery.Page(items, filter, cursor, size, scope); err != nil {
+			writeError(w, http.StatusBadRequest, validator.KindInvalidInput.String(), err)
+			return
+		}
+	}
+	page.Items, page.Count = items, len(items)
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// --- responses ------------------------------------------------------------
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	writeJSON(w, status, map[string]ErrorDetail{"error": {Code: code, Message: err.Error()}})
+}
+
+// writeKindError writes err with the HTTP status for its validator kind.
+func writeKindError(w http.ResponseWriter, err error) {
+	kind := validator.KindOf(err)
+	writeError(w, httpStatus(kind), kind.String(), err)
+}
+
+// httpStatus maps a validator error kind to the status of a failed request.
+// Azure rejecting the server's credential is a 502: the caller's own token
+// was fine, the server's upstream was not.
+func httpStatus(kind validator.Kind) int {
+	switch kind {
+	case validator.KindInvalidInput:
+		return http.StatusBadRequest
+	case validator.KindResourceGroupNotFound:
+		return http.StatusNotFound
+	case validator.KindAuth:
+		return http.StatusBadGateway
+	case validator.KindPollTimeout:
+		return http.StatusGatewayTimeout
+	case validator.KindInterrupted:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// statusRecorder remembers the status code written, for the request log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
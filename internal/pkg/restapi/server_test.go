@@ -0,0 +1,490 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
+)
+
+const testToken = "0123456789abcdef-test-token"
+
+// newTestAPI serves a Server using a static Azure token, with the jobs
+// cancelled when the test ends.
+func newTestAPI(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s, err := NewServer("test", Options{Token: testToken, Credential: auth.NewStaticTokenCredential("fake-azure-token")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		ts.Close()
+		s.Close()
+	})
+	return s, ts
+}
+
+// call sends an authenticated request and returns the status and body.
+func call(t *testing.T, ts *httptest.Server, method, path, body string) (int, http.Header, []byte) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header, data
+}
+
+// decode unmarshals data into a T, failing the test on error.
+func decode[T any](t *testing.T, data []byte) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return v
+}
+
+// errorCode returns the code of an error response body.
+func errorCode(t *testing.T, data []byte) string {
+	t.Helper()
+	return decode[map[string]ErrorDetail](t, data)["error"].Code
+}
+
+// submit posts req and returns the queued job.
+func submit(t *testing.T, ts *httptest.Server, req ValidationRequest) Validation {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	status, header, data := call(t, ts, http.MethodPost, "/v1/validations", string(body))
+	if status != http.StatusAccepted {
+		t.Fatalf("POST /v1/validations = %d %s", status, data)
+	}
+	v := decode[Validation](t, data)
+	if loc := header.Get("Location"); loc != "/v1/validations/"+v.ID {
+		t.Errorf("Location = %q for job %s", loc, v.ID)
+	}
+	return v
+}
+
+// await polls the job until it finishes.
+func await(t *testing.T, ts *httptest.Server, id string) Validation {
+	t.Helper()
+	deadline := time.Now().Add(20 * time.Second)
+	for time.Now().Before(deadline) {
+		status, _, data := call(t, ts, http.MethodGet, "/v1/validations/"+id, "")
+		if status != http.StatusOK {
+			t.Fatalf("GET job = %d %s", status, data)
+		}
+		if v := decode[Validation](t, data); v.State.Finished() {
+			return v
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Validation{}
+}
+
+func moveRequest(target string) ValidationRequest {
+	return ValidationRequest{
+		SourceSubscriptionID: fakeSourceSub,
+		SourceResourceGroup:  "rg-src",
+		TargetSubscriptionID: fakeTargetSub,
+		TargetResourceGroup:  target,
+		PollIntervalSeconds:  1,
+	}
+}
+
+func TestNewServerRejectsBadOptions(t *testing.T) {
+	cred := auth.NewStaticTokenCredential("x")
+	if _, err := NewServer("test", Options{Token: "short", Credential: cred}); !errors.Is(err, ErrWeakToken) {
+		t.Errorf("weak token: err = %v, want ErrWeakToken", err)
+	}
+	if _, err := NewServer("test", Options{Token: testToken}); err == nil {
+		t.Error("a server without a credential was created")
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	_, ts := newTestAPI(t)
+
+	for _, path := range []string{HealthPath, ReadyPath, OpenAPIPath} {
+		resp, err := ts.Client().Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s without a token = %d, want 200", path, resp.StatusCode)
+		}
+	}
+
+	for name, header := range map[string]string{"missing": "", "wrong": "Bearer not-the-token-at-all", "not bearer": "Basic " + testToken} {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL+"/v1/validations", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || errorCode(t, data) != "unauthorized" || resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("%s token: %d %s", name, resp.StatusCode, data)
+		}
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	s, ts := newTestAPI(t)
+	status, _, data := call(t, ts, http.MethodGet, OpenAPIPath, "")
+	if status != http.StatusOK {
+		t.Fatalf("GET %s = %d", OpenAPIPath, status)
+	}
+	doc := decode[struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}](t, data)
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	served := map[string]bool{}
+	for _, r := range s.routes() {
+		method, path, _ := strings.Cut(r.pattern, " ")
+		served[strings.ToLower(method)+" "+path] = true
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("route %s is not documented", r.pattern)
+		}
+	}
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if method != "parameters" && !served[method+" "+path] {
+				t.Errorf("documented %s %s is not served", method, path)
+			}
+		}
+	}
+}
+
+func TestCreateValidationRejectsBadRequests(t *testing.T) {
+	_, ts := newTestAPI(t)
+	valid, _ := json.Marshal(moveRequest("rg-dst"))
+	tests := []struct {
+		name, body string
+	}{
+		{"not JSON", "{"},
+		{"unknown field", strings.Replace(string(valid), "{", `{"source_sub":"x",`, 1)},
+		{"bad subscription", strings.Replace(string(valid), fakeSourceSub, "not-a-uuid", 1)},
+		{"missing target group", strings.Replace(string(valid), `"rg-dst"`, `""`, 1)},
+		{"negative poll timeout", strings.Replace(string(valid), "{", `{"poll_timeout_seconds":-1,`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, data := call(t, ts, http.MethodPost, "/v1/validations", tt.body)
+			if status != http.StatusBadRequest || errorCode(t, data) != "invalid_input" {
+				t.Errorf("POST = %d %s, want 400 invalid_input", status, data)
+			}
+		})
+	}
+}
+
+func TestValidationAgainstFakeARM(t *testing.T) {
+	arm := newFakeARM(t)
+	_, ts := newTestAPI(t)
+
+	ok := submit(t, ts, moveRequest("rg-dst"))
+	conflict := submit(t, ts, moveRequest(fakeConflictGroup))
+	if ok.State != JobQueued && ok.State != JobRunning {
+		t.Errorf("new job state = %s", ok.State)
+	}
+
+	t.Run("valid move", func(t *testing.T) {
+		v := await(t, ts, ok.ID)
+		if v.State != JobCompleted || v.Result == nil || !v.Result.Success {
+			t.Fatalf("job = %+v (error %+v), want a successful completed validation", v, v.Error)
+		}
+		if v.Result.HTTPStatusCode != http.StatusNoContent || v.Result.ResourcesValidated != len(fakeResources) || v.Result.PollCount < 1 {
+			t.Errorf("result = %+v", v.Result)
+		}
+		if v.ExpiresAt == "" || v.FinishedAt == "" {
+			t.Errorf("finished job lacks finished_at or expires_at: %+v", v)
+		}
+	})
+
+	t.Run("blocked move", func(t *testing.T) {
+		v := await(t, ts, conflict.ID)
+		if v.State != JobCompleted || v.Result == nil || v.Result.Success {
+			t.Fatalf("job = %+v, want a completed validation that failed", v)
+		}
+		if len(v.Result.Errors) != 1 || v.Result.Errors[0].Code != "ResourceMoveNotSupported" || v.Result.Errors[0].ResourceName != "vm1" {
+			t.Errorf("errors = %+v", v.Result.Errors)
+		}
+	})
+
+	t.Run("reports", func(t *testing.T) {
+		for format, want := range map[string]string{
+			"":         "application/json",
+			"json":     "application/json",
+			"markdown": "text/markdown",
+			"html":     "text/html",
+			"sarif":    "application/sarif+json",
+			"junit":    "application/xml",
+		} {
+			status, header, data := call(t, ts, http.MethodGet, "/v1/validations/"+conflict.ID+"/report?format="+format, "")
+			if status != http.StatusOK || !strings.HasPrefix(header.Get("Content-Type"), want) {
+				t.Errorf("format %q: %d %s", format, status, header.Get("Content-Type"))
+				continue
+			}
+			if !strings.Contains(string(data), "vm1") {
+				t.Errorf("format %q report does not name the blocked resource", format)
+			}
+		}
+
+		_, _, data := call(t, ts, http.MethodGet, "/v1/validations/"+conflict.ID+"/report", "")
+		rec := decode[poller.RunRecord](t, data)
+		if rec.StatusCode != http.StatusConflict || rec.Diagnostics == nil || rec.Diagnostics.CorrelationID == "" {
+			t.Errorf("run record = %+v", rec)
+		}
+
+		if status, _, data := call(t, ts, http.MethodGet, "/v1/validations/"+ok.ID+"/report?format=pdf", ""); status != http.StatusBadRequest {
+			t.Errorf("pdf report = %d %s, want 400", status, data)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		_, _, data := call(t, ts, http.MethodGet, "/v1/validations", "")
+		list := decode[struct {
+			Items []Validation `json:"items"`
+			Count int          `json:"count"`
+		}](t, data)
+		if list.Count != 2 || list.Items[0].ID != conflict.ID {
+			t.Errorf("list = %+v, want both jobs, newest first", list)
+		}
+	})
+
+	if !arm.called("POST /subscriptions/" + fakeSourceSub + "/resourceGroups/rg-src/validateMoveResources") {
+		t.Error("validateMoveResources was never called")
+	}
+}
+
+// TestMetricsAgainstFakeARM checks that validations run through the API are
+// counted, timed and seen at the ARM pipeline, and that /metrics serves
+// them without a token. Tests in this package run one at a time, so the
+// deltas are exact.
+func TestMetricsAgainstFakeARM(t *testing.T) {
+	newFakeARM(t)
+	_, ts := newTestAPI(t)
+
+	valid, invalid := metrics.Validations.With("valid"), metrics.Validations.With("invalid")
+	polling := metrics.ValidationPhaseDuration.With(metrics.PhasePolling)
+	submitted := metrics.ARMRequestDuration.With(http.MethodPost, "202")
+	polls := metrics.Polls.With()
+	queued, running := metrics.JobsInFlight.With(string(JobQueued)), metrics.JobsInFlight.With(string(JobRunning))
+	validBefore, invalidBefore, pollingBefore, submittedBefore, pollsBefore := valid.Value(), invalid.Value(), polling.Count(), submitted.Count(), polls.Value()
+	queuedBefore, runningBefore := queued.Value(), running.Value()
+
+	await(t, ts, submit(t, ts, moveRequest("rg-dst")).ID)
+	await(t, ts, submit(t, ts, moveRequest(fakeConflictGroup)).ID)
+
+	if got := valid.Value() - validBefore; got != 1 {
+		t.Errorf("valid outcomes = %v, want 1", got)
+	}
+	if got := invalid.Value() - invalidBefore; got != 1 {
+		t.Errorf("invalid outcomes = %v, want 1", got)
+	}
+	if got := polling.Count() - pollingBefore; got != 2 {
+		t.Errorf("polling phases timed = %d, want 2", got)
+	}
+	if got := submitted.Count() - submittedBefore; got != 2 {
+		t.Errorf("POST 202 ARM requests = %d, want 2", got)
+	}
+	if got := polls.Value() - pollsBefore; got < 2 {
+		t.Errorf("polls = %v, want at least 2", got)
+	}
+	if queued.Value() != queuedBefore || running.Value() != runningBefore {
+		t.Errorf("jobs in flight = %v queued, %v running after both finished; want %v and %v", queued.Value(), running.Value(), queuedBefore, runningBefore)
+	}
+
+	resp, err := ts.Client().Get(ts.URL + metrics.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("GET %s = %d %q", metrics.Path, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`armv_validations_total{outcome="valid"}`,
+		`armv_validation_phase_duration_seconds_count{phase="submitting"}`,
+		`armv_arm_request_duration_seconds_count{method="POST",code="202"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics lack %s", want)
+		}
+	}
+}
+
+func TestValidationFailsOnMissingGroup(t *testing.T) {
+	newFakeARM(t)
+	_, ts := newTestAPI(t)
+
+	v := await(t, ts, submit(t, ts, moveRequest("rg-missing")).ID)
+	if v.State != JobFailed || v.Error == nil || v.Error.Code != "resource_group_not_found" {
+		t.Fatalf("job = %+v, want failed with resource_group_not_found", v)
+	}
+	if status, _, data := call(t, ts, http.MethodGet, "/v1/validations/"+v.ID+"/report", ""); status != http.StatusConflict || !strings.Contains(string(data), "rg-missing") {
+		t.Errorf("report of failed job = %d %s, want 409 naming the cause", status, data)
+	}
+}
+
+func TestCancelValidation(t *testing.T) {
+	arm := newFakeARM(t)
+	arm.setHold(true)
+	_, ts := newTestAPI(t)
+
+	v := submit(t, ts, moveRequest("rg-dst"))
+	deadline := time.Now().Add(10 * time.Second)
+	for !arm.called("/operations/") && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	status, _, data := call(t, ts, http.MethodDelete, "/v1/validations/"+v.ID, "")
+	if status != http.StatusOK {
+		t.Fatalf("DELETE = %d %s", status, data)
+	}
+	if got := decode[Validation](t, data); got.State != JobCancelled || got.Error == nil || got.Error.Code != "interrupted" {
+		t.Errorf("cancelled job = %+v", got)
+	}
+
+	if status, _, data := call(t, ts, http.MethodGet, "/v1/validations/val-unknown", ""); status != http.StatusNotFound || errorCode(t, data) != "not_found" {
+		t.Errorf("unknown job = %d %s, want 404 not_found", status, data)
+	}
+}
+
+func TestDiscoveryAgainstFakeARM(t *testing.T) {
+	newFakeARM(t)
+	_, ts := newTestAPI(t)
+
+	type page[T any] struct {
+		Items      []T    `json:"items"`
+		Count      int    `json:"count"`
+		TotalCount int    `json:"total_count"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	status, _, data := call(t, ts, http.MethodGet, "/v1/subscriptions?name_contains=targ", "")
+	subs := decode[page[map[string]string]](t, data)
+	if status != http.StatusOK || subs.Count != 1 || subs.Items[0]["subscription_id"] != fakeTargetSub {
+		t.Errorf("subscriptions = %d %s", status, data)
+	}
+
+	groupsPath := "/v1/subscriptions/" + fakeTargetSub + "/resourceGroups?page_size=1"
+	_, _, data = call(t, ts, http.MethodGet, groupsPath, "")
+	first := decode[page[map[string]string]](t, data)
+	if first.Count != 1 || first.TotalCount != 2 || first.NextCursor == "" || first.Items[0]["name"] != fakeConflictGroup {
+		t.Fatalf("first page = %s", data)
+	}
+	_, _, data = call(t, ts, http.MethodGet, groupsPath+"&cursor="+first.NextCursor, "")
+	second := decode[page[map[string]string]](t, data)
+	if second.Count != 1 || second.NextCursor != "" || second.Items[0]["name"] != "rg-dst" {
+		t.Errorf("second page = %s", data)
+	}
+
+	status, _, data = call(t, ts, http.MethodGet, "/v1/subscriptions/"+fakeSourceSub+"/resourceGroups/rg-src/resources?type=Microsoft.Storage/", "")
+	res := decode[page[map[string]string]](t, data)
+	if status != http.StatusOK || res.Count != 1 || res.Items[0]["name"] != "st1" {
+		t.Errorf("resources = %d %s", status, data)
+	}
+
+	for _, path := range []string{
+		"/v1/subscriptions/not-a-uuid/resourceGroups",
+		"/v1/subscriptions/" + fakeTargetSub + "/resourceGroups?page_size=0",
+		"/v1/subscriptions/" + fakeTargetSub + "/resourceGroups?tag==x",
+		"/v1/subscriptions/" + fakeTargetSub + "/resourceGroups?cursor=bogus",
+	} {
+		if status, _, data := call(t, ts, http.MethodGet, path, ""); status != http.StatusBadRequest || errorCode(t, data) != "invalid_input" {
+			t.Errorf("GET %s = %d %s, want 400 invalid_input", path, status, data)
+		}
+	}
+}
+
+func TestServeShutsDownGracefully(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Serve(ctx, "test", Options{Addr: addr, Token: testToken, Credential: auth.NewStaticTokenCredential("x"), DrainPeriod: 200 * time.Millisecond})
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + ReadyPath)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never became ready: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// During the drain the server still answers, but is not ready.
+	cancel()
+	resp, err := http.Get("http://" + addr + ReadyPath)
+	if err != nil {
+		t.Fatalf("GET %s while draining: %v", ReadyPath, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET %s while draining = %d, want 503", ReadyPath, resp.StatusCode)
+	}
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("Serve = %v, want nil after cancellation", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+}
+
+func TestReadinessFailsAfterClose(t *testing.T) {
+	s, ts := newTestAPI(t)
+	s.Close()
+	resp, err := ts.Client().Get(ts.URL + ReadyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET %s after Close = %d, want 503", ReadyPath, resp.StatusCode)
+	}
+}
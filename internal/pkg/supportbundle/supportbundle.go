@@ -0,0 +1,317 @@
+// Package supportbundle packages a completed validation run into a zip for
+// escalation to Azure support: the report, the validate-move request, the
+// resource inventory, the redacted HTTP trace and tool/environment details.
+// Everything is passed through the pipeline redaction helpers before it is
+// written, so a bundle can be attached to a support ticket as is.
+package supportbundle
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/pipeline"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// Files inside the bundle.
+const (
+	FileManifest    = "manifest.json"
+	FileEnvironment = "environment.json"
+	FileRunRecord   = "run.json"
+	FileReport      = "report.md"
+	FileInventory   = "inventory.json"
+	FileMoveInfo    = "moveinfo.json"
+	FileHTTPTrace   = "http-trace.jsonl"
+)
+
+// runRecordPattern matches the run records written next to each report.
+const runRecordPattern = "run-*.json"
+
+// Options selects the run to bundle.
+type Options struct {
+	// RunRecord is the path of the run-*.json record to bundle.
+	RunRecord string
+
+	// ToolVersion is the version of the armv binary creating the bundle.
+	ToolVersion string
+
+	// Now stamps the bundle; zero means time.Now.
+	Now time.Time
+}
+
+// Manifest summarises the bundle; it is the first file a support engineer
+// should read.
+type Manifest struct {
+	CreatedAt     time.Time `json:"created_at"`
+	ToolVersion   string    `json:"tool_version"`
+	RunRecord     string    `json:"run_record"`
+	RunStartedAt  time.Time `json:"run_started_at,omitzero"`
+	RunFinishedAt time.Time `json:"run_finished_at"`
+	RunVersion    string    `json:"run_tool_version,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	StatusCode    int       `json:"status_code"`
+	Status        string    `json:"status"`
+	Files         []string  `json:"files"`
+
+	// Missing lists bundle files that could not be produced and why, e.g. a
+	// record written by an older armv without diagnostics.
+	Missing map[string]string `json:"missing,omitempty"`
+}
+
+// Environment describes where the run was made. Only variables that affect
+// authentication or networking are included, with secret values redacted.
+type Environment struct {
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	NumCPU    int               `json:"num_cpu"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// Inventory is the set of resources that was submitted for validation.
+type Inventory struct {
+	Context               poller.ReportContext `json:"context"`
+	TargetResourceGroupID string               `json:"target_resource_group_id,omitempty"`
+	ResourceIDs           []string             `json:"resource_ids"`
+}
+
+// LatestRunRecord returns the newest run-*.json in dir. Record names embed
+// a sortable timestamp, so the lexically last one is the newest.
+func LatestRunRecord(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, runRecordPattern))
+	if err != nil {
+		return "", fmt.Errorf("supportbundle: find run records: %w", err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("supportbundle: no %s files in %s; run a validation first", runRecordPattern, dir)
+	}
+	slices.Sort(matches)
+	return matches[len(matches)-1], nil
+}
+
+// Write builds the bundle for opts.RunRecord and writes the zip to w.
+func Write(w io.Writer, opts Options) (Manifest, error) {
+	rec, err := poller.LoadRunRecord(opts.RunRecord)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("supportbundle: %w", err)
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	m := Manifest{
+		CreatedAt:     now.UTC(),
+		ToolVersion:   opts.ToolVersion,
+		RunRecord:     filepath.Base(opts.RunRecord),
+		RunFinishedAt: rec.GeneratedAt,
+		StatusCode:    rec.StatusCode,
+		Status:        rec.Status,
+		Missing:       map[string]string{},
+	}
+
+	files := []bundleFile{}
+	add := func(name string, data []byte) {
+		files = append(files, bundleFile{name: name, data: data})
+		m.Files = append(m.Files, name)
+	}
+
+	env, err := json.MarshalIndent(environment(os.Environ()), "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("supportbundle: encode environment: %w", err)
+	}
+	add(FileEnvironment, env)
+
+	redactedRec := rec
+	redactedRec.Body = pipeline.RedactString(rec.Body)
+	recJSON, err := json.MarshalIndent(redactedRec, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("supportbundle: encode run record: %w", err)
+	}
+	add(FileRunRecord, recJSON)
+	add(FileReport, []byte(pipeline.RedactString(poller.RenderMarkdown(redactedRec.Report()))))
+
+	if diag := rec.Diagnostics; diag == nil {
+		const reason = "run record has no diagnostics (written by an older armv)"
+		m.Missing[FileInventory] = reason
+		m.Missing[FileMoveInfo] = reason
+		m.Missing[FileHTTPTrace] = reason
+	} else {
+		m.RunStartedAt = diag.StartedAt
+		m.RunVersion = diag.ToolVersion
+		m.CorrelationID = diag.CorrelationID
+
+		inv, err := json.MarshalIndent(Inventory{
+			Context:               rec.Context,
+			TargetResourceGroupID: diag.TargetResourceGroupID,
+			ResourceIDs:           diag.ResourceIDs,
+		}, "", "  ")
+		if err != nil {
+			return Manifest{}, fmt.Errorf("supportbundle: encode inventory: %w", err)
+		}
+		add(FileInventory, inv)
+
+		moveInfo, err := json.MarshalIndent(armresources.MoveInfo{
+			Resources:           to.SliceOfPtrs(diag.ResourceIDs...),
+			TargetResourceGroup: to.Ptr(diag.TargetResourceGroupID),
+		}, "", "  ")
+		if err != nil {
+			return Manifest{}, fmt.Errorf("supportbundle: encode move info: %w", err)
+		}
+		add(FileMoveInfo, moveInfo)
+
+		trace, err := readTrace(filepath.Dir(opts.RunRecord), diag.TraceFile)
+		if err != nil {
+			m.Missing[FileHTTPTrace] = err.Error()
+		} else {
+			add(FileHTTPTrace, trace)
+		}
+	}
+
+	if len(m.Missing) == 0 {
+		m.Missing = nil
+	}
+	m.Files = append([]string{FileManifest}, m.Files...)
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("supportbundle: encode manifest: %w", err)
+	}
+	files = append([]bundleFile{{name: FileManifest, data: manifest}}, files...)
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return Manifest{}, fmt.Errorf("supportbundle: add %s: %w", f.name, err)
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return Manifest{}, fmt.Errorf("supportbundle: write %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("supportbundle: finish zip: %w", err)
+	}
+	return m, nil
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// readTrace reads the run's trace file from dir, redacting each line again
+// in case the file predates a redaction rule.
+func readTrace(dir, name string) ([]byte, error) {
+	if name == "" {
+		return nil, errors.New("run did not record an HTTP trace")
+	}
+	if name != filepath.Base(name) {
+		return nil, fmt.Errorf("trace file %q is not a plain file name", name)
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("open HTTP trace: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out bytes.Buffer
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for sc.Scan() {
+		out.WriteString(pipeline.RedactString(sc.Text()))
+		out.WriteByte('\n')
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read HTTP trace: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// envPrefixes selects the variables that influence authentication, cloud
+// selection or networking.
+var envPrefixes = []string{"AZURE_", "ARMV_", "MSI_", "IDENTITY_"}
+
+// proxyVars may carry credentials in their user-info part.
+var proxyVars = map[string]bool{"HTTP_PROXY": true, "HTTPS_PROXY": true, "NO_PROXY": true}
+
+// secretEnvName matches variables whose values are never recorded.
+var secretEnvName = []string{"SECRET", "PASSWORD", "TOKEN", "KEY", "CERTIFICATE"}
+
+// environment returns the runtime details and the relevant variables from
+// environ (KEY=VALUE pairs), redacted.
+func environment(environ []string) Environment {
+	env := Environment{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		Variables: map[string]string{},
+	}
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		upper := strings.ToUpper(name)
+		switch {
+		case proxyVars[upper]:
+			env.Variables[name] = redactProxy(value)
+		case hasAnyPrefix(upper, envPrefixes):
+			if containsAny(upper, secretEnvName) {
+				env.Variables[name] = pipeline.Redacted
+			} else {
+				env.Variables[name] = pipeline.RedactString(value)
+			}
+		}
+	}
+	if len(env.Variables) == 0 {
+		env.Variables = nil
+	}
+	return env
+}
+
+// redactProxy strips any password from a proxy URL.
+func redactProxy(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), pipeline.Redacted)
+	}
+	return u.String()
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,10 @@ package validation
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
@@ -23,9 +25,32 @@ func (azureResourceMoveInfo *AzureResourceMoveInfo) ValidateMove(ctx context.Con
 		return nil, err
 	}
 
+	slog.DebugContext(ctx, "validation: submitting validate-move",
+		"source_resource_group", azureResourceMoveInfo.SourceResourceGroup,
+		"resource_count", len(azureResourceMoveInfo.ResourceIds))
 	poller, err := client.BeginValidateMoveResources(ctx, azureResourceMoveInfo.SourceResourceGroup, moveInfo, nil)
 	if err != nil {
 		return nil, fmt.Errorf("validation: begin validate move: %w", err)
 	}
 	return poller, nil
 }
+
+// ResumeValidateMove reattaches to a validate-move operation started earlier
+// (possibly by another process) from the token returned by the poller's
+// ResumeToken method. The returned poller must be driven to completion by the
+// caller, exactly as for ValidateMove.
+func ResumeValidateMove(ctx context.Context, cred azcore.TokenCredential, subscriptionID, resourceGroup, resumeToken string) (*runtime.Poller[armresources.ClientValidateMoveResourcesResponse], error) {
+	client, err := auth.NewResourceClient(subscriptionID, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "validation: resuming validate-move", "source_resource_group", resourceGroup)
+	poller, err := client.BeginValidateMoveResources(ctx, resourceGroup, armresources.MoveInfo{}, &armresources.ClientBeginValidateMoveResourcesOptions{
+		ResumeToken: resumeToken,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("validation: resume validate move: %w", err)
+	}
+	return poller, nil
+}
@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// Kind classifies why a validation did not succeed, so each front end can map
+// the outcome consistently: the CLI to a process exit code, the MCP server to
+// an error code in the tool result.
+type Kind int
+
+const (
+	// KindInternal is any failure not covered by a more specific kind.
+	KindInternal Kind = iota
+	// KindInvalidInput covers malformed IDs, missing parameters and empty source groups.
+	KindInvalidInput
+	// KindAuth covers credential failures and 401/403 responses from Azure.
+	KindAuth
+	// KindResourceGroupNotFound means the source or target group does not exist.
+	KindResourceGroupNotFound
+	// KindPollTimeout means the long-running operation outlived the polling ceiling.
+	KindPollTimeout
+	// KindValidationFailed means Azure completed the check and reported conflicts.
+	KindValidationFailed
+	// KindInterrupted means the caller cancelled the run (e.g. SIGINT) before it finished.
+	KindInterrupted
+)
+
+// String returns the stable snake_case code for the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindInvalidInput:
+		return "invalid_input"
+	case KindAuth:
+		return "auth_failed"
+	case KindResourceGroupNotFound:
+		return "resource_group_not_found"
+	case KindPollTimeout:
+		return "poll_timeout"
+	case KindValidationFailed:
+		return "validation_failed"
+	case KindInterrupted:
+		return "interrupted"
+	default:
+		return "internal_error"
+	}
+}
+
+// Error is a classified validator error. Match a category with errors.Is
+// against one of the Err* sentinels, or extract the Kind with errors.As.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Sentinels for errors.Is. They carry no underlying error and only match on Kind.
+var (
+	ErrInvalidInput          = &Error{Kind: KindInvalidInput}
+	ErrAuth                  = &Error{Kind: KindAuth}
+	ErrResourceGroupNotFound = &Error{Kind: KindResourceGroupNotFound}
+	ErrPollTimeout           = &Error{Kind: KindPollTimeout}
+	ErrValidationFailed      = &Error{Kind: KindValidationFailed}
+	ErrInterrupted           = &Error{Kind: KindInterrupted}
+)
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.String()
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel for e's Kind.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Kind == e.Kind
+}
+
+// NewError classifies err as kind. If err is already classified it is
+// returned unchanged, and if the Azure response shows the call was rejected
+// for authentication or authorisation reasons it is classified as KindAuth
+// regardless of kind, since that is the actionable cause.
+func NewError(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	if isAuthFailure(err) {
+		kind = KindAuth
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the Kind of err, or KindInternal if it was never classified.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// PollError classifies an error returned while polling the long-running
+// operation: a context deadline becomes KindPollTimeout, a cancelled context
+// KindInterrupted, and anything else is classified as for NewError with
+// KindInternal.
+func PollError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewError(KindPollTimeout, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return NewError(KindInterrupted, err)
+	}
+	return NewError(KindInternal, err)
+}
+
+func isAuthFailure(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden
+	}
+	var authErr *azidentity.AuthenticationFailedError
+	return errors.As(err, &authErr)
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestNewErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	plain := errors.New("boom")
+	forbidden := fmt.Errorf("resourcegroups: get: %w", &azcore.ResponseError{StatusCode: http.StatusForbidden, ErrorCode: "AuthorizationFailed"})
+	notFound := &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: "ResourceGroupNotFound"}
+
+	tests := []struct {
+		name     string
+		kind     Kind
+		err      error
+		wantKind Kind
+		sentinel error
+	}{
+		{name: "plain error keeps requested kind", kind: KindResourceGroupNotFound, err: plain, wantKind: KindResourceGroupNotFound, sentinel: ErrResourceGroupNotFound},
+		{name: "403 response is promoted to auth", kind: KindInternal, err: forbidden, wantKind: KindAuth, sentinel: ErrAuth},
+		{name: "non-auth response keeps requested kind", kind: KindInternal, err: notFound, wantKind: KindInternal},
+		{name: "already classified error is not reclassified", kind: KindInternal, err: NewError(KindInvalidInput, plain), wantKind: KindInvalidInput, sentinel: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := NewError(tt.kind, tt.err)
+			if got := KindOf(err); got != tt.wantKind {
+				t.Errorf("KindOf = %v, want %v", got, tt.wantKind)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.sentinel)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("classified error no longer wraps the original")
+			}
+			var ve *Error
+			if !errors.As(err, &ve) {
+				t.Fatal("errors.As(*Error) = false")
+			}
+		})
+	}
+}
+
+func TestErrorSentinelsDoNotCrossMatch(t *testing.T) {
+	t.Parallel()
+
+	err := NewError(KindAuth, errors.New("denied"))
+	for _, other := range []error{ErrInvalidInput, ErrResourceGroupNotFound, ErrPollTimeout, ErrValidationFailed} {
+		if errors.Is(err, other) {
+			t.Errorf("auth error unexpectedly matched %v", other)
+		}
+	}
+	if err.Error() != "denied" {
+		t.Errorf("Error() = %q, want the wrapped message", err.Error())
+	}
+}
+
+func TestPollError(t *testing.T) {
+	t.Parallel()
+
+	if got := KindOf(PollError(fmt.Errorf("poll: %w", context.DeadlineExceeded))); got != KindPollTimeout {
+		t.Errorf("deadline exceeded classified as %v, want %v", got, KindPollTimeout)
+	}
+	if got := KindOf(PollError(fmt.Errorf("poll: %w", context.Canceled))); got != KindInterrupted {
+		t.Errorf("cancellation classified as %v, want %v", got, KindInterrupted)
+	}
+	if got := KindOf(PollError(errors.New("HTTP 500"))); got != KindInternal {
+		t.Errorf("plain poll error classified as %v, want %v", got, KindInternal)
+	}
+}
+
+func TestKindOfUnclassified(t *testing.T) {
+	t.Parallel()
+
+	if got := KindOf(errors.New("x")); got != KindInternal {
+		t.Errorf("KindOf(unclassified) = %v, want %v", got, KindInternal)
+	}
+	if got := KindInternal.String(); got != "internal_error" {
+		t.Errorf("KindInternal.String() = %q", got)
+	}
+}
+
+// TestValidateInputErrorsAreTyped pins that Validate's early guards return
+// KindInvalidInput so the CLI exits with the input-error code and the MCP
+// server tags the result consistently.
+func TestValidateInputErrorsAreTyped(t *testing.T) {
+	t.Parallel()
+
+	_, err := Validate(context.Background(), Input{SourceSubscriptionID: "bad"}, nil, nil)
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Validate with bad UUID: got %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AaronSaikovski/armv/pkg/utils"
+)
+
+const (
+	// ResumeFilePrefix names the resume-state files written next to reports.
+	ResumeFilePrefix = "resume-"
+
+	resumeFileExt   = ".json"
+	resumeTimestamp = "2006-01-02-15-04-05"
+)
+
+// unsafeFileChars matches anything that should not appear in a file name
+// derived from a resource group name.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ResumeState is everything needed to reattach to an in-flight validate-move
+// operation: the poller's resume token plus the request context the report
+// is rendered from. It is persisted as JSON as soon as Azure accepts the
+// request so an interrupted run can be picked up by another process.
+type ResumeState struct {
+	ResumeToken           string    `json:"resume_token"`
+	SourceSubscriptionID  string    `json:"source_subscription_id"`
+	SourceResourceGroup   string    `json:"source_resource_group"`
+	TargetSubscriptionID  string    `json:"target_subscription_id"`
+	TargetResourceGroup   string    `json:"target_resource_group"`
+	TargetResourceGroupID string    `json:"target_resource_group_id,omitempty"`
+	ResourceIDs           []string  `json:"resource_ids"`
+	StartedAt             time.Time `json:"started_at"`
+}
+
+// Validate checks the state carries a token and well-formed subscription IDs.
+func (st ResumeState) Validate() error {
+	if st.ResumeToken == "" {
+		return errors.New("resume state has no resume token")
+	}
+	if !utils.CheckValidSubscriptionID(st.SourceSubscriptionID) {
+		return fmt.Errorf("resume state has invalid source subscription ID %q", st.SourceSubscriptionID)
+	}
+	if !utils.CheckValidSubscriptionID(st.TargetSubscriptionID) {
+		return fmt.Errorf("resume state has invalid target subscription ID %q", st.TargetSubscriptionID)
+	}
+	if st.SourceResourceGroup == "" {
+		return errors.New("resume state has no source resource group")
+	}
+	return nil
+}
+
+// ResumeFileName returns the file name SaveResumeState uses for st.
+func ResumeFileName(st ResumeState) string {
+	rg := strings.Trim(unsafeFileChars.ReplaceAllString(st.SourceResourceGroup, "_"), "_.")
+	if rg == "" {
+		rg = "rg"
+	}
+	return ResumeFilePrefix + st.StartedAt.Format(resumeTimestamp) + "-" + rg + resumeFileExt
+}
+
+// SaveResumeState writes st as JSON into dir and returns the file path.
+func SaveResumeState(dir string, st ResumeState) (string, error) {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode resume state: %w", err)
+	}
+
+	name := ResumeFileName(st)
+	if err := utils.WriteOutputFile(dir, name, string(data)+"\n"); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// LoadResumeState reads and validates a file written by SaveResumeState.
+func LoadResumeState(path string) (ResumeState, error) {
+	var st ResumeState
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return st, fmt.Errorf("read resume state: %w", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("decode resume state %s: %w", path, err)
+	}
+	if err := st.Validate(); err != nil {
+		return st, fmt.Errorf("%s: %w", path, err)
+	}
+	return st, nil
+}
+
+// LatestResumeFile returns the most recent resume-*.json file in dir. The
+// timestamp in the name sorts lexically, so no stat calls are needed.
+func LatestResumeFile(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, ResumeFilePrefix+"*"+resumeFileExt))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no %s*%s files found in %s", ResumeFilePrefix, resumeFileExt, dir)
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1], nil
+}
+
+// DefaultResumeDir is where callers without an output directory (the MCP
+// server) keep resume state: <user cache dir>/armv/resume.
+func DefaultResumeDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "armv", "resume"), nil
+}
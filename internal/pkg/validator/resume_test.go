@@ -0,0 +1,141 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+)
+
+func sampleResumeState(startedAt time.Time) ResumeState {
+	return ResumeState{
+		ResumeToken:           `{"type":"Location","pollURL":"https://management.azure.com/x"}`,
+		SourceSubscriptionID:  "11111111-1111-1111-1111-111111111111",
+		SourceResourceGroup:   "rg-src",
+		TargetSubscriptionID:  "22222222-2222-2222-2222-222222222222",
+		TargetResourceGroup:   "rg-tgt",
+		TargetResourceGroupID: "/subscriptions/22222222-2222-2222-2222-222222222222/resourceGroups/rg-tgt",
+		ResourceIDs:           []string{"/subscriptions/11111111-1111-1111-1111-111111111111/resourceGroups/rg-src/providers/Microsoft.Storage/storageAccounts/sa1"},
+		StartedAt:             startedAt,
+	}
+}
+
+func TestResumeStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	want := sampleResumeState(time.Date(2026, 4, 20, 10, 45, 12, 0, time.UTC))
+
+	path, err := SaveResumeState(dir, want)
+	if err != nil {
+		t.Fatalf("SaveResumeState() error = %v", err)
+	}
+	if got := filepath.Base(path); got != "resume-2026-04-20-10-45-12-rg-src.json" {
+		t.Errorf("file name = %q", got)
+	}
+
+	got, err := LoadResumeState(path)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestLoadResumeStateRejectsInvalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	st := sampleResumeState(time.Now().UTC())
+	st.ResumeToken = ""
+	path, err := SaveResumeState(dir, st)
+	if err != nil {
+		t.Fatalf("SaveResumeState() error = %v", err)
+	}
+	if _, err := LoadResumeState(path); err == nil || !strings.Contains(err.Error(), "no resume token") {
+		t.Errorf("LoadResumeState() error = %v, want missing token error", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.json")
+	if err := os.WriteFile(garbage, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResumeState(garbage); err == nil {
+		t.Error("LoadResumeState() accepted malformed JSON")
+	}
+}
+
+func TestResumeFileNameSanitisesResourceGroup(t *testing.T) {
+	t.Parallel()
+
+	st := sampleResumeState(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	st.SourceResourceGroup = "../rg with spaces/(prod)"
+	name := ResumeFileName(st)
+	if strings.ContainsAny(name, `/\ ()`) {
+		t.Errorf("ResumeFileName() = %q contains unsafe characters", name)
+	}
+	if !strings.HasPrefix(name, "resume-2026-01-02-03-04-05-") {
+		t.Errorf("ResumeFileName() = %q", name)
+	}
+}
+
+func TestLatestResumeFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := LatestResumeFile(dir); err == nil {
+		t.Error("LatestResumeFile() on empty dir returned no error")
+	}
+
+	older := sampleResumeState(time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC))
+	newer := sampleResumeState(time.Date(2026, 4, 21, 9, 0, 0, 0, time.UTC))
+	for _, st := range []ResumeState{newer, older} {
+		if _, err := SaveResumeState(dir, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := LatestResumeFile(dir)
+	if err != nil {
+		t.Fatalf("LatestResumeFile() error = %v", err)
+	}
+	if filepath.Base(got) != ResumeFileName(newer) {
+		t.Errorf("LatestResumeFile() = %q, want %q", filepath.Base(got), ResumeFileName(newer))
+	}
+}
+
+// TestResumeGuardsRunBeforeAzure mirrors the Validate guard test: a bad state
+// or missing credential is an input error and never fires progress.
+func TestResumeGuardsRunBeforeAzure(t *testing.T) {
+	cred, err := auth.GetAzureDefaultCredential()
+	if err != nil {
+		t.Fatalf("failed to construct DefaultAzureCredential: %v", err)
+	}
+
+	noToken := sampleResumeState(time.Now())
+	noToken.ResumeToken = ""
+
+	var progress []string
+	onProgress := func(msg string) { progress = append(progress, msg) }
+
+	if _, err := Resume(context.Background(), noToken, cred, poller.PollOptions{}, onProgress); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Resume(no token) error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := Resume(context.Background(), sampleResumeState(time.Now()), nil, poller.PollOptions{}, onProgress); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Resume(nil cred) error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := Resume(context.Background(), sampleResumeState(time.Now()), cred, poller.PollOptions{Interval: time.Millisecond}, onProgress); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Resume(bad poll options) error = %v, want ErrInvalidInput", err)
+	}
+	if len(progress) != 0 {
+		t.Errorf("progress fired before guards passed: %v", progress)
+	}
+}
@@ -7,23 +7,36 @@ package validator
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AaronSaikovski/armv/cmd/armv/poller"
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
 	"github.com/AaronSaikovski/armv/internal/pkg/resourcegroups"
 	"github.com/AaronSaikovski/armv/internal/pkg/resources"
 	"github.com/AaronSaikovski/armv/internal/pkg/validation"
 	"github.com/AaronSaikovski/armv/pkg/utils"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
-// Input collects the four parameters every validate-move invocation needs.
+// Input collects the four parameters every validate-move invocation needs,
+// plus optional polling overrides (zero means the poller default) and an
+// optional subset of the source resources (empty means all of them).
 type Input struct {
 	SourceSubscriptionID string
 	SourceResourceGroup  string
 	TargetSubscriptionID string
 	TargetResourceGroup  string
+
+	// ResourceIDs restricts validation to these resources of the source
+	// resource group. Each must be in the group; matching ignores case.
+	ResourceIDs []string
+
+	PollInterval time.Duration
+	PollTimeout  time.Duration
 }
 
 // Result is the outcome of a validation, suitable for programmatic rendering.
@@ -38,6 +51,28 @@ type Result struct {
 	HTTPStatus            string
 	ResponseBody          []byte
 	Success               bool
+	PollCount             int
+	PollDuration          time.Duration
+}
+
+// RunRecord returns r as the run record every report format, and the REST
+// and MCP servers' results, are built from.
+func (r *Result) RunRecord(generatedAt time.Time) poller.RunRecord {
+	return poller.RunRecord{
+		GeneratedAt: generatedAt.UTC(),
+		Context: poller.ReportContext{
+			SourceSubscriptionID: r.SourceSubscriptionID,
+			SourceResourceGroup:  r.SourceResourceGroup,
+			TargetSubscriptionID: r.TargetSubscriptionID,
+			TargetResourceGroup:  r.TargetResourceGroup,
+			ResourceCount:        len(r.ResourceIDs),
+			PollCount:            r.PollCount,
+			PollDuration:         r.PollDuration,
+		},
+		StatusCode: r.HTTPStatusCode,
+		Status:     r.HTTPStatus,
+		Body:       string(r.ResponseBody),
+	}
 }
 
 // ProgressFn is an optional hook the caller supplies to receive human-readable
@@ -45,35 +80,54 @@ type Result struct {
 // notifications to the client. Pass nil to disable.
 type ProgressFn func(message string)
 
+// Operation is a validate-move request Azure has accepted but which may still
+// be running. Obtain one from Start or Resume and call Wait to poll it to
+// completion.
+type Operation struct {
+	in          Input
+	resourceIDs []string
+	targetRGID  string
+	startedAt   time.Time
+	poller      *runtime.Poller[armresources.ClientValidateMoveResourcesResponse]
+	notify      ProgressFn
+}
+
 // Validate runs the full resource-move validation flow without touching stdout,
 // rendering a progress bar, or writing files. The caller supplies a credential
 // so either DefaultAzureCredential or an explicit service principal can be used.
 // If onProgress is non-nil, it is called with a short message at each phase
 // (credential check, resource group checks, Azure API start, each poll tick).
 func Validate(ctx context.Context, in Input, cred azcore.TokenCredential, onProgress ProgressFn) (*Result, error) {
-	notify := func(msg string) {
-		if onProgress != nil {
-			onProgress(msg)
-		}
+	op, err := Start(ctx, in, cred, onProgress)
+	if err != nil {
+		return nil, err
 	}
+	return op.Wait(ctx)
+}
+
+// Start performs every step of Validate up to and including submitting the
+// validate-move request, and returns without polling. Callers that want to
+// survive a restart persist op.ResumeState() before calling Wait.
+func Start(ctx context.Context, in Input, cred azcore.TokenCredential, onProgress ProgressFn) (_ *Operation, err error) {
+	defer func() { countFailure(err) }()
+	notify := progressNotifier(onProgress)
 
 	if !utils.CheckValidSubscriptionID(in.SourceSubscriptionID) {
-		return nil, fmt.Errorf("invalid source subscription ID %q: must be a UUID", in.SourceSubscriptionID)
+		return nil, NewError(KindInvalidInput, fmt.Errorf("invalid source subscription ID %q: must be a UUID", in.SourceSubscriptionID))
 	}
 	if !utils.CheckValidSubscriptionID(in.TargetSubscriptionID) {
-		return nil, fmt.Errorf("invalid target subscription ID %q: must be a UUID", in.TargetSubscriptionID)
+		return nil, NewError(KindInvalidInput, fmt.Errorf("invalid target subscription ID %q: must be a UUID", in.TargetSubscriptionID))
+	}
+	pollOpts := poller.PollOptions{Interval: in.PollInterval, Timeout: in.PollTimeout}
+	if err := pollOpts.Validate(); err != nil {
+		return nil, NewError(KindInvalidInput, err)
 	}
 	if cred == nil {
-		return nil, fmt.Errorf("credential is required")
+		return nil, NewError(KindInvalidInput, fmt.Errorf("credential is required"))
 	}
 
-	notify("Verifying Azure credentials")
-	ok, err := auth.CheckLogin(ctx, cred, in.SourceSubscriptionID)
-	if err != nil {
-		return nil, fmt.Errorf("login error: %w", err)
-	}
-	if !ok {
-		return nil, fmt.Errorf("credential is not authorised for subscription %q", in.SourceSubscriptionID)
+	if err := checkLogin(ctx, cred, in.SourceSubscriptionID, notify); err != nil {
+		return nil, err
 	}
 
 	info := validation.NewAzureResourceMoveInfo(
@@ -86,25 +140,18 @@ func Validate(ctx context.Context, in Input, cred azcore.TokenCredential, onProg
 	)
 
 	notify("Enumerating resource groups and resources")
-	if err := populateResourceInfo(ctx, &info); err != nil {
+	if err := populateResourceInfo(ctx, &info, in.ResourceIDs); err != nil {
 		return nil, err
 	}
 
 	notify(fmt.Sprintf("Starting Azure validate-move for %d resource(s)", len(info.ResourceIds)))
+	stopTimer := metrics.TimePhase(metrics.PhaseSubmitting)
 	respPoller, err := info.ValidateMove(ctx)
+	stopTimer()
 	if err != nil {
-		return nil, fmt.Errorf("failed to start validate move: %w", err)
+		return nil, NewError(KindInternal, fmt.Errorf("failed to start validate move: %w", err))
 	}
 
-	respData, err := poller.PollAndCollect(ctx, respPoller, func(elapsed time.Duration) {
-		notify(fmt.Sprintf("Polling Azure validate-move (elapsed %ds)", int(elapsed.Seconds())))
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to poll validate-move API: %w", err)
-	}
-
-	notify(fmt.Sprintf("Validation complete (HTTP %d)", respData.RespStatusCode))
-
 	resourceIDs := make([]string, 0, len(info.ResourceIds))
 	for _, id := range info.ResourceIds {
 		if id != nil {
@@ -116,59 +163,264 @@ func Validate(ctx context.Context, in Input, cred azcore.TokenCredential, onProg
 		targetRGID = *info.TargetResourceGroupId
 	}
 
-	return &Result{
-		SourceSubscriptionID:  in.SourceSubscriptionID,
-		SourceResourceGroup:   in.SourceResourceGroup,
-		TargetSubscriptionID:  in.TargetSubscriptionID,
-		TargetResourceGroup:   in.TargetResourceGroup,
-		TargetResourceGroupID: targetRGID,
-		ResourceIDs:           resourceIDs,
+	return &Operation{
+		in:          in,
+		resourceIDs: resourceIDs,
+		targetRGID:  targetRGID,
+		startedAt:   time.Now().UTC(),
+		poller:      respPoller,
+		notify:      notify,
+	}, nil
+}
+
+// Resume reattaches to an operation previously started by Start (in this or
+// another process) using its saved ResumeState. pollOpts overrides the polling
+// cadence for the remainder of the operation.
+func Resume(ctx context.Context, st ResumeState, cred azcore.TokenCredential, pollOpts poller.PollOptions, onProgress ProgressFn) (_ *Operation, err error) {
+	defer func() { countFailure(err) }()
+	notify := progressNotifier(onProgress)
+
+	if err := st.Validate(); err != nil {
+		return nil, NewError(KindInvalidInput, err)
+	}
+	if err := pollOpts.Validate(); err != nil {
+		return nil, NewError(KindInvalidInput, err)
+	}
+	if cred == nil {
+		return nil, NewError(KindInvalidInput, fmt.Errorf("credential is required"))
+	}
+
+	if err := checkLogin(ctx, cred, st.SourceSubscriptionID, notify); err != nil {
+		return nil, err
+	}
+
+	notify("Reattaching to Azure validate-move")
+	respPoller, err := validation.ResumeValidateMove(ctx, cred, st.SourceSubscriptionID, st.SourceResourceGroup, st.ResumeToken)
+	if err != nil {
+		return nil, NewError(KindInvalidInput, fmt.Errorf("failed to resume validate move: %w", err))
+	}
+
+	return &Operation{
+		in: Input{
+			SourceSubscriptionID: st.SourceSubscriptionID,
+			SourceResourceGroup:  st.SourceResourceGroup,
+			TargetSubscriptionID: st.TargetSubscriptionID,
+			TargetResourceGroup:  st.TargetResourceGroup,
+			PollInterval:         pollOpts.Interval,
+			PollTimeout:          pollOpts.Timeout,
+		},
+		resourceIDs: st.ResourceIDs,
+		targetRGID:  st.TargetResourceGroupID,
+		startedAt:   st.StartedAt,
+		poller:      respPoller,
+		notify:      notify,
+	}, nil
+}
+
+// ResumeState captures the operation's resume token and request context. It
+// fails once the operation has completed, as there is nothing left to resume.
+func (op *Operation) ResumeState() (ResumeState, error) {
+	token, err := op.poller.ResumeToken()
+	if err != nil {
+		return ResumeState{}, fmt.Errorf("get resume token: %w", err)
+	}
+	return ResumeState{
+		ResumeToken:           token,
+		SourceSubscriptionID:  op.in.SourceSubscriptionID,
+		SourceResourceGroup:   op.in.SourceResourceGroup,
+		TargetSubscriptionID:  op.in.TargetSubscriptionID,
+		TargetResourceGroup:   op.in.TargetResourceGroup,
+		TargetResourceGroupID: op.targetRGID,
+		ResourceIDs:           op.resourceIDs,
+		StartedAt:             op.startedAt,
+	}, nil
+}
+
+// Wait polls the operation until Azure returns a verdict, the poll timeout
+// elapses, or ctx is cancelled. Polling events go to the operation's progress
+// callback and to any extra observers (logging, metrics).
+func (op *Operation) Wait(ctx context.Context, observers ...poller.Observer) (*Result, error) {
+	pollOpts := poller.PollOptions{Interval: op.in.PollInterval, Timeout: op.in.PollTimeout}
+	stopTimer := metrics.TimePhase(metrics.PhasePolling)
+	respData, stats, err := poller.Poll(ctx, op.poller, pollOpts, append([]poller.Observer{ProgressObserver(op.notify), metricsObserver}, observers...)...)
+	stopTimer()
+	if err != nil {
+		err = PollError(fmt.Errorf("failed to poll validate-move API: %w", err))
+		countFailure(err)
+		return nil, err
+	}
+
+	op.notify(fmt.Sprintf("Validation complete (HTTP %d)", respData.RespStatusCode))
+
+	result := &Result{
+		SourceSubscriptionID:  op.in.SourceSubscriptionID,
+		SourceResourceGroup:   op.in.SourceResourceGroup,
+		TargetSubscriptionID:  op.in.TargetSubscriptionID,
+		TargetResourceGroup:   op.in.TargetResourceGroup,
+		TargetResourceGroupID: op.targetRGID,
+		ResourceIDs:           op.resourceIDs,
 		HTTPStatusCode:        respData.RespStatusCode,
 		HTTPStatus:            respData.RespStatus,
 		ResponseBody:          respData.RespBody,
 		Success:               poller.ResourceMoveOK(respData.RespStatusCode),
-	}, nil
+		PollCount:             stats.Count,
+		PollDuration:          stats.Duration,
+	}
+	if result.Success {
+		metrics.Validations.With("valid").Inc()
+	} else {
+		metrics.Validations.With("invalid").Inc()
+	}
+	return result, nil
+}
+
+// countFailure counts a validation that ended without a verdict, by error
+// kind. It does nothing for a nil err.
+func countFailure(err error) {
+	if err != nil {
+		metrics.Validations.With(KindOf(err).String()).Inc()
+	}
+}
+
+// metricsObserver counts validate-move polls and the throttling they meet.
+var metricsObserver = poller.ObserverFunc(func(e poller.Event) {
+	switch e.Kind {
+	case poller.EventTick:
+		metrics.Polls.With().Inc()
+	case poller.EventThrottled:
+		metrics.Throttled.With(metrics.ThrottlePoll).Inc()
+	}
+})
+
+// ProgressObserver adapts a ProgressFn to a poller.Observer, turning poll
+// ticks and throttling into the human-readable messages the MCP server
+// forwards as progress notifications.
+func ProgressObserver(fn ProgressFn) poller.Observer {
+	return poller.ObserverFunc(func(e poller.Event) {
+		if fn == nil {
+			return
+		}
+		switch e.Kind {
+		case poller.EventTick:
+			fn(fmt.Sprintf("Polling Azure validate-move (elapsed %ds)", int(e.Elapsed.Seconds())))
+		case poller.EventThrottled:
+			fn(fmt.Sprintf("Azure is throttling validate-move polls; retrying in %ds", int(e.Delay.Seconds())))
+		}
+	})
+}
+
+func progressNotifier(onProgress ProgressFn) ProgressFn {
+	return func(msg string) {
+		if onProgress != nil {
+			onProgress(msg)
+		}
+	}
+}
+
+func checkLogin(ctx context.Context, cred azcore.TokenCredential, subscriptionID string, notify ProgressFn) error {
+	defer metrics.TimePhase(metrics.PhaseLoginCheck)()
+	notify("Verifying Azure credentials")
+	ok, err := auth.CheckLogin(ctx, cred, subscriptionID)
+	if err != nil {
+		return NewError(KindAuth, fmt.Errorf("login error: %w", err))
+	}
+	if !ok {
+		return NewError(KindAuth, fmt.Errorf("credential is not authorised for subscription %q", subscriptionID))
+	}
+	return nil
 }
 
-func populateResourceInfo(ctx context.Context, info *validation.AzureResourceMoveInfo) error {
+func populateResourceInfo(ctx context.Context, info *validation.AzureResourceMoveInfo, want []string) error {
+	stopTimer := metrics.TimePhase(metrics.PhaseResourceGroups)
+	defer func() { stopTimer() }()
+
 	resourceGroupClient, err := resourcegroups.GetResourceGroupClient(info.Credentials, info.SourceSubscriptionId)
 	if err != nil {
-		return fmt.Errorf("failed to get resource group client: %w", err)
+		return NewError(KindInternal, fmt.Errorf("failed to get resource group client: %w", err))
 	}
 
 	srcExists, err := resourcegroups.CheckResourceGroupExists(ctx, resourceGroupClient, info.SourceResourceGroup)
 	if err != nil {
-		return err
+		return NewError(KindInternal, err)
 	}
 	if !srcExists {
-		return fmt.Errorf("source resource group %q does not exist", info.SourceResourceGroup)
+		return NewError(KindResourceGroupNotFound, fmt.Errorf("source resource group %q does not exist", info.SourceResourceGroup))
 	}
 
 	dstExists, err := resourcegroups.CheckResourceGroupExists(ctx, resourceGroupClient, info.TargetResourceGroup)
 	if err != nil {
-		return err
+		return NewError(KindInternal, err)
 	}
 	if !dstExists {
-		return fmt.Errorf("target resource group %q does not exist", info.TargetResourceGroup)
+		return NewError(KindResourceGroupNotFound, fmt.Errorf("target resource group %q does not exist", info.TargetResourceGroup))
 	}
+	stopTimer()
+	stopTimer = metrics.TimePhase(metrics.PhaseEnumerating)
 
 	resourcesClient, err := resources.GetResourcesClient(info.Credentials, info.SourceSubscriptionId)
 	if err != nil {
-		return err
+		return NewError(KindInternal, err)
 	}
 
 	info.ResourceIds, err = resources.GetResourceIds(ctx, resourcesClient, info.SourceResourceGroup)
 	if err != nil {
-		return fmt.Errorf("failed to get resource IDs: %w", err)
+		return NewError(KindInternal, fmt.Errorf("failed to get resource IDs: %w", err))
 	}
 	if len(info.ResourceIds) == 0 {
-		return fmt.Errorf("no resources found in source resource group %q", info.SourceResourceGroup)
+		return NewError(KindInvalidInput, fmt.Errorf("no resources found in source resource group %q", info.SourceResourceGroup))
+	}
+	if info.ResourceIds, err = SelectResources(info.ResourceIds, want, info.SourceResourceGroup); err != nil {
+		return err
 	}
 
 	info.TargetResourceGroupId, err = resourcegroups.GetResourceGroupId(ctx, resourceGroupClient, info.TargetResourceGroup)
 	if err != nil {
-		return fmt.Errorf("failed to get target resource group ID: %w", err)
+		return NewError(KindInternal, fmt.Errorf("failed to get target resource group ID: %w", err))
 	}
 
 	return nil
 }
+
+// SelectResources returns the IDs in all that are listed in want, in the
+// order of all. An empty want selects everything. ARM resource IDs are
+// case-insensitive, so matching ignores case; an ID not in the resource
+// group is an input error rather than being silently dropped.
+func SelectResources(all []*string, want []string, resourceGroup string) ([]*string, error) {
+	if len(want) == 0 {
+		return all, nil
+	}
+
+	wanted := make(map[string]bool, len(want))
+	for _, id := range want {
+		if id = strings.TrimSpace(id); id != "" {
+			wanted[strings.ToLower(id)] = false
+		}
+	}
+	selected := make([]*string, 0, len(wanted))
+	for _, id := range all {
+		if id == nil {
+			continue
+		}
+		key := strings.ToLower(*id)
+		if seen, ok := wanted[key]; ok && !seen {
+			wanted[key] = true
+			selected = append(selected, id)
+		}
+	}
+
+	var missing []string
+	for _, id := range want {
+		id = strings.TrimSpace(id)
+		if key := strings.ToLower(id); id != "" && !wanted[key] {
+			missing = append(missing, id)
+			wanted[key] = true // report each once
+		}
+	}
+	if len(missing) > 0 {
+		return nil, NewError(KindInvalidInput, fmt.Errorf("%d selected resource(s) not in source resource group %q: %s", len(missing), resourceGroup, strings.Join(missing, ", ")))
+	}
+	if len(selected) == 0 {
+		return nil, NewError(KindInvalidInput, fmt.Errorf("no resources selected in source resource group %q", resourceGroup))
+	}
+	return selected, nil
+}
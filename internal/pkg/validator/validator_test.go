@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/AaronSaikovski/armv/internal/pkg/auth"
+	"github.com/AaronSaikovski/armv/internal/pkg/metrics"
 )
 
 // TestValidateEarlyReturnsDoNotFireProgress locks in that the input-validation
@@ -120,3 +121,41 @@ func TestValidateAcceptsNilProgress(t *testing.T) {
 		t.Fatal("expected error for invalid UUID, got nil")
 	}
 }
+
+// TestValidateCountsFailuresByKind checks that a validation stopped before
+// reaching Azure is still counted, under its error kind.
+func TestValidateCountsFailuresByKind(t *testing.T) {
+	counter := metrics.Validations.With(KindInvalidInput.String())
+	before := counter.Value()
+
+	_, err := Validate(context.Background(), Input{SourceSubscriptionID: "not-a-uuid"}, nil, nil)
+	if KindOf(err) != KindInvalidInput {
+		t.Fatalf("error kind = %v, want invalid input (error %v)", KindOf(err), err)
+	}
+	if got := counter.Value() - before; got != 1 {
+		t.Errorf("invalid_input validations counted = %v, want 1", got)
+	}
+}
+
+func TestSelectResources(t *testing.T) {
+	t.Parallel()
+
+	a, b, c := "/subscriptions/s/resourceGroups/rg/providers/P/t/a", "/subscriptions/s/resourceGroups/rg/providers/P/t/b", "/subscriptions/s/resourceGroups/rg/providers/P/t/c"
+	all := []*string{&a, &b, &c}
+
+	got, err := SelectResources(all, nil, "rg")
+	if err != nil || len(got) != 3 {
+		t.Errorf("SelectResources(nil) = (%d ids, %v), want all 3", len(got), err)
+	}
+
+	// Matching ignores case and duplicates, and keeps the group's order.
+	got, err = SelectResources(all, []string{strings.ToUpper(c), a, a}, "rg")
+	if err != nil || len(got) != 2 || *got[0] != a || *got[1] != c {
+		t.Errorf("SelectResources(c, a) = (%v, %v)", got, err)
+	}
+
+	_, err = SelectResources(all, []string{a, "/subscriptions/s/resourceGroups/rg/providers/P/t/x"}, "rg")
+	if KindOf(err) != KindInvalidInput || !strings.Contains(err.Error(), "/t/x") {
+		t.Errorf("SelectResources(unknown) error = %v, want an input error naming it", err)
+	}
+}
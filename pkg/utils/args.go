@@ -2,6 +2,8 @@
 // version management, and common helper functions for the ARMV application.
 package utils
 
+import "time"
+
 const (
 	// AppDescription is the application description
 	AppDescription = `ARMV - Azure Resource Movability Validator
@@ -18,6 +20,13 @@ type Args struct {
 	TargetResourceGroup  string
 	Debug                bool
 	OutputPath           string
+	PollInterval         time.Duration
+	PollTimeout          time.Duration
+	AuthMode             string
+	TenantID             string
+	ClientID             string
+	ReportFormats        []string
+	ResourceIDs          []string
 }
 
 // FormatVersion returns the formatted version string for display.
@@ -1,6 +1,8 @@
 package test
 
 import (
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/AaronSaikovski/armv/cmd/armv/app"
@@ -76,6 +78,8 @@ func TestRootCommandFlags(t *testing.T) {
 		{name: "target-resource-group", flagName: "target-resource-group", flagType: "string"},
 		{name: "debug", flagName: "debug", flagType: "bool"},
 		{name: "output-path", flagName: "output-path", flagType: "string", defaultValue: app.DefaultOutputPath},
+		{name: "poll-interval", flagName: "poll-interval", flagType: "duration", defaultValue: "2s"},
+		{name: "poll-timeout", flagName: "poll-timeout", flagType: "duration", defaultValue: "30m0s"},
 	}
 
 	for _, tt := range tests {
@@ -95,12 +99,47 @@ func TestRootCommandFlags(t *testing.T) {
 	}
 }
 
-// TestRootCommandRequiredFlags verifies every required flag is marked as such.
-// Guards against accidental removal of MarkFlagRequired after the loop refactor.
+// TestRootCommandLoggingFlags verifies the logging flags are persistent, so
+// they also apply to subcommands such as report and resume.
+func TestRootCommandLoggingFlags(t *testing.T) {
+	t.Parallel()
+
+	cmd := app.NewRootCommand("test-version")
+
+	tests := []struct {
+		flagName     string
+		flagType     string
+		defaultValue string
+	}{
+		{flagName: "log-level", flagType: "string", defaultValue: app.DefaultLogLevel},
+		{flagName: "log-format", flagType: "string", defaultValue: app.DefaultLogFormat},
+		{flagName: "trace-http", flagType: "bool", defaultValue: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			t.Parallel()
+			flag := cmd.PersistentFlags().Lookup(tt.flagName)
+			if flag == nil {
+				t.Fatalf("persistent flag %q not found", tt.flagName)
+			}
+			if flag.Value.Type() != tt.flagType {
+				t.Errorf("type = %q, want %q", flag.Value.Type(), tt.flagType)
+			}
+			if flag.DefValue != tt.defaultValue {
+				t.Errorf("default = %q, want %q", flag.DefValue, tt.defaultValue)
+			}
+		})
+	}
+}
+
+// TestRootCommandRequiredFlags verifies the four IDs are still required, but
+// only after ARMV_* variables and the config profile have been applied: they
+// are not cobra-required (which would reject a profile-only run), and a run
+// without them fails as an input error naming every missing flag.
 func TestRootCommandRequiredFlags(t *testing.T) {
 	t.Parallel()
 
-	cmd := app.NewRootCommand("test")
 	required := []string{
 		"source-subscription-id",
 		"source-resource-group",
@@ -108,14 +147,27 @@ func TestRootCommandRequiredFlags(t *testing.T) {
 		"target-resource-group",
 	}
 
+	cmd := app.NewRootCommand("test")
 	for _, name := range required {
 		flag := cmd.Flags().Lookup(name)
 		if flag == nil {
 			t.Fatalf("flag %q not found", name)
 		}
-		annotations := flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]
-		if len(annotations) == 0 || annotations[0] != "true" {
-			t.Errorf("flag %q is not marked required (annotations=%v)", name, annotations)
+		if annotations := flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]; len(annotations) != 0 {
+			t.Errorf("flag %q is cobra-required, so it cannot come from the environment or a profile", name)
+		}
+	}
+
+	cmd.SetArgs([]string{"--config", writeConfig(t, "profiles: {}\n"), "--source-resource-group", "rg-src"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	if got := app.ExitCode(err); got != app.ExitInputError {
+		t.Fatalf("ExitCode(%v) = %d, want %d", err, got, app.ExitInputError)
+	}
+	for _, name := range required {
+		if mentioned := strings.Contains(err.Error(), "--"+name); mentioned == (name == "source-resource-group") {
+			t.Errorf("error %q: mention of --%s = %v", err, name, mentioned)
 		}
 	}
 }
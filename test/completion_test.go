@@ -0,0 +1,86 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+)
+
+// complete runs cobra's hidden __complete command and returns the
+// candidates, without descriptions or the trailing directive line.
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	cmd := app.NewRootCommand("test")
+	var out bytes.Buffer
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("__complete %v: %v", args, err)
+	}
+
+	var got []string
+	for line := range strings.SplitSeq(strings.TrimSpace(out.String()), "\n") {
+		if strings.HasPrefix(line, ":") {
+			break
+		}
+		value, _, _ := strings.Cut(line, "\t")
+		got = append(got, value)
+	}
+	return got
+}
+
+func TestCompletionScripts(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			t.Parallel()
+			cmd := app.NewRootCommand("test")
+			var out bytes.Buffer
+			cmd.SetArgs([]string{"completion", shell})
+			cmd.SetOut(&out)
+			if err := cmd.Execute(); err != nil || !strings.Contains(out.String(), "armv") {
+				t.Errorf("completion %s = (%d bytes, %v)", shell, out.Len(), err)
+			}
+		})
+	}
+
+	cmd := app.NewRootCommand("test")
+	cmd.SetArgs([]string{"completion", "powershell"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if got := app.ExitCode(cmd.Execute()); got != app.ExitInputError {
+		t.Errorf("completion powershell exit code = %d, want %d", got, app.ExitInputError)
+	}
+}
+
+func TestStaticFlagCompletion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "auth mode", args: []string{"--auth-mode", ""}, want: []string{"default", "azure-cli", "managed-identity", "client-secret"}},
+		{name: "report formats skip listed ones", args: []string{"--report-formats", "md,"}, want: []string{"md,html", "md,sarif", "md,junit"}},
+		{name: "list format", args: []string{"list", "groups", "--format", "j"}, want: []string{"table", "json", "csv"}},
+		{name: "profiles from the config file", args: []string{"--config", writeConfig(t, profileConfig), "--profile", ""}, want: []string{"dev", "prod-to-shared"}},
+		// Without a subscription there is nothing to look groups up in,
+		// so no Azure call is made and nothing is offered.
+		{name: "resource group needs a subscription", args: []string{"--config", writeConfig(t, "profiles: {}\n"), "--source-resource-group", ""}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := complete(t, tt.args...); !slices.Equal(got, tt.want) {
+				t.Errorf("complete(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
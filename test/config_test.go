@@ -0,0 +1,216 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+)
+
+// writeConfig writes a config file into a temp dir and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const profileConfig = `default_profile: prod-to-shared
+profiles:
+  prod-to-shared:
+    source_subscription_id: 00000000-0000-0000-0000-000000000000
+    source_resource_group: rg-prod
+    target_subscription_id: 11111111-1111-1111-1111-111111111111
+    target_resource_group: rg-shared
+    auth:
+      mode: azure-cli
+      tenant_id: tenant-1
+    report_formats: [md, sarif]
+    poll_interval: 5s
+    poll_timeout: 45m
+  dev:
+    source_resource_group: rg-dev
+`
+
+// configShow runs `armv config show` with args and returns its output.
+func configShow(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := app.NewRootCommand("test")
+	var out bytes.Buffer
+	cmd.SetArgs(append([]string{"config", "show"}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// settingRow returns the whitespace-separated fields of the row for name.
+func settingRow(t *testing.T, out, name string) []string {
+	t.Helper()
+	for line := range strings.SplitSeq(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return fields
+		}
+	}
+	t.Fatalf("no %q row in:\n%s", name, out)
+	return nil
+}
+
+// TestConfigPrecedence pins flag > env > profile > default. It sets
+// environment variables, so it cannot run in parallel.
+func TestConfigPrecedence(t *testing.T) {
+	path := writeConfig(t, profileConfig)
+	t.Setenv("ARMV_POLL_INTERVAL", "7s")
+	t.Setenv("ARMV_SOURCE_RESOURCE_GROUP", "rg-from-env")
+	t.Setenv("ARMV_TENANT_ID", "tenant-from-env")
+
+	out, err := configShow(t, "--config", path, "--tenant-id", "tenant-from-flag", "--auth-mode", "managed-identity")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if !strings.Contains(out, "Profile:     prod-to-shared") {
+		t.Errorf("default_profile not selected:\n%s", out)
+	}
+
+	tests := []struct {
+		setting string
+		value   string
+		source  string
+	}{
+		{setting: "tenant-id", value: "tenant-from-flag", source: "flag"},
+		{setting: "auth-mode", value: "managed-identity", source: "flag"},
+		{setting: "poll-interval", value: "7s", source: "env"},
+		{setting: "source-resource-group", value: "rg-from-env", source: "env"},
+		{setting: "target-resource-group", value: "rg-shared", source: "profile"},
+		{setting: "poll-timeout", value: "45m0s", source: "profile"},
+		{setting: "report-formats", value: "md,sarif", source: "profile"},
+		{setting: "client-id", value: `""`, source: "default"},
+		{setting: "output-path", value: app.DefaultOutputPath, source: "default"},
+	}
+	for _, tt := range tests {
+		row := settingRow(t, out, tt.setting)
+		if len(row) < 3 || row[1] != tt.value || row[2] != tt.source {
+			t.Errorf("%s = %v, want value %q from %s", tt.setting, row, tt.value, tt.source)
+		}
+	}
+}
+
+func TestConfigProfileSelection(t *testing.T) {
+	path := writeConfig(t, profileConfig)
+	t.Setenv("ARMV_PROFILE", "dev")
+
+	out, err := configShow(t, "--config", path)
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if row := settingRow(t, out, "source-resource-group"); row[1] != "rg-dev" {
+		t.Errorf("ARMV_PROFILE=dev not applied: %v", row)
+	}
+
+	out, err = configShow(t, "--config", path, "--profile", "prod-to-shared")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if row := settingRow(t, out, "source-resource-group"); row[1] != "rg-prod" {
+		t.Errorf("--profile did not override ARMV_PROFILE: %v", row)
+	}
+}
+
+func TestConfigErrorsAreInputErrors(t *testing.T) {
+	t.Parallel()
+
+	missing := filepath.Join(t.TempDir(), "nope.yaml")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "explicit config file missing", args: []string{"--config", missing}},
+		{name: "unknown key", args: []string{"--config", writeConfig(t, "profiles:\n  p:\n    poll_timout: 5m\n")}},
+		{name: "unknown profile", args: []string{"--config", writeConfig(t, profileConfig), "--profile", "staging"}},
+		{name: "undefined default profile", args: []string{"--config", writeConfig(t, "default_profile: x\nprofiles: {}\n")}},
+		{name: "bad duration in profile", args: []string{"--config", writeConfig(t, "profiles:\n  p:\n    poll_timeout: soon\n"), "--profile", "p"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := configShow(t, tt.args...)
+			if got := app.ExitCode(err); got != app.ExitInputError {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, app.ExitInputError)
+			}
+		})
+	}
+}
+
+func TestConfigInvalidEnvValue(t *testing.T) {
+	t.Setenv("ARMV_POLL_TIMEOUT", "forever")
+
+	_, err := configShow(t, "--config", writeConfig(t, "profiles: {}\n"))
+	if got := app.ExitCode(err); got != app.ExitInputError || !strings.Contains(err.Error(), "ARMV_POLL_TIMEOUT") {
+		t.Errorf("ExitCode(%v) = %d, want %d naming ARMV_POLL_TIMEOUT", err, got, app.ExitInputError)
+	}
+}
+
+// TestConfigProfileResourceIDs covers the resource subset `armv interactive`
+// saves when some resources are deselected.
+func TestConfigProfileResourceIDs(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "profiles:\n  p:\n    resource_ids:\n      - /subscriptions/s/resourceGroups/rg/providers/P/t/a\n      - /subscriptions/s/resourceGroups/rg/providers/P/t/b\n")
+	out, err := configShow(t, "--config", path, "--profile", "p")
+	if err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	row := settingRow(t, out, "resource-ids")
+	want := "/subscriptions/s/resourceGroups/rg/providers/P/t/a,/subscriptions/s/resourceGroups/rg/providers/P/t/b"
+	if row[1] != want || row[2] != "profile" {
+		t.Errorf("resource-ids = %v, want %q from profile", row, want)
+	}
+}
+
+// TestSubcommandFlagsIgnoreEnv checks that only run settings come from ARMV_*
+// variables: a value meant for one subcommand's flag must not reach another
+// subcommand's flag of the same name. It sets environment variables, so it
+// cannot run in parallel.
+func TestSubcommandFlagsIgnoreEnv(t *testing.T) {
+	// sarif is valid for `report render --format`, not for `list --format`.
+	t.Setenv("ARMV_FORMAT", "sarif")
+	// A file given as ARMV_TOKEN_FILE must not become the server's token.
+	t.Setenv("ARMV_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("ARMV_API_TOKEN", "")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "list",
+			args:    []string{"list", "groups", "--subscription-id", testSubscriptionID, "--sort", "size"},
+			wantErr: "sort",
+		},
+		{
+			name:    "serve",
+			args:    []string{"serve", "--listen", "127.0.0.1:0"},
+			wantErr: "ARMV_API_TOKEN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := app.NewRootCommand("test")
+			cmd.SetArgs(append(tt.args, "--config", writeConfig(t, "profiles: {}\n")))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.Execute()
+			if got := app.ExitCode(err); got != app.ExitInputError || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() = %v (exit %d), want an input error about %s", err, got, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+	"github.com/AaronSaikovski/armv/internal/pkg/validator"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil is success", err: nil, want: app.ExitOK},
+		{name: "unclassified is internal", err: errors.New("boom"), want: app.ExitInternalError},
+		{name: "validation failed", err: validator.NewError(validator.KindValidationFailed, errors.New("409")), want: app.ExitValidationFailed},
+		{name: "invalid input", err: validator.NewError(validator.KindInvalidInput, errors.New("bad uuid")), want: app.ExitInputError},
+		{name: "auth", err: validator.NewError(validator.KindAuth, errors.New("401")), want: app.ExitAuthFailure},
+		{name: "resource group not found", err: validator.NewError(validator.KindResourceGroupNotFound, errors.New("rg")), want: app.ExitResourceGroupNotFound},
+		{name: "poll timeout", err: validator.NewError(validator.KindPollTimeout, errors.New("deadline")), want: app.ExitPollTimeout},
+		{name: "interrupted", err: validator.NewError(validator.KindInterrupted, errors.New("signal")), want: app.ExitInterrupted},
+		{name: "wrapped classification survives", err: fmt.Errorf("outer: %w", validator.NewError(validator.KindAuth, errors.New("401"))), want: app.ExitAuthFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := app.ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRootCommandUsageErrorsAreInputErrors verifies cobra-level failures
+// (missing required flags, unknown flags) exit with ExitInputError rather
+// than the generic internal-error code.
+func TestRootCommandUsageErrorsAreInputErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing required flags", args: []string{}},
+		{name: "unknown flag", args: []string{"--no-such-flag"}},
+		{name: "invalid log level", args: []string{"--log-level", "verbose"}},
+		{name: "invalid log format", args: []string{"--log-format", "xml"}},
+		{name: "interactive with unsupported report format", args: []string{"interactive", "--report-formats", "pdf"}},
+		{name: "interactive with too-short poll interval", args: []string{"interactive", "--poll-interval", "10ms"}},
+		{name: "interactive with unknown auth mode", args: []string{"interactive", "--auth-mode", "password"}},
+		{name: "malformed subscription ID", args: []string{
+			"--source-subscription-id", "not-a-uuid",
+			"--source-resource-group", "rg-src",
+			"--target-subscription-id", "11111111-1111-1111-1111-111111111111",
+			"--target-resource-group", "rg-dst",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := app.NewRootCommand("test")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			if got := app.ExitCode(err); got != app.ExitInputError {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, app.ExitInputError)
+			}
+		})
+	}
+}
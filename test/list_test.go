@@ -0,0 +1,43 @@
+package test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+)
+
+const testSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+// TestListInputErrors checks that bad list options are rejected as input
+// errors before any credential is built or Azure is called.
+func TestListInputErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown format", args: []string{"subscriptions", "--format", "xml"}},
+		{name: "unknown sort column", args: []string{"subscriptions", "--sort", "location"}},
+		{name: "groups without subscription", args: []string{"groups"}},
+		{name: "groups with malformed subscription", args: []string{"groups", "--subscription-id", "not-a-uuid"}},
+		{name: "type filter on groups", args: []string{"groups", "--subscription-id", testSubscriptionID, "--type", "Microsoft.Web/sites"}},
+		{name: "resources without group", args: []string{"resources", "--subscription-id", testSubscriptionID}},
+		{name: "resources with bad sort", args: []string{"resources", "--subscription-id", testSubscriptionID, "--resource-group", "rg", "--sort", "-size"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := app.NewRootCommand("test")
+			cmd.SetArgs(append([]string{"list", "--config", writeConfig(t, "profiles: {}\n")}, tt.args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.Execute()
+			if got := app.ExitCode(err); got != app.ExitInputError {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, got, app.ExitInputError)
+			}
+		})
+	}
+}
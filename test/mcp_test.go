@@ -0,0 +1,46 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+)
+
+// TestMCPServeTokenErrors checks that `mcp serve` refuses to start without a
+// usable bearer token for --listen or with bad job limits. It clears
+// ARMV_MCP_TOKEN, so it cannot run in parallel.
+func TestMCPServeInputErrors(t *testing.T) {
+	t.Setenv("ARMV_MCP_TOKEN", "")
+
+	weak := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(weak, []byte("short\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no token", args: []string{"--listen", "127.0.0.1:0"}},
+		{name: "missing token file", args: []string{"--listen", "127.0.0.1:0", "--token-file", filepath.Join(t.TempDir(), "nope")}},
+		{name: "weak token", args: []string{"--listen", "127.0.0.1:0", "--token-file", weak}},
+		{name: "token file without listen", args: []string{"--token-file", weak}},
+		{name: "zero max jobs", args: []string{"--max-jobs", "0"}},
+		{name: "zero max validations", args: []string{"--max-validations", "0"}},
+		{name: "zero job retention", args: []string{"--job-retention", "0s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := app.NewRootCommand("test")
+			cmd.SetArgs(append([]string{"mcp", "serve", "--config", writeConfig(t, "profiles: {}\n")}, tt.args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			if got := app.ExitCode(cmd.Execute()); got != app.ExitInputError {
+				t.Errorf("exit code = %d, want %d", got, app.ExitInputError)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+)
+
+func TestPartialReportRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	p := poller.PartialReport{
+		InterruptedAt: time.Date(2026, 4, 20, 10, 45, 12, 0, time.UTC),
+		Phase:         poller.PhasePolling,
+		Elapsed:       95 * time.Second,
+		Context: poller.ReportContext{
+			SourceSubscriptionID: "11111111-1111-1111-1111-111111111111",
+			SourceResourceGroup:  "rg-src",
+			TargetSubscriptionID: "22222222-2222-2222-2222-222222222222",
+			TargetResourceGroup:  "rg-dst",
+		},
+		ResourceIDs: []string{
+			"/subscriptions/abc/resourceGroups/rg-src/providers/Microsoft.Storage/storageAccounts/sa1",
+			"/subscriptions/abc/resourceGroups/rg-src/providers/Microsoft.Web/sites/app|1",
+		},
+		ResumeToken: `{"type":"Location"}`,
+		ResumeFile:  "output/resume-2026-04-20-10-43-37-rg-src.json",
+	}
+
+	got := p.RenderMarkdown()
+	for _, want := range []string{
+		"(INTERRUPTED)",
+		"- **Phase reached:** polling validate-move",
+		"- **Elapsed:** 1m35s",
+		"armv resume --token-file output/resume-2026-04-20-10-43-37-rg-src.json",
+		`{"type":"Location"}`,
+		"## Resource Inventory (2)",
+		"| 1 | Microsoft.Storage/storageAccounts | sa1 |",
+		`| 2 | Microsoft.Web/sites | app\|1 |`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("partial report missing %q\n%s", want, got)
+		}
+	}
+}
+
+func TestPartialReportBeforeSubmission(t *testing.T) {
+	t.Parallel()
+
+	got := poller.PartialReport{Phase: poller.PhaseLoginCheck}.RenderMarkdown()
+	if !strings.Contains(got, "had not been accepted") || !strings.Contains(got, "Resources had not been enumerated") {
+		t.Errorf("unexpected report before submission:\n%s", got)
+	}
+	if strings.Contains(got, "Resume Token") {
+		t.Errorf("report without a token rendered a resume section:\n%s", got)
+	}
+}
+
+func TestWritePartialReport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	at := time.Date(2026, 4, 20, 10, 45, 12, 0, time.UTC)
+	name, err := poller.WritePartialReport(dir, poller.PartialReport{InterruptedAt: at, Phase: poller.PhaseEnumerating})
+	if err != nil {
+		t.Fatalf("WritePartialReport() error = %v", err)
+	}
+	if name != "partial-2026-04-20-10-45-12.md" {
+		t.Errorf("file name = %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "enumerating resources") {
+		t.Errorf("written report missing phase:\n%s", data)
+	}
+}
@@ -229,3 +229,20 @@ func TestTopFailureNames(t *testing.T) {
 		t.Error("empty report should return nil")
 	}
 }
+
+func TestRenderMarkdown_PollingStats(t *testing.T) {
+	t.Parallel()
+
+	report := poller.BuildValidationReport(204, "No Content", nil, "", poller.ReportContext{
+		PollCount:    12,
+		PollDuration: 24*time.Second + 400*time.Millisecond,
+	})
+	if md := poller.RenderMarkdown(report); !strings.Contains(md, "**Polling:** 12 polls over 24s") {
+		t.Errorf("expected polling stats line, got:\n%s", md)
+	}
+
+	noPolling := poller.BuildValidationReport(204, "No Content", nil, "", poller.ReportContext{})
+	if md := poller.RenderMarkdown(noPolling); strings.Contains(md, "**Polling:**") {
+		t.Errorf("polling line should be omitted when no polls ran, got:\n%s", md)
+	}
+}
@@ -0,0 +1,231 @@
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/poller"
+)
+
+var failedRunBody = []byte(`{
+  "error": {
+    "code": "ResourceMoveValidationFailed",
+    "message": "Move validation failed.",
+    "details": [
+      {"code": "ResourceMoveNotSupported", "target": "/subscriptions/s/resourceGroups/r/providers/Microsoft.ContainerInstance/containerGroups/aci<1>", "message": "not supported"},
+      {"code": "ResourceMoveNotSupported", "target": "/subscriptions/s/resourceGroups/r/providers/Microsoft.Web/sites/app", "message": "also not supported"}
+    ]
+  }
+}`)
+
+func failedReport() poller.ValidationReport {
+	rec := poller.RunRecord{
+		GeneratedAt: time.Date(2026, 4, 20, 10, 45, 12, 0, time.UTC),
+		Context: poller.ReportContext{
+			SourceSubscriptionID: "sub-src",
+			SourceResourceGroup:  "rg-src",
+			TargetSubscriptionID: "sub-dst",
+			TargetResourceGroup:  "rg-dst",
+			ResourceCount:        5,
+		},
+		StatusCode: 409,
+		Status:     "Conflict",
+		Body:       string(failedRunBody),
+	}
+	return rec.Report()
+}
+
+func TestParseReportFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    poller.ReportFormat
+		wantErr bool
+	}{
+		{in: "md", want: poller.FormatMarkdown},
+		{in: "markdown", want: poller.FormatMarkdown},
+		{in: "HTML", want: poller.FormatHTML},
+		{in: " sarif ", want: poller.FormatSARIF},
+		{in: "junit", want: poller.FormatJUnit},
+		{in: "pdf", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := poller.ParseReportFormat(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseReportFormat(%q) = %q, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReportFormat(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReportFormat(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRecordReportUsesRecordedTimestamp(t *testing.T) {
+	t.Parallel()
+
+	report := failedReport()
+	if want := time.Date(2026, 4, 20, 10, 45, 12, 0, time.UTC); !report.GeneratedAt.Equal(want) {
+		t.Errorf("GeneratedAt = %v, want %v", report.GeneratedAt, want)
+	}
+	if len(report.Errors) != 2 {
+		t.Fatalf("expected 2 parsed errors, got %d", len(report.Errors))
+	}
+	if report.RawJSON == "" {
+		t.Error("expected pretty-printed RawJSON for a 409 record")
+	}
+}
+
+func TestRenderHTML_EscapesAndTabulates(t *testing.T) {
+	t.Parallel()
+
+	out, err := poller.Render(failedReport(), poller.FormatHTML)
+	if err != nil {
+		t.Fatalf("Render html: %v", err)
+	}
+
+	for _, want := range []string{
+		"<h1>Azure Resource Move Validation Report</h1>",
+		"FAILED (2 errors)",
+		"<code>ResourceMoveValidationFailed</code>",
+		"<h2>Summary</h2>",
+		"aci&lt;1&gt;",
+		"<h2>Raw Azure API Response</h2>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+	if strings.Contains(out, "aci<1>") {
+		t.Error("resource name was not HTML-escaped")
+	}
+}
+
+func TestRenderSARIF(t *testing.T) {
+	t.Parallel()
+
+	out, err := poller.Render(failedReport(), poller.FormatSARIF)
+	if err != nil {
+		t.Fatalf("Render sarif: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					LogicalLocations []struct {
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(out), &log); err != nil {
+		t.Fatalf("sarif output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected sarif envelope: version=%q runs=%d", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "armv" {
+		t.Errorf("driver name = %q, want armv", run.Tool.Driver.Name)
+	}
+	if len(run.Tool.Driver.Rules) != 1 {
+		t.Errorf("expected duplicate codes to collapse into 1 rule, got %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+	if got := run.Results[1].Locations[0].LogicalLocations[0].FullyQualifiedName; !strings.HasSuffix(got, "/sites/app") {
+		t.Errorf("result location = %q, want the failing resource ID", got)
+	}
+}
+
+func TestRenderSARIF_SuccessHasNoResults(t *testing.T) {
+	t.Parallel()
+
+	report := poller.BuildValidationReport(204, "No Content", nil, "", poller.ReportContext{})
+	out, err := poller.RenderSARIF(report)
+	if err != nil {
+		t.Fatalf("RenderSARIF: %v", err)
+	}
+	if !strings.Contains(out, `"results": []`) {
+		t.Errorf("expected an empty results array for a successful run, got:\n%s", out)
+	}
+}
+
+func TestRenderJUnit(t *testing.T) {
+	t.Parallel()
+
+	out, err := poller.Render(failedReport(), poller.FormatJUnit)
+	if err != nil {
+		t.Fatalf("Render junit: %v", err)
+	}
+
+	var doc struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Type string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("junit output is not valid XML: %v", err)
+	}
+	if doc.Tests != 3 || doc.Failures != 3 {
+		t.Errorf("tests=%d failures=%d, want 3/3 (overall + 2 resources)", doc.Tests, doc.Failures)
+	}
+	if len(doc.Suites) != 1 || doc.Suites[0].Name != "rg-src -> rg-dst" {
+		t.Fatalf("unexpected suites: %+v", doc.Suites)
+	}
+	if first := doc.Suites[0].Cases[0]; first.Name != "validate-move" || first.Failure == nil || first.Failure.Type != "ResourceMoveValidationFailed" {
+		t.Errorf("unexpected overall case: %+v", first)
+	}
+}
+
+func TestRenderJUnit_SuccessPasses(t *testing.T) {
+	t.Parallel()
+
+	report := poller.BuildValidationReport(204, "No Content", nil, "", poller.ReportContext{})
+	out, err := poller.RenderJUnit(report)
+	if err != nil {
+		t.Fatalf("RenderJUnit: %v", err)
+	}
+	if !strings.Contains(out, `tests="1" failures="0"`) {
+		t.Errorf("expected a single passing case, got:\n%s", out)
+	}
+	if strings.Contains(out, "<failure") {
+		t.Error("successful run should not contain a failure element")
+	}
+}
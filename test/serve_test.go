@@ -0,0 +1,50 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AaronSaikovski/armv/cmd/armv/app"
+)
+
+// TestServeInputErrors checks that `serve` refuses to start without an
+// address, a usable bearer token or sane job limits. It clears
+// ARMV_API_TOKEN, so it cannot run in parallel.
+func TestServeInputErrors(t *testing.T) {
+	t.Setenv("ARMV_API_TOKEN", "")
+
+	weak := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(weak, []byte("short\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	strong := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(strong, []byte("0123456789abcdef0123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no listen", args: []string{"--token-file", strong}},
+		{name: "no token", args: []string{"--listen", "127.0.0.1:0"}},
+		{name: "missing token file", args: []string{"--listen", "127.0.0.1:0", "--token-file", filepath.Join(t.TempDir(), "nope")}},
+		{name: "weak token", args: []string{"--listen", "127.0.0.1:0", "--token-file", weak}},
+		{name: "zero max jobs", args: []string{"--listen", "127.0.0.1:0", "--token-file", strong, "--max-jobs", "0"}},
+		{name: "zero job retention", args: []string{"--listen", "127.0.0.1:0", "--token-file", strong, "--job-retention", "0s"}},
+		{name: "zero drain period", args: []string{"--listen", "127.0.0.1:0", "--token-file", strong, "--drain-period", "0s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := app.NewRootCommand("test")
+			cmd.SetArgs(append([]string{"serve", "--config", writeConfig(t, "profiles: {}\n")}, tt.args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			if got := app.ExitCode(cmd.Execute()); got != app.ExitInputError {
+				t.Errorf("exit code = %d, want %d", got, app.ExitInputError)
+			}
+		})
+	}
+}